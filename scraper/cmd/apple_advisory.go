@@ -33,6 +33,7 @@ type AppleAdvisory struct {
 type appleCVSSItem struct {
 	CVEID             string
 	CVSS3VectorString string
+	CVSS3BaseScore    float64
 }
 
 type AppleAdvisoryAffectedProductItem struct {
@@ -91,6 +92,7 @@ func queryApple(cveID string) (*appleCVSSItem, error) {
 	}
 	if score != nil {
 		item.CVSS3VectorString = score.VectorString()
+		item.CVSS3BaseScore = score.BaseScore()
 	}
 
 	return &item, nil
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"xorm.io/xorm"
+
+	"nanscraper/vulndb"
+)
+
+// appleadvCVSSCmd represents the Apple CVSS command.
+// It queries the Apple advisories for the CVSS3 vectors of CVEs and stores them as
+// vendor CVSS entries in the vulndb.
+var appleadvCVSSCmd = &cobra.Command{
+	Use:   "cvss <vulndb path> <CVE ID>...",
+	Short: "Store Apple CVSS3 scores of CVEs in the vulndb",
+	Long: `
+The appleadv cvss command queries the Apple advisories for the CVSS3 vectors
+of the specified CVEs and stores them in the vendor CVSS entries of the vulndb,
+updating the last crawled time of existing entries.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println("Need to specify vulndb path and at least one CVE ID")
+			os.Exit(1)
+		}
+
+		orm, err := xorm.NewEngine("sqlite3", args[0])
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		defer orm.Close()
+
+		sessionw := vulndb.NewSessionWrapper(orm)
+		crawledAt := time.Now().UTC().Unix()
+		for _, cveID := range args[1:] {
+			item, err := queryApple(cveID)
+			if err != nil {
+				fmt.Printf("ERROR: %s: %v\n", cveID, err)
+				continue
+			}
+			if len(item.CVSS3VectorString) == 0 {
+				fmt.Printf("%s: No CVSS3 vector - skipping\n", cveID)
+				continue
+			}
+			if len(item.CVEID) == 0 {
+				item.CVEID = cveID
+			}
+
+			score := item.CVSS3BaseScore
+			err = vulndb.UpsertVendorCVSSEntry(sessionw, item.CVEID, vulndb.SourceApple, item.CVSS3VectorString, &score, crawledAt)
+			if err != nil {
+				fmt.Printf("ERROR: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		err = sessionw.CommitAndClose()
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Complete")
+	},
+}
+
+func init() {
+	APPLEADVRootCMD.AddCommand(appleadvCVSSCmd)
+}
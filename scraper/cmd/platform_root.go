@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"xorm.io/xorm"
+
+	"nanscraper/vulndb"
+)
+
+// platformRootCmd represents the platform command.
+var platformRootCmd = &cobra.Command{
+	Use:   "platform",
+	Short: "Query the platform vulnerabilities of the vulndb",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// platformListCmd lists the supported platforms.
+var platformListCmd = &cobra.Command{
+	Use:   "list <vulndb path>",
+	Short: "List the supported platforms",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			fmt.Println("Need to specify vulndb path")
+			os.Exit(1)
+		}
+		sessionw := openVulnDB(args[0])
+		defer sessionw.Close()
+
+		platforms, err := vulndb.ListPlatforms(sessionw)
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		for _, p := range platforms {
+			fmt.Printf("%d\t%s\n", p.ID, p.DisplayName)
+		}
+	},
+}
+
+// openVulnDB opens the vulndb at `path`, exiting on failure.
+func openVulnDB(path string) *vulndb.VulnDBSession {
+	if _, err := os.Stat(path); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	orm, err := xorm.NewEngine("sqlite3", path)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	return vulndb.NewSessionWrapper(orm)
+}
+
+func init() {
+	rootCmd.AddCommand(platformRootCmd)
+	platformRootCmd.AddCommand(platformListCmd)
+}
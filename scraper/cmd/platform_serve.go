@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"nanscraper/vulndb"
+)
+
+var platformServeAddr string
+
+// platformServer serves the platform vulnerabilities of a vulndb as JSON:
+//
+//	GET /platforms
+//	GET /platforms/<platform id>/vulnerabilities?source=cpe&min_severity=high&sort=cvss3&limit=50&offset=0
+//	GET /cves/<CVE ID>/platforms
+type platformServer struct {
+	mu       sync.Mutex // The vulndb session is not safe for concurrent use.
+	sessionw *vulndb.VulnDBSession
+}
+
+func (s *platformServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "platforms":
+		platforms, err := vulndb.ListPlatforms(s.sessionw)
+		writeJSON(w, platforms, err)
+	case len(parts) == 3 && parts[0] == "platforms" && parts[2] == "vulnerabilities":
+		platformID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			http.Error(w, "invalid platform id", http.StatusBadRequest)
+			return
+		}
+		query := r.URL.Query()
+		var sources []string
+		for _, source := range query["source"] {
+			sources = append(sources, strings.Split(source, ",")...)
+		}
+		limit, offset := 0, 0
+		if v := query.Get("limit"); len(v) > 0 {
+			if limit, err = strconv.Atoi(v); err != nil {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+		if v := query.Get("offset"); len(v) > 0 {
+			if offset, err = strconv.Atoi(v); err != nil {
+				http.Error(w, "invalid offset", http.StatusBadRequest)
+				return
+			}
+		}
+		filters, err := platformFilters(sources, query.Get("min_severity"), query.Get("sort"), limit, offset)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		res, err := vulndb.ListPlatformVulnerabilities(s.sessionw, platformID, filters)
+		writeJSON(w, res, err)
+	case len(parts) == 3 && parts[0] == "cves" && parts[2] == "platforms":
+		platforms, err := vulndb.ListPlatformsForCVE(s.sessionw, parts[1])
+		writeJSON(w, platforms, err)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writeJSON writes `v` as JSON response, or an internal server error if `err` is set.
+func writeJSON(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// platformServeCmd serves the platform vulnerability queries over HTTP.
+var platformServeCmd = &cobra.Command{
+	Use:   "serve <vulndb path>",
+	Short: "Serve the platform vulnerability queries as JSON over HTTP",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			fmt.Println("Need to specify vulndb path")
+			os.Exit(1)
+		}
+		sessionw := openVulnDB(args[0])
+		defer sessionw.Close()
+
+		fmt.Printf("Serving platform vulnerabilities on %s\n", platformServeAddr)
+		err := http.ListenAndServe(platformServeAddr, &platformServer{sessionw: sessionw})
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	platformRootCmd.AddCommand(platformServeCmd)
+	platformServeCmd.Flags().StringVar(&platformServeAddr, "addr", "localhost:8080", "Listen address")
+}
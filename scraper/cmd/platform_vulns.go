@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"nanscraper/vulndb"
+)
+
+var (
+	platformSources     []string
+	platformMinSeverity string
+	platformSortBy      string
+	platformLimit       int
+	platformOffset      int
+	platformJSON        bool
+)
+
+// platformSortKeys maps the --sort values to the advisory orders.
+var platformSortKeys = map[string]vulndb.MatchSortKey{
+	"score":     vulndb.SortByBaseScore,
+	"cvss3":     vulndb.SortByBaseScore, // Before CVSS4, kept for compatibility.
+	"cvss2":     vulndb.SortByCVSS2,
+	"published": vulndb.SortByPublished,
+	"disclosed": vulndb.SortByDisclosed,
+	"cve":       vulndb.SortByCVEID,
+}
+
+// platformFilters returns the platform vulnerability filters of the sources, minimum severity name (e.g. high),
+// sort key (see platformSortKeys) and page.
+func platformFilters(sources []string, minSeverity, sortBy string, limit, offset int) (vulndb.PlatformVulnerabilityFilters, error) {
+	filters := vulndb.PlatformVulnerabilityFilters{Sources: sources, Limit: limit, Offset: offset}
+	if len(minSeverity) > 0 {
+		severity, ok := vulndb.ParseSeverity(minSeverity)
+		if !ok {
+			return filters, fmt.Errorf("unknown severity '%s'", minSeverity)
+		}
+		filters.MinSeverity = severity
+	}
+	if len(sortBy) > 0 {
+		sortKey, ok := platformSortKeys[strings.ToLower(sortBy)]
+		if !ok {
+			return filters, fmt.Errorf("unknown sort key '%s'", sortBy)
+		}
+		filters.SortBy = sortKey
+	}
+	if limit < 0 || offset < 0 {
+		return filters, fmt.Errorf("invalid page limit %d offset %d", limit, offset)
+	}
+	return filters, nil
+}
+
+// platformVulnsCmd lists the vulnerabilities of a platform.
+var platformVulnsCmd = &cobra.Command{
+	Use:   "vulns <vulndb path> <platform id>",
+	Short: "List the CVEs of a platform with the attributing sources",
+	Long: `
+The platform vulns command lists the CVEs of a platform (see platform list)
+from the platform vulnerabilities of the vulndb, with the sources (cpe,
+msrcAPI, redhat_oval, ...) attributing each CVE to the platform.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println("Need to specify vulndb path and platform id")
+			os.Exit(1)
+		}
+		platformID, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			fmt.Printf("ERROR: Invalid platform id '%s'\n", args[1])
+			os.Exit(1)
+		}
+		filters, err := platformFilters(platformSources, platformMinSeverity, platformSortBy, platformLimit, platformOffset)
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		sessionw := openVulnDB(args[0])
+		defer sessionw.Close()
+
+		res, err := vulndb.ListPlatformVulnerabilities(sessionw, platformID, filters)
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		if platformJSON {
+			printJSON(res)
+			return
+		}
+		for _, v := range res.Vulnerabilities {
+			fmt.Printf("%s\t%.1f\t%s\t%s\n", v.Advisory.CVEID, v.Advisory.BaseScore(),
+				time.Unix(v.Advisory.PublishedAt, 0).UTC().Format("2006-01-02"), strings.Join(v.Sources, ","))
+		}
+		fmt.Printf("%d of %d CVEs\n", len(res.Vulnerabilities), res.Total)
+	},
+}
+
+// platformCVECmd lists the platforms affected by CVEs.
+var platformCVECmd = &cobra.Command{
+	Use:   "cve <vulndb path> <CVE ID>...",
+	Short: "List the platforms affected by CVEs with the attributing sources",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println("Need to specify vulndb path and at least one CVE ID")
+			os.Exit(1)
+		}
+		sessionw := openVulnDB(args[0])
+		defer sessionw.Close()
+
+		results := map[string][]vulndb.CVEPlatform{}
+		for _, cveID := range args[1:] {
+			platforms, err := vulndb.ListPlatformsForCVE(sessionw, cveID)
+			if err != nil {
+				fmt.Printf("ERROR: %s: %v\n", cveID, err)
+				os.Exit(1)
+			}
+			results[cveID] = platforms
+			if platformJSON {
+				continue
+			}
+			for _, p := range platforms {
+				fmt.Printf("%s\t%d\t%s\t%s\n", cveID, p.PlatformID, p.DisplayName, strings.Join(p.Sources, ","))
+			}
+		}
+		if platformJSON {
+			printJSON(results)
+		}
+	},
+}
+
+// printJSON prints `v` as indented JSON, exiting on failure.
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+func init() {
+	platformRootCmd.AddCommand(platformVulnsCmd)
+	platformRootCmd.AddCommand(platformCVECmd)
+
+	platformVulnsCmd.Flags().StringSliceVar(&platformSources, "source", nil, "Only CVEs attributed by the sources (cpe, msrcAPI, redhat_oval, csaf, ...)")
+	platformVulnsCmd.Flags().StringVar(&platformMinSeverity, "min-severity", "", "Minimum CVSS3 severity (low, medium, high, critical)")
+	platformVulnsCmd.Flags().StringVar(&platformSortBy, "sort", "score", "Order of the CVEs (score, cvss2, published, disclosed, cve)")
+	platformVulnsCmd.Flags().IntVar(&platformLimit, "limit", 0, "Maximum number of CVEs, 0 for unlimited")
+	platformVulnsCmd.Flags().IntVar(&platformOffset, "offset", 0, "Number of CVEs to skip")
+	platformVulnsCmd.Flags().BoolVar(&platformJSON, "json", false, "Print as JSON")
+	platformCVECmd.Flags().BoolVar(&platformJSON, "json", false, "Print as JSON")
+}
@@ -0,0 +1,167 @@
+package cpe
+
+import (
+	"strings"
+)
+
+// fsPrefix is the prefix of CPE 2.3 formatted strings.
+const fsPrefix = "cpe:2.3:"
+
+// BindToFS binds the WFN to a CPE 2.3 formatted string, e.g.
+// cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*.
+func (w WFN) BindToFS() string {
+	var values []string
+	for _, value := range w.attributes() {
+		values = append(values, bindValueFS(*value))
+	}
+	return fsPrefix + strings.Join(values, ":")
+}
+
+// bindValueFS binds the WFN attribute value `value` for a formatted string. The quoted characters ".", "-" and
+// "_" are unquoted, other quoted characters remain quoted.
+func bindValueFS(value string) string {
+	switch value {
+	case Any, "":
+		return "*"
+	case NA:
+		return "-"
+	case `\-`:
+		// A lone unquoted hyphen would bind to NA.
+		return value
+	}
+
+	var sb strings.Builder
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) {
+			i++
+			switch runes[i] {
+			case '.', '-', '_':
+			default:
+				sb.WriteRune('\\')
+			}
+		}
+		sb.WriteRune(runes[i])
+	}
+	return sb.String()
+}
+
+// UnbindFS unbinds the CPE 2.3 formatted string `fs` to a WFN.
+func UnbindFS(fs string) (WFN, error) {
+	w := NewWFN()
+	if !strings.HasPrefix(fs, fsPrefix) {
+		return w, ErrInvalidPrefix
+	}
+
+	components, err := splitFS(fs[len(fsPrefix):])
+	if err != nil {
+		return w, err
+	}
+	attributes := w.attributes()
+	if len(components) != len(attributes) {
+		return w, ErrInvalidLength
+	}
+	for i, component := range components {
+		value, err := unbindValueFS(component)
+		if err != nil {
+			return w, err
+		}
+		*attributes[i] = value
+	}
+	if !validPart(w.Part) {
+		return w, ErrInvalidPart
+	}
+	return w, nil
+}
+
+// splitFS splits the formatted string components `s` at each unquoted colon, keeping the quoting.
+func splitFS(s string) ([]string, error) {
+	var components []string
+	var sb strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			components = append(components, sb.String())
+			sb.Reset()
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	if escaped {
+		return nil, ErrInvalidEscape
+	}
+	return append(components, sb.String()), nil
+}
+
+// unbindValueFS unbinds the formatted string component `s` to a WFN attribute value, quoting all characters other
+// than letters, digits, underscore and the wildcards.
+func unbindValueFS(s string) (string, error) {
+	switch s {
+	case "*", "":
+		return Any, nil
+	case "-":
+		return NA, nil
+	}
+
+	var sb strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case isWordChar(r), r == '*', r == '?':
+			sb.WriteRune(r)
+		case r == '\\':
+			sb.WriteRune(r)
+			i++
+			sb.WriteRune(runes[i])
+		default:
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		}
+	}
+	value := sb.String()
+	if err := checkWildcards(value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// checkWildcards returns an error if the WFN string value `value` has unquoted wildcards other than at the
+// beginning or end.
+func checkWildcards(value string) error {
+	// Unquoted characters of the value, quoted characters as 0.
+	var chars []rune
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			escaped = false
+			chars = append(chars, 0)
+		case r == '\\':
+			escaped = true
+		default:
+			chars = append(chars, r)
+		}
+	}
+
+	isWildcard := func(r rune) bool { return r == '*' || r == '?' }
+	start := 0
+	for start < len(chars) && isWildcard(chars[start]) {
+		start++
+	}
+	end := len(chars)
+	for end > start && isWildcard(chars[end-1]) {
+		end--
+	}
+	for _, r := range chars[start:end] {
+		if isWildcard(r) {
+			return ErrInvalidWildcard
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package cpe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// uriPrefix is the prefix of CPE 2.2 URIs.
+const uriPrefix = "cpe:/"
+
+// BindToURI binds the WFN to a CPE 2.2 URI, e.g. cpe:/a:microsoft:internet_explorer:8.0.6001:beta. The
+// sw_edition, target_sw, target_hw and other attributes are packed into the edition component if any of them
+// are not Any, e.g. cpe:/a:hp:openview_network_manager:7.51::~~~linux~~.
+func (w WFN) BindToURI() string {
+	edition := bindValueURI(w.Edition)
+	if w.SWEdition != Any || w.TargetSW != Any || w.TargetHW != Any || w.Other != Any {
+		edition = "~" + strings.Join([]string{edition, bindValueURI(w.SWEdition), bindValueURI(w.TargetSW),
+			bindValueURI(w.TargetHW), bindValueURI(w.Other)}, "~")
+	}
+
+	components := []string{bindValueURI(w.Part), bindValueURI(w.Vendor), bindValueURI(w.Product),
+		bindValueURI(w.Version), bindValueURI(w.Update), edition, bindValueURI(w.Language)}
+	return strings.TrimRight(uriPrefix+strings.Join(components, ":"), ":")
+}
+
+// bindValueURI binds the WFN attribute value `value` for a URI, percent-encoding the quoted characters and the
+// wildcards.
+func bindValueURI(value string) string {
+	switch value {
+	case Any, "":
+		return ""
+	case NA:
+		return "-"
+	}
+
+	var sb strings.Builder
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			sb.WriteString(pctEncode(runes[i]))
+		case r == '?':
+			sb.WriteString("%01")
+		case r == '*':
+			sb.WriteString("%02")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// pctEncode returns the percent-encoding of the quoted character `r`. Hyphen and period are not encoded.
+func pctEncode(r rune) string {
+	if r == '-' || r == '.' {
+		return string(r)
+	}
+	var sb strings.Builder
+	for _, b := range []byte(string(r)) {
+		fmt.Fprintf(&sb, "%%%02x", b)
+	}
+	return sb.String()
+}
+
+// UnbindURI unbinds the CPE 2.2 URI `uri` to a WFN. Missing components are Any.
+func UnbindURI(uri string) (WFN, error) {
+	w := NewWFN()
+	if !strings.HasPrefix(strings.ToLower(uri), uriPrefix) {
+		return w, ErrInvalidPrefix
+	}
+
+	components := strings.Split(uri[len(uriPrefix):], ":")
+	if len(components) > 7 {
+		return w, ErrInvalidLength
+	}
+	attributes := []*string{&w.Part, &w.Vendor, &w.Product, &w.Version, &w.Update, &w.Edition, &w.Language}
+	for i, component := range components {
+		if i == 5 && strings.HasPrefix(component, "~") {
+			// Packed edition: ~edition~sw_edition~target_sw~target_hw~other.
+			packed := strings.Split(component[1:], "~")
+			if len(packed) != 5 {
+				return w, ErrInvalidLength
+			}
+			for j, attribute := range []*string{&w.Edition, &w.SWEdition, &w.TargetSW, &w.TargetHW, &w.Other} {
+				value, err := decodeURI(packed[j])
+				if err != nil {
+					return w, err
+				}
+				*attribute = value
+			}
+			continue
+		}
+
+		value, err := decodeURI(component)
+		if err != nil {
+			return w, err
+		}
+		*attributes[i] = value
+	}
+	if !validPart(w.Part) {
+		return w, ErrInvalidPart
+	}
+	return w, nil
+}
+
+// decodeURI decodes the URI component `s` to a WFN attribute value: quoting the characters other than letters,
+// digits and underscore and decoding the percent-encoded characters (%01 and %02 are the wildcards ? and *).
+// Unlike NISTIR 7695, the case is kept as in the URI.
+func decodeURI(s string) (string, error) {
+	switch s {
+	case "":
+		return Any, nil
+	case "-":
+		return NA, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if !isWordChar(r) {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(r)
+			i += size - 1
+			continue
+		}
+
+		if i+2 >= len(s) {
+			return "", ErrInvalidEncoding
+		}
+		decoded, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", ErrInvalidEncoding
+		}
+		i += 2
+		switch {
+		case decoded == 0x01:
+			sb.WriteByte('?')
+		case decoded == 0x02:
+			sb.WriteByte('*')
+		case decoded < 0x20 || decoded > 0x7e:
+			return "", ErrInvalidCharacter
+		default:
+			if !isWordChar(rune(decoded)) {
+				sb.WriteByte('\\')
+			}
+			sb.WriteByte(byte(decoded))
+		}
+	}
+	value := sb.String()
+	if err := checkWildcards(value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
@@ -0,0 +1,117 @@
+// Package cpe implements the CPE 2.3 Well-Formed Name (WFN) model of the CPE Naming specification (NISTIR 7695):
+// binding WFNs to and unbinding them from CPE 2.2 URIs and CPE 2.3 formatted strings.
+package cpe
+
+import (
+	"errors"
+	"strings"
+)
+
+// Logical attribute values of WFNs.
+const (
+	Any = "*" // ANY: any value.
+	NA  = "-" // NA: not applicable, no legal value.
+)
+
+// Errors returned when unbinding CPE names.
+var (
+	ErrInvalidPrefix    = errors.New("invalid CPE prefix")
+	ErrInvalidLength    = errors.New("invalid number of CPE components")
+	ErrInvalidPart      = errors.New("invalid CPE part")
+	ErrInvalidEscape    = errors.New("invalid CPE escape")
+	ErrInvalidWildcard  = errors.New("embedded CPE wildcard")
+	ErrInvalidEncoding  = errors.New("invalid CPE percent encoding")
+	ErrInvalidCharacter = errors.New("invalid CPE character")
+)
+
+// WFN is a CPE Well-Formed Name. The attribute values are either the logical values Any and NA, or WFN strings
+// where all characters other than letters, digits and underscore are quoted with a backslash, e.g. `big\$money`,
+// except the wildcards "*" and "?" at the beginning and end of the value. Use Quote and Unquote to convert from
+// and to plain values.
+type WFN struct {
+	Part      string // a (application), o (operating system) or h (hardware), or Any.
+	Vendor    string
+	Product   string
+	Version   string
+	Update    string
+	Edition   string
+	Language  string
+	SWEdition string
+	TargetSW  string
+	TargetHW  string
+	Other     string
+}
+
+// NewWFN returns a WFN with all attributes Any.
+func NewWFN() WFN {
+	return WFN{
+		Part:      Any,
+		Vendor:    Any,
+		Product:   Any,
+		Version:   Any,
+		Update:    Any,
+		Edition:   Any,
+		Language:  Any,
+		SWEdition: Any,
+		TargetSW:  Any,
+		TargetHW:  Any,
+		Other:     Any,
+	}
+}
+
+// attributes returns pointers to the attributes of the WFN in the order of CPE 2.3 formatted strings.
+func (w *WFN) attributes() []*string {
+	return []*string{&w.Part, &w.Vendor, &w.Product, &w.Version, &w.Update, &w.Edition, &w.Language,
+		&w.SWEdition, &w.TargetSW, &w.TargetHW, &w.Other}
+}
+
+// Unbind unbinds the CPE 2.3 formatted string or CPE 2.2 URI `name` to a WFN.
+func Unbind(name string) (WFN, error) {
+	if strings.HasPrefix(name, "cpe:2.3:") {
+		return UnbindFS(name)
+	}
+	return UnbindURI(name)
+}
+
+// Quote returns the plain value `value` as a WFN string value, quoting all characters other than letters, digits
+// and underscore, e.g. "big$money" -> `big\$money`.
+func Quote(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		if !isWordChar(r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Unquote returns the plain value of the WFN string value `value`, removing the quoting, e.g. `big\$money` ->
+// "big$money". Unquoted wildcards are kept, thus the plain value is ambiguous for values with quoted wildcards.
+func Unquote(value string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range value {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// isWordChar returns true if `r` is a letter, digit or underscore, which are never quoted in WFNs.
+func isWordChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_'
+}
+
+// validPart returns true if `part` is a valid part attribute value.
+func validPart(part string) bool {
+	switch part {
+	case "a", "o", "h", Any:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package cpe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Examples of NISTIR 7695 section 6.
+func TestBind(t *testing.T) {
+	testcases := []struct {
+		WFN WFN
+		URI string
+		FS  string
+	}{
+		{
+			WFN{Part: "a", Vendor: "microsoft", Product: "internet_explorer", Version: `8\.0\.6001`, Update: "beta",
+				Edition: Any, Language: Any, SWEdition: Any, TargetSW: Any, TargetHW: Any, Other: Any},
+			"cpe:/a:microsoft:internet_explorer:8.0.6001:beta",
+			"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*",
+		},
+		{
+			WFN{Part: "a", Vendor: "microsoft", Product: "internet_explorer", Version: `8\.*`, Update: "sp?",
+				Edition: Any, Language: Any, SWEdition: Any, TargetSW: Any, TargetHW: Any, Other: Any},
+			"cpe:/a:microsoft:internet_explorer:8.%02:sp%01",
+			"cpe:2.3:a:microsoft:internet_explorer:8.*:sp?:*:*:*:*:*:*",
+		},
+		{
+			WFN{Part: "a", Vendor: "hp", Product: "insight_diagnostics", Version: `7\.4\.0\.1570`, Update: NA,
+				Edition: Any, Language: Any, SWEdition: "online", TargetSW: "win2003", TargetHW: "x64", Other: Any},
+			"cpe:/a:hp:insight_diagnostics:7.4.0.1570:-:~~online~win2003~x64~",
+			"cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*",
+		},
+		{
+			WFN{Part: "a", Vendor: "hp", Product: "openview_network_manager", Version: `7\.51`, Update: Any,
+				Edition: Any, Language: Any, SWEdition: Any, TargetSW: "linux", TargetHW: Any, Other: Any},
+			"cpe:/a:hp:openview_network_manager:7.51::~~~linux~~",
+			"cpe:2.3:a:hp:openview_network_manager:7.51:*:*:*:*:linux:*:*",
+		},
+		{
+			WFN{Part: "a", Vendor: `foo\\bar`, Product: `big\$money_manager_2010`, Version: Any, Update: Any,
+				Edition: Any, Language: Any, SWEdition: "special", TargetSW: "ipod_touch", TargetHW: "80gb", Other: Any},
+			"cpe:/a:foo%5cbar:big%24money_manager_2010:::~~special~ipod_touch~80gb~",
+			`cpe:2.3:a:foo\\bar:big\$money_manager_2010:*:*:*:*:special:ipod_touch:80gb:*`,
+		},
+		{
+			WFN{Part: "a", Vendor: `archive\:\:tar_project`, Product: `archive\:\:tar`, Version: Any, Update: Any,
+				Edition: Any, Language: Any, SWEdition: Any, TargetSW: "perl", TargetHW: Any, Other: Any},
+			"cpe:/a:archive%3a%3atar_project:archive%3a%3atar:::~~~perl~~",
+			`cpe:2.3:a:archive\:\:tar_project:archive\:\:tar:*:*:*:*:*:perl:*:*`,
+		},
+		{
+			WFN{Part: "o", Vendor: "acme", Product: "producto", Version: `1\.0`, Update: "update2", Edition: "pro",
+				Language: `en\-us`, SWEdition: Any, TargetSW: Any, TargetHW: Any, Other: Any},
+			"cpe:/o:acme:producto:1.0:update2:pro:en-us",
+			"cpe:2.3:o:acme:producto:1.0:update2:pro:en-us:*:*:*:*",
+		},
+	}
+
+	for _, tcase := range testcases {
+		require.Equal(t, tcase.URI, tcase.WFN.BindToURI())
+		require.Equal(t, tcase.FS, tcase.WFN.BindToFS())
+
+		// Round trip.
+		w, err := UnbindURI(tcase.URI)
+		require.NoError(t, err, tcase.URI)
+		require.Equal(t, tcase.WFN, w, tcase.URI)
+		w, err = UnbindFS(tcase.FS)
+		require.NoError(t, err, tcase.FS)
+		require.Equal(t, tcase.WFN, w, tcase.FS)
+		w, err = Unbind(tcase.FS)
+		require.NoError(t, err, tcase.FS)
+		require.Equal(t, tcase.WFN, w, tcase.FS)
+	}
+}
+
+func TestUnbind(t *testing.T) {
+	w, err := UnbindURI("cpe:/a:Microsoft:Internet_Explorer:8.%2a:sp%3f")
+	require.NoError(t, err)
+	require.Equal(t, "Microsoft", w.Vendor)
+	require.Equal(t, "Internet_Explorer", w.Product)
+	require.Equal(t, `8\.\*`, w.Version)
+	require.Equal(t, `sp\?`, w.Update)
+	require.Equal(t, Any, w.Edition)
+
+	w, err = UnbindFS(`cpe:2.3:a:foo:bar\*:1.0\?:*:*:*:*:*:*:*`)
+	require.NoError(t, err)
+	require.Equal(t, `bar\*`, w.Product)
+	require.Equal(t, `1\.0\?`, w.Version)
+
+	for _, invalid := range []string{
+		"cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*",
+		"cpe:2.3:a:foo:bar:12.*.1:*:*:*:*:*:*:*",
+		`cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*\`,
+		"cpe:2.3:x:foo:bar:1.0:*:*:*:*:*:*:*",
+		"cpe:/a:foo:bar:1.0:sp1:pro:en:extra",
+		"cpe:/a:foo:bar:1%02.0",
+		"cpe:/a:foo:bar:1.0%2",
+		"cpe:/a:foo:bar:1.0::~pro~",
+		"cpe:/x:foo:bar",
+		"cpe:foo:bar",
+	} {
+		_, err = Unbind(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	require.Equal(t, `big\$money_2010`, Quote("big$money_2010"))
+	require.Equal(t, `8\.0\.6001`, Quote("8.0.6001"))
+	require.Equal(t, "big$money_2010", Unquote(`big\$money_2010`))
+	require.Equal(t, `foo\bar`, Unquote(`foo\\bar`))
+	require.Equal(t, "8.*", Unquote(`8\.*`))
+}
@@ -0,0 +1,54 @@
+// Package cvss parses CVSS vector strings and calculates scores.
+package cvss
+
+import (
+	"errors"
+	"strings"
+)
+
+// Vector represents a parsed CVSS vector.
+type Vector interface {
+	// Version returns the CVSS version, e.g. 3.1.
+	Version() string
+	// VectorString returns the canonical vector string.
+	VectorString() string
+	// BaseScore returns the base score.
+	BaseScore() float64
+	// ToCVSS3 returns the vector as a CVSS3 vector.
+	ToCVSS3() CVSS3Vector
+}
+
+// Errors returned when parsing vector strings.
+var (
+	ErrUnsupportedVersion = errors.New("unsupported CVSS version")
+	ErrInvalidVector      = errors.New("invalid CVSS vector")
+)
+
+// Parse parses the CVSS `vector` string. Currently supports CVSS 3.0 and 3.1, CVSS 4.0 vectors are parsed by
+// ParseCVSS4 as they cannot be represented as CVSS3.
+func Parse(vector string) (Vector, error) {
+	vector = strings.TrimSpace(vector)
+	if strings.HasPrefix(vector, "CVSS:3.0/") || strings.HasPrefix(vector, "CVSS:3.1/") {
+		v, err := ParseCVSS3(vector)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return nil, ErrUnsupportedVersion
+}
+
+// Severity returns the qualitative severity rating of a CVSS3 `score`: NONE, LOW, MEDIUM, HIGH or CRITICAL.
+func Severity(score float64) string {
+	switch {
+	case score >= 9.0:
+		return "CRITICAL"
+	case score >= 7.0:
+		return "HIGH"
+	case score >= 4.0:
+		return "MEDIUM"
+	case score > 0:
+		return "LOW"
+	}
+	return "NONE"
+}
@@ -0,0 +1,302 @@
+package cvss
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CVSS3Vector represents a CVSS 3.0/3.1 vector. Metric values are the abbreviations used in vector strings,
+// e.g. "N" for AV:N. Temporal and environmental metrics are "X" (not defined) when not set.
+type CVSS3Vector struct {
+	Ver string // 3.0 or 3.1
+
+	// Base metrics.
+	AttackVector       string // N, A, L, P
+	AttackComplexity   string // L, H
+	PrivilegesRequired string // N, L, H
+	UserInteraction    string // N, R
+	Scope              string // U, C
+	Confidentiality    string // H, L, N
+	Integrity          string // H, L, N
+	Availability       string // H, L, N
+
+	// Temporal metrics.
+	ExploitCodeMaturity string // X, H, F, P, U
+	RemediationLevel    string // X, U, W, T, O
+	ReportConfidence    string // X, C, R, U
+
+	// Environmental metrics.
+	ConfidentialityRequirement string // X, H, M, L
+	IntegrityRequirement       string // X, H, M, L
+	AvailabilityRequirement    string // X, H, M, L
+	ModifiedAttackVector       string // X, N, A, L, P
+	ModifiedAttackComplexity   string // X, L, H
+	ModifiedPrivilegesRequired string // X, N, L, H
+	ModifiedUserInteraction    string // X, N, R
+	ModifiedScope              string // X, U, C
+	ModifiedConfidentiality    string // X, H, L, N
+	ModifiedIntegrity          string // X, H, L, N
+	ModifiedAvailability       string // X, H, L, N
+}
+
+// Requirements represents the security requirements of an asset: L, M, H or X (not defined).
+type Requirements struct {
+	Confidentiality string
+	Integrity       string
+	Availability    string
+}
+
+// cvss3Metric defines a metric in vector string order with its allowed values.
+type cvss3Metric struct {
+	Name   string
+	Values string // Allowed value abbreviations.
+	Base   bool   // Mandatory base metric.
+	Field  func(v *CVSS3Vector) *string
+}
+
+var cvss3Metrics = []cvss3Metric{
+	{"AV", "NALP", true, func(v *CVSS3Vector) *string { return &v.AttackVector }},
+	{"AC", "LH", true, func(v *CVSS3Vector) *string { return &v.AttackComplexity }},
+	{"PR", "NLH", true, func(v *CVSS3Vector) *string { return &v.PrivilegesRequired }},
+	{"UI", "NR", true, func(v *CVSS3Vector) *string { return &v.UserInteraction }},
+	{"S", "UC", true, func(v *CVSS3Vector) *string { return &v.Scope }},
+	{"C", "HLN", true, func(v *CVSS3Vector) *string { return &v.Confidentiality }},
+	{"I", "HLN", true, func(v *CVSS3Vector) *string { return &v.Integrity }},
+	{"A", "HLN", true, func(v *CVSS3Vector) *string { return &v.Availability }},
+	{"E", "XHFPU", false, func(v *CVSS3Vector) *string { return &v.ExploitCodeMaturity }},
+	{"RL", "XUWTO", false, func(v *CVSS3Vector) *string { return &v.RemediationLevel }},
+	{"RC", "XCRU", false, func(v *CVSS3Vector) *string { return &v.ReportConfidence }},
+	{"CR", "XHML", false, func(v *CVSS3Vector) *string { return &v.ConfidentialityRequirement }},
+	{"IR", "XHML", false, func(v *CVSS3Vector) *string { return &v.IntegrityRequirement }},
+	{"AR", "XHML", false, func(v *CVSS3Vector) *string { return &v.AvailabilityRequirement }},
+	{"MAV", "XNALP", false, func(v *CVSS3Vector) *string { return &v.ModifiedAttackVector }},
+	{"MAC", "XLH", false, func(v *CVSS3Vector) *string { return &v.ModifiedAttackComplexity }},
+	{"MPR", "XNLH", false, func(v *CVSS3Vector) *string { return &v.ModifiedPrivilegesRequired }},
+	{"MUI", "XNR", false, func(v *CVSS3Vector) *string { return &v.ModifiedUserInteraction }},
+	{"MS", "XUC", false, func(v *CVSS3Vector) *string { return &v.ModifiedScope }},
+	{"MC", "XHLN", false, func(v *CVSS3Vector) *string { return &v.ModifiedConfidentiality }},
+	{"MI", "XHLN", false, func(v *CVSS3Vector) *string { return &v.ModifiedIntegrity }},
+	{"MA", "XHLN", false, func(v *CVSS3Vector) *string { return &v.ModifiedAvailability }},
+}
+
+// ParseCVSS3 parses a CVSS 3.0/3.1 vector string, e.g. CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H.
+// All base metrics are required, metrics may only be specified once.
+func ParseCVSS3(vector string) (*CVSS3Vector, error) {
+	parts := strings.Split(strings.TrimSpace(vector), "/")
+	switch parts[0] {
+	case "CVSS:3.0", "CVSS:3.1":
+	default:
+		return nil, ErrUnsupportedVersion
+	}
+
+	v := &CVSS3Vector{Ver: strings.TrimPrefix(parts[0], "CVSS:")}
+	for _, m := range cvss3Metrics {
+		if !m.Base {
+			*m.Field(v) = "X"
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 || len(kv[1]) != 1 {
+			return nil, fmt.Errorf("%w: malformed metric '%s'", ErrInvalidVector, part)
+		}
+		if seen[kv[0]] {
+			return nil, fmt.Errorf("%w: duplicate metric '%s'", ErrInvalidVector, kv[0])
+		}
+		seen[kv[0]] = true
+
+		var metric *cvss3Metric
+		for i := range cvss3Metrics {
+			if cvss3Metrics[i].Name == kv[0] {
+				metric = &cvss3Metrics[i]
+				break
+			}
+		}
+		if metric == nil {
+			return nil, fmt.Errorf("%w: unknown metric '%s'", ErrInvalidVector, kv[0])
+		}
+		if !strings.Contains(metric.Values, kv[1]) {
+			return nil, fmt.Errorf("%w: invalid value '%s' for metric '%s'", ErrInvalidVector, kv[1], kv[0])
+		}
+		*metric.Field(v) = kv[1]
+	}
+
+	for _, m := range cvss3Metrics {
+		if m.Base && !seen[m.Name] {
+			return nil, fmt.Errorf("%w: missing base metric '%s'", ErrInvalidVector, m.Name)
+		}
+	}
+
+	return v, nil
+}
+
+// Version returns the CVSS version, 3.0 or 3.1.
+func (v CVSS3Vector) Version() string {
+	return v.Ver
+}
+
+// ToCVSS3 returns the vector itself.
+func (v CVSS3Vector) ToCVSS3() CVSS3Vector {
+	return v
+}
+
+// VectorString returns the canonical vector string, with the metrics in specification order and undefined
+// temporal and environmental metrics omitted.
+func (v CVSS3Vector) VectorString() string {
+	parts := []string{"CVSS:" + v.Ver}
+	for _, m := range cvss3Metrics {
+		value := *m.Field(&v)
+		if !m.Base && (value == "X" || value == "") {
+			continue
+		}
+		parts = append(parts, m.Name+":"+value)
+	}
+	return strings.Join(parts, "/")
+}
+
+// WithRequirements returns a copy of the vector with the security requirements of an asset `req` set.
+// Requirements that are empty are left as in the vector.
+func (v CVSS3Vector) WithRequirements(req Requirements) CVSS3Vector {
+	if len(req.Confidentiality) > 0 {
+		v.ConfidentialityRequirement = req.Confidentiality
+	}
+	if len(req.Integrity) > 0 {
+		v.IntegrityRequirement = req.Integrity
+	}
+	if len(req.Availability) > 0 {
+		v.AvailabilityRequirement = req.Availability
+	}
+	return v
+}
+
+// Metric weights as defined by the specification.
+var (
+	cvss3WeightAV  = map[string]float64{"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2}
+	cvss3WeightAC  = map[string]float64{"L": 0.77, "H": 0.44}
+	cvss3WeightUI  = map[string]float64{"N": 0.85, "R": 0.62}
+	cvss3WeightCIA = map[string]float64{"H": 0.56, "L": 0.22, "N": 0}
+	cvss3WeightE   = map[string]float64{"X": 1, "H": 1, "F": 0.97, "P": 0.94, "U": 0.91}
+	cvss3WeightRL  = map[string]float64{"X": 1, "U": 1, "W": 0.97, "T": 0.96, "O": 0.95}
+	cvss3WeightRC  = map[string]float64{"X": 1, "C": 1, "R": 0.96, "U": 0.92}
+	cvss3WeightReq = map[string]float64{"X": 1, "H": 1.5, "M": 1, "L": 0.5}
+)
+
+// cvss3WeightPR returns the weight of privileges required `pr`, which depends on whether the scope is changed.
+func cvss3WeightPR(pr string, scopeChanged bool) float64 {
+	switch pr {
+	case "N":
+		return 0.85
+	case "L":
+		if scopeChanged {
+			return 0.68
+		}
+		return 0.62
+	case "H":
+		if scopeChanged {
+			return 0.5
+		}
+		return 0.27
+	}
+	return 0
+}
+
+// weight returns the weight of `value` in `weights`, where an empty value means not defined.
+func weight(weights map[string]float64, value string) float64 {
+	if len(value) == 0 {
+		value = "X"
+	}
+	return weights[value]
+}
+
+// roundup rounds up to one decimal as defined by the specification version. CVSS 3.1 avoids floating point
+// artifacts by rounding to an integer first.
+func (v CVSS3Vector) roundup(x float64) float64 {
+	if v.Ver == "3.0" {
+		return math.Ceil(x*10) / 10
+	}
+	i := int64(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
+
+// BaseScore returns the base score.
+func (v CVSS3Vector) BaseScore() float64 {
+	scopeChanged := v.Scope == "C"
+	iss := 1 - (1-cvss3WeightCIA[v.Confidentiality])*(1-cvss3WeightCIA[v.Integrity])*(1-cvss3WeightCIA[v.Availability])
+
+	var impact float64
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	exploitability := 8.22 * cvss3WeightAV[v.AttackVector] * cvss3WeightAC[v.AttackComplexity] *
+		cvss3WeightPR(v.PrivilegesRequired, scopeChanged) * cvss3WeightUI[v.UserInteraction]
+
+	if impact <= 0 {
+		return 0
+	}
+	if scopeChanged {
+		return v.roundup(math.Min(1.08*(impact+exploitability), 10))
+	}
+	return v.roundup(math.Min(impact+exploitability, 10))
+}
+
+// temporalMultiplier returns the product of the temporal metric weights.
+func (v CVSS3Vector) temporalMultiplier() float64 {
+	return weight(cvss3WeightE, v.ExploitCodeMaturity) * weight(cvss3WeightRL, v.RemediationLevel) *
+		weight(cvss3WeightRC, v.ReportConfidence)
+}
+
+// TemporalScore returns the temporal score, which equals the base score if no temporal metrics are defined.
+func (v CVSS3Vector) TemporalScore() float64 {
+	return v.roundup(v.BaseScore() * v.temporalMultiplier())
+}
+
+// modified returns the modified metric value `modified`, falling back to the base metric value `base` if
+// not defined.
+func modified(modified, base string) string {
+	if len(modified) == 0 || modified == "X" {
+		return base
+	}
+	return modified
+}
+
+// EnvironmentalScore returns the environmental score, which equals the temporal score if no environmental
+// metrics are defined.
+func (v CVSS3Vector) EnvironmentalScore() float64 {
+	scopeChanged := modified(v.ModifiedScope, v.Scope) == "C"
+
+	miss := math.Min(1-
+		(1-weight(cvss3WeightReq, v.ConfidentialityRequirement)*cvss3WeightCIA[modified(v.ModifiedConfidentiality, v.Confidentiality)])*
+			(1-weight(cvss3WeightReq, v.IntegrityRequirement)*cvss3WeightCIA[modified(v.ModifiedIntegrity, v.Integrity)])*
+			(1-weight(cvss3WeightReq, v.AvailabilityRequirement)*cvss3WeightCIA[modified(v.ModifiedAvailability, v.Availability)]),
+		0.915)
+
+	var impact float64
+	switch {
+	case !scopeChanged:
+		impact = 6.42 * miss
+	case v.Ver == "3.0":
+		impact = 7.52*(miss-0.029) - 3.25*math.Pow(miss-0.02, 15)
+	default:
+		impact = 7.52*(miss-0.029) - 3.25*math.Pow(miss*0.9731-0.02, 13)
+	}
+	exploitability := 8.22 * cvss3WeightAV[modified(v.ModifiedAttackVector, v.AttackVector)] *
+		cvss3WeightAC[modified(v.ModifiedAttackComplexity, v.AttackComplexity)] *
+		cvss3WeightPR(modified(v.ModifiedPrivilegesRequired, v.PrivilegesRequired), scopeChanged) *
+		cvss3WeightUI[modified(v.ModifiedUserInteraction, v.UserInteraction)]
+
+	if impact <= 0 {
+		return 0
+	}
+	if scopeChanged {
+		return v.roundup(v.roundup(math.Min(1.08*(impact+exploitability), 10)) * v.temporalMultiplier())
+	}
+	return v.roundup(v.roundup(math.Min(impact+exploitability, 10)) * v.temporalMultiplier())
+}
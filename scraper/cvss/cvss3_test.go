@@ -0,0 +1,82 @@
+package cvss
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCVSS3Scores(t *testing.T) {
+	testcases := []struct {
+		Vector        string
+		Base          float64
+		Temporal      float64
+		Environmental float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, 9.8, 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0, 10.0, 10.0},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, 6.1, 6.1},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:C/C:L/I:L/A:N", 7.2, 7.2, 7.2},
+		{"CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:U/C:L/I:L/A:L", 6.3, 6.3, 6.3},
+		{"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", 7.8, 7.8, 7.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, 0, 0},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:P/RL:O/RC:C", 9.8, 8.8, 8.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/CR:L/IR:L/AR:L", 9.8, 9.8, 8.0},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/MAV:L/MPR:H", 9.8, 9.8, 6.7},
+	}
+
+	for _, tcase := range testcases {
+		v, err := ParseCVSS3(tcase.Vector)
+		require.NoError(t, err, "Vector: %s", tcase.Vector)
+		require.Equal(t, tcase.Base, v.BaseScore(), "Vector: %s", tcase.Vector)
+		require.Equal(t, tcase.Temporal, v.TemporalScore(), "Vector: %s", tcase.Vector)
+		require.Equal(t, tcase.Environmental, v.EnvironmentalScore(), "Vector: %s", tcase.Vector)
+	}
+}
+
+func TestCVSS3VectorString(t *testing.T) {
+	testcases := []struct {
+		Vector    string
+		Canonical string
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		{"CVSS:3.0/S:U/AV:N/AC:L/PR:N/UI:N/C:H/I:H/A:H/E:X", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/CR:H/RL:O", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/RL:O/CR:H"},
+	}
+
+	for _, tcase := range testcases {
+		v, err := Parse(tcase.Vector)
+		require.NoError(t, err)
+		require.Equal(t, tcase.Canonical, v.VectorString())
+
+		// Round trip.
+		v2, err := Parse(v.VectorString())
+		require.NoError(t, err)
+		require.Equal(t, v.ToCVSS3(), v2.ToCVSS3())
+	}
+
+	for _, invalid := range []string{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H",          // Missing A.
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/A:L",  // Duplicate.
+		"CVSS:3.1/AV:Z/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",      // Invalid value.
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/XX:H", // Unknown metric.
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P",                        // CVSS2.
+	} {
+		_, err := Parse(invalid)
+		require.Error(t, err, "Vector: %s", invalid)
+	}
+}
+
+func TestCVSS3Requirements(t *testing.T) {
+	v, err := ParseCVSS3("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N")
+	require.NoError(t, err)
+	require.Equal(t, 7.5, v.BaseScore())
+
+	high := v.WithRequirements(Requirements{Confidentiality: "H"})
+	require.Equal(t, 9.3, high.EnvironmentalScore())
+	low := v.WithRequirements(Requirements{Confidentiality: "L"})
+	require.Equal(t, 5.7, low.EnvironmentalScore())
+	require.Equal(t, "X", v.ConfidentialityRequirement)
+	require.Equal(t, "CRITICAL", Severity(high.EnvironmentalScore()))
+	require.Equal(t, "MEDIUM", Severity(low.EnvironmentalScore()))
+}
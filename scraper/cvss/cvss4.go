@@ -0,0 +1,414 @@
+package cvss
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CVSS4Vector represents a CVSS 4.0 vector. Metric values are the abbreviations used in vector strings, e.g.
+// "N" for AV:N. Threat, environmental and supplemental metrics are "X" (not defined) when not set.
+type CVSS4Vector struct {
+	metrics map[string]string
+}
+
+// cvss4Metric defines a metric in vector string order with its allowed values.
+type cvss4Metric struct {
+	Name   string
+	Values []string // Allowed value abbreviations.
+	Base   bool     // Mandatory base metric.
+}
+
+var cvss4Metrics = []cvss4Metric{
+	// Base metrics.
+	{"AV", []string{"N", "A", "L", "P"}, true},
+	{"AC", []string{"L", "H"}, true},
+	{"AT", []string{"N", "P"}, true},
+	{"PR", []string{"N", "L", "H"}, true},
+	{"UI", []string{"N", "P", "A"}, true},
+	{"VC", []string{"H", "L", "N"}, true},
+	{"VI", []string{"H", "L", "N"}, true},
+	{"VA", []string{"H", "L", "N"}, true},
+	{"SC", []string{"H", "L", "N"}, true},
+	{"SI", []string{"H", "L", "N"}, true},
+	{"SA", []string{"H", "L", "N"}, true},
+	// Threat metrics.
+	{"E", []string{"X", "A", "P", "U"}, false},
+	// Environmental metrics.
+	{"CR", []string{"X", "H", "M", "L"}, false},
+	{"IR", []string{"X", "H", "M", "L"}, false},
+	{"AR", []string{"X", "H", "M", "L"}, false},
+	{"MAV", []string{"X", "N", "A", "L", "P"}, false},
+	{"MAC", []string{"X", "L", "H"}, false},
+	{"MAT", []string{"X", "N", "P"}, false},
+	{"MPR", []string{"X", "N", "L", "H"}, false},
+	{"MUI", []string{"X", "N", "P", "A"}, false},
+	{"MVC", []string{"X", "H", "L", "N"}, false},
+	{"MVI", []string{"X", "H", "L", "N"}, false},
+	{"MVA", []string{"X", "H", "L", "N"}, false},
+	{"MSC", []string{"X", "H", "L", "N"}, false},
+	{"MSI", []string{"X", "S", "H", "L", "N"}, false},
+	{"MSA", []string{"X", "S", "H", "L", "N"}, false},
+	// Supplemental metrics, these do not affect the score.
+	{"S", []string{"X", "N", "P"}, false},
+	{"AU", []string{"X", "N", "Y"}, false},
+	{"R", []string{"X", "A", "U", "I"}, false},
+	{"V", []string{"X", "D", "C"}, false},
+	{"RE", []string{"X", "L", "M", "H"}, false},
+	{"U", []string{"X", "Clear", "Green", "Amber", "Red"}, false},
+}
+
+// ParseCVSS4 parses a CVSS 4.0 vector string, e.g. CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N.
+// All base metrics are required, metrics may only be specified once.
+func ParseCVSS4(vector string) (*CVSS4Vector, error) {
+	parts := strings.Split(strings.TrimSpace(vector), "/")
+	if parts[0] != "CVSS:4.0" {
+		return nil, ErrUnsupportedVersion
+	}
+
+	v := &CVSS4Vector{metrics: map[string]string{}}
+	for _, m := range cvss4Metrics {
+		if !m.Base {
+			v.metrics[m.Name] = "X"
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 || len(kv[1]) == 0 {
+			return nil, fmt.Errorf("%w: malformed metric '%s'", ErrInvalidVector, part)
+		}
+		if seen[kv[0]] {
+			return nil, fmt.Errorf("%w: duplicate metric '%s'", ErrInvalidVector, kv[0])
+		}
+		seen[kv[0]] = true
+
+		var metric *cvss4Metric
+		for i := range cvss4Metrics {
+			if cvss4Metrics[i].Name == kv[0] {
+				metric = &cvss4Metrics[i]
+				break
+			}
+		}
+		if metric == nil {
+			return nil, fmt.Errorf("%w: unknown metric '%s'", ErrInvalidVector, kv[0])
+		}
+		valid := false
+		for _, value := range metric.Values {
+			if value == kv[1] {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("%w: invalid value '%s' for metric '%s'", ErrInvalidVector, kv[1], kv[0])
+		}
+		v.metrics[kv[0]] = kv[1]
+	}
+
+	for _, m := range cvss4Metrics {
+		if m.Base && !seen[m.Name] {
+			return nil, fmt.Errorf("%w: missing base metric '%s'", ErrInvalidVector, m.Name)
+		}
+	}
+
+	return v, nil
+}
+
+// Version returns the CVSS version, 4.0.
+func (v CVSS4Vector) Version() string {
+	return "4.0"
+}
+
+// Metric returns the value of metric `name` as specified in the vector, e.g. "N" for AV, or "X" if not defined.
+func (v CVSS4Vector) Metric(name string) string {
+	return v.metrics[name]
+}
+
+// VectorString returns the canonical vector string, with the metrics in specification order and undefined
+// threat, environmental and supplemental metrics omitted.
+func (v CVSS4Vector) VectorString() string {
+	parts := []string{"CVSS:4.0"}
+	for _, m := range cvss4Metrics {
+		value := v.metrics[m.Name]
+		if !m.Base && (value == "X" || value == "") {
+			continue
+		}
+		parts = append(parts, m.Name+":"+value)
+	}
+	return strings.Join(parts, "/")
+}
+
+// WithRequirements returns a copy of the vector with the security requirements of an asset `req` set.
+// Requirements that are empty are left as in the vector.
+func (v CVSS4Vector) WithRequirements(req Requirements) CVSS4Vector {
+	metrics := make(map[string]string, len(v.metrics))
+	for name, value := range v.metrics {
+		metrics[name] = value
+	}
+	if len(req.Confidentiality) > 0 {
+		metrics["CR"] = req.Confidentiality
+	}
+	if len(req.Integrity) > 0 {
+		metrics["IR"] = req.Integrity
+	}
+	if len(req.Availability) > 0 {
+		metrics["AR"] = req.Availability
+	}
+	return CVSS4Vector{metrics: metrics}
+}
+
+// effective returns the value of `metric` used for scoring: the modified metric if defined, otherwise the
+// base metric, with the worst case defaults for undefined exploit maturity and security requirements.
+func (v CVSS4Vector) effective(metric string) string {
+	value := v.metrics[metric]
+	switch metric {
+	case "E":
+		if value == "X" {
+			return "A"
+		}
+		return value
+	case "CR", "IR", "AR":
+		if value == "X" {
+			return "H"
+		}
+		return value
+	}
+	if modified, has := v.metrics["M"+metric]; has && modified != "X" {
+		return modified
+	}
+	return value
+}
+
+// macroVector returns the equivalence classes EQ1 to EQ6 of the vector.
+func (v CVSS4Vector) macroVector() [6]int {
+	m := v.effective
+	var eq [6]int
+
+	switch {
+	case m("AV") == "N" && m("PR") == "N" && m("UI") == "N":
+		eq[0] = 0
+	case (m("AV") == "N" || m("PR") == "N" || m("UI") == "N") && m("AV") != "P":
+		eq[0] = 1
+	default:
+		eq[0] = 2
+	}
+
+	if m("AC") != "L" || m("AT") != "N" {
+		eq[1] = 1
+	}
+
+	switch {
+	case m("VC") == "H" && m("VI") == "H":
+		eq[2] = 0
+	case m("VC") == "H" || m("VI") == "H" || m("VA") == "H":
+		eq[2] = 1
+	default:
+		eq[2] = 2
+	}
+
+	switch {
+	case m("MSI") == "S" || m("MSA") == "S":
+		eq[3] = 0
+	case m("SC") == "H" || m("SI") == "H" || m("SA") == "H":
+		eq[3] = 1
+	default:
+		eq[3] = 2
+	}
+
+	switch m("E") {
+	case "P":
+		eq[4] = 1
+	case "U":
+		eq[4] = 2
+	}
+
+	if !(m("CR") == "H" && m("VC") == "H") && !(m("IR") == "H" && m("VI") == "H") && !(m("AR") == "H" && m("VA") == "H") {
+		eq[5] = 1
+	}
+
+	return eq
+}
+
+// cvss4MacroVectorKey returns the cvss4MacroVectorScores key of the equivalence classes `eq`.
+func cvss4MacroVectorKey(eq [6]int) string {
+	var key strings.Builder
+	for _, class := range eq {
+		key.WriteString(strconv.Itoa(class))
+	}
+	return key.String()
+}
+
+// Severity levels of the metric values used to compute the distance to the highest severity vectors of a
+// MacroVector. Lower is more severe.
+var cvss4Levels = map[string]map[string]float64{
+	"AV": {"N": 0.0, "A": 0.1, "L": 0.2, "P": 0.3},
+	"PR": {"N": 0.0, "L": 0.1, "H": 0.2},
+	"UI": {"N": 0.0, "P": 0.1, "A": 0.2},
+	"AC": {"L": 0.0, "H": 0.1},
+	"AT": {"N": 0.0, "P": 0.1},
+	"VC": {"H": 0.0, "L": 0.1, "N": 0.2},
+	"VI": {"H": 0.0, "L": 0.1, "N": 0.2},
+	"VA": {"H": 0.0, "L": 0.1, "N": 0.2},
+	"SC": {"H": 0.1, "L": 0.2, "N": 0.3},
+	"SI": {"S": 0.0, "H": 0.1, "L": 0.2, "N": 0.3},
+	"SA": {"S": 0.0, "H": 0.1, "L": 0.2, "N": 0.3},
+	"CR": {"H": 0.0, "M": 0.1, "L": 0.2},
+	"IR": {"H": 0.0, "M": 0.1, "L": 0.2},
+	"AR": {"H": 0.0, "M": 0.1, "L": 0.2},
+}
+
+// Highest severity vectors of each equivalence class. EQ3 and EQ6 are combined, indexed by EQ3 then EQ6.
+var (
+	cvss4MaxEQ1 = [][]string{
+		{"AV:N/PR:N/UI:N"},
+		{"AV:A/PR:N/UI:N", "AV:N/PR:L/UI:N", "AV:N/PR:N/UI:P"},
+		{"AV:P/PR:N/UI:N", "AV:A/PR:L/UI:P"},
+	}
+	cvss4MaxEQ2 = [][]string{
+		{"AC:L/AT:N"},
+		{"AC:H/AT:N", "AC:L/AT:P"},
+	}
+	cvss4MaxEQ3EQ6 = [][][]string{
+		{
+			{"VC:H/VI:H/VA:H/CR:H/IR:H/AR:H"},
+			{"VC:H/VI:H/VA:L/CR:M/IR:M/AR:H", "VC:H/VI:H/VA:H/CR:M/IR:M/AR:M"},
+		},
+		{
+			{"VC:L/VI:H/VA:H/CR:H/IR:H/AR:H", "VC:H/VI:L/VA:H/CR:H/IR:H/AR:H"},
+			{"VC:L/VI:H/VA:L/CR:H/IR:M/AR:H", "VC:L/VI:H/VA:H/CR:H/IR:M/AR:M", "VC:H/VI:L/VA:H/CR:M/IR:H/AR:M", "VC:H/VI:L/VA:L/CR:M/IR:H/AR:H", "VC:L/VI:L/VA:H/CR:H/IR:H/AR:M"},
+		},
+		{
+			nil,
+			{"VC:L/VI:L/VA:L/CR:H/IR:H/AR:H"},
+		},
+	}
+	cvss4MaxEQ4 = [][]string{
+		{"SC:H/SI:S/SA:S"},
+		{"SC:H/SI:H/SA:H"},
+		{"SC:L/SI:L/SA:L"},
+	}
+)
+
+// Depth of each equivalence class, i.e. the maximal severity distance within it in steps of 0.1.
+var (
+	cvss4DepthEQ1    = []float64{1, 4, 5}
+	cvss4DepthEQ2    = []float64{1, 2}
+	cvss4DepthEQ3EQ6 = [][]float64{{7, 6}, {8, 8}, {0, 10}}
+	cvss4DepthEQ4    = []float64{6, 5, 4}
+)
+
+// BaseScore returns the score of the vector. CVSS 4.0 has a single score which takes the threat and
+// environmental metrics into account when defined (CVSS-BT, CVSS-BE or CVSS-BTE).
+func (v CVSS4Vector) BaseScore() float64 {
+	m := v.effective
+	none := true
+	for _, metric := range []string{"VC", "VI", "VA", "SC", "SI", "SA"} {
+		if m(metric) != "N" {
+			none = false
+			break
+		}
+	}
+	if none {
+		return 0
+	}
+
+	eq := v.macroVector()
+	value, has := cvss4MacroVectorScores[cvss4MacroVectorKey(eq)]
+	if !has {
+		return 0
+	}
+
+	// Score of the next lower MacroVector for each equivalence class, if any.
+	lower := func(deltas [6]int) (float64, bool) {
+		next := eq
+		for i, delta := range deltas {
+			next[i] += delta
+		}
+		score, has := cvss4MacroVectorScores[cvss4MacroVectorKey(next)]
+		return score, has
+	}
+	var lowerEQ3EQ6 float64
+	var hasEQ3EQ6 bool
+	if eq[2] == 0 && eq[5] == 0 {
+		// Both 01 and 10 are lower, take the highest one.
+		left, hasLeft := lower([6]int{0, 0, 0, 0, 0, 1})
+		right, hasRight := lower([6]int{0, 0, 1, 0, 0, 0})
+		lowerEQ3EQ6, hasEQ3EQ6 = left, hasLeft
+		if hasRight && (!hasLeft || right > left) {
+			lowerEQ3EQ6, hasEQ3EQ6 = right, true
+		}
+	} else if eq[2] == 1 && eq[5] == 0 {
+		lowerEQ3EQ6, hasEQ3EQ6 = lower([6]int{0, 0, 0, 0, 0, 1})
+	} else if eq[2] == 2 {
+		lowerEQ3EQ6, hasEQ3EQ6 = lower([6]int{0, 0, 1, 0, 0, 1})
+	} else {
+		lowerEQ3EQ6, hasEQ3EQ6 = lower([6]int{0, 0, 1, 0, 0, 0})
+	}
+
+	// Severity distance of the vector from the first highest severity vector of the MacroVector it does not
+	// exceed.
+	distance := map[string]float64{}
+search:
+	for _, max1 := range cvss4MaxEQ1[eq[0]] {
+		for _, max2 := range cvss4MaxEQ2[eq[1]] {
+			for _, max36 := range cvss4MaxEQ3EQ6[eq[2]][eq[5]] {
+				for _, max4 := range cvss4MaxEQ4[eq[3]] {
+					maxVector := strings.Join([]string{max1, max2, max36, max4}, "/")
+					distance = map[string]float64{}
+					exceeds := false
+					for _, part := range strings.Split(maxVector, "/") {
+						kv := strings.SplitN(part, ":", 2)
+						levels := cvss4Levels[kv[0]]
+						distance[kv[0]] = levels[m(kv[0])] - levels[kv[1]]
+						if distance[kv[0]] < -1e-9 {
+							exceeds = true
+						}
+					}
+					if !exceeds {
+						break search
+					}
+				}
+			}
+		}
+	}
+
+	const step = 0.1
+	classes := []struct {
+		Distance float64
+		Depth    float64
+		Lower    float64
+		HasLower bool
+	}{
+		{distance["AV"] + distance["PR"] + distance["UI"], cvss4DepthEQ1[eq[0]], 0, false},
+		{distance["AC"] + distance["AT"], cvss4DepthEQ2[eq[1]], 0, false},
+		{distance["VC"] + distance["VI"] + distance["VA"] + distance["CR"] + distance["IR"] + distance["AR"],
+			cvss4DepthEQ3EQ6[eq[2]][eq[5]], lowerEQ3EQ6, hasEQ3EQ6},
+		{distance["SC"] + distance["SI"] + distance["SA"], cvss4DepthEQ4[eq[3]], 0, false},
+		{0, 1, 0, false}, // EQ5 has no severity distance.
+	}
+	classes[0].Lower, classes[0].HasLower = lower([6]int{1, 0, 0, 0, 0, 0})
+	classes[1].Lower, classes[1].HasLower = lower([6]int{0, 1, 0, 0, 0, 0})
+	classes[3].Lower, classes[3].HasLower = lower([6]int{0, 0, 0, 1, 0, 0})
+	classes[4].Lower, classes[4].HasLower = lower([6]int{0, 0, 0, 0, 1, 0})
+
+	// The score is the MacroVector score minus the mean of the proportional distances to the next lower
+	// MacroVectors.
+	existing := 0
+	total := 0.0
+	for _, class := range classes {
+		if !class.HasLower {
+			continue
+		}
+		existing++
+		total += (value - class.Lower) * class.Distance / (class.Depth * step)
+	}
+	if existing > 0 {
+		value -= total / float64(existing)
+	}
+
+	value = math.Max(0, math.Min(10, value))
+	return math.Round(value*10) / 10
+}
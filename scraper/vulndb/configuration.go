@@ -0,0 +1,331 @@
+package vulndb
+
+import (
+	"sort"
+
+	"nanscraper/common"
+	"nanscraper/vulndb/nvdjson"
+)
+
+// insertCVEConfigurations inserts the NVD configuration `nodes` of advisory `advisoryID` into vulndb as children
+// of `parentID` (nil for the root nodes).
+func insertCVEConfigurations(sessionw *VulnDBSession, advisoryID int64, parentID *int64, nodes []nvdjson.ConfigurationNode) error {
+	for _, n := range nodes {
+		node := cveConfigurationNode{
+			AdvisoryID: advisoryID,
+			ParentID:   parentID,
+			Operator:   n.Operator,
+		}
+		if n.Negate {
+			node.Negate = 1
+		}
+		err := sessionw.Insert(&node)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range n.CPEMatches {
+			cpeParts, err := ParseCPE(m.CPE23)
+			if err != nil {
+				log.Debugf("Invalid configuration CPE '%s' - skipping", m.CPE23)
+				continue
+			}
+			match := cveConfigurationMatch{
+				NodeID:                node.ID,
+				AdvisoryID:            advisoryID,
+				CPE23:                 m.CPE23,
+				Systype:               cpeParts.Systype,
+				Vendor:                cpeParts.Vendor,
+				Product:               cpeParts.Product,
+				VersionStartExcluding: m.VersionStartExcluding,
+				VersionStartIncluding: m.VersionStartIncluding,
+				VersionEndExcluding:   m.VersionEndExcluding,
+				VersionEndIncluding:   m.VersionEndIncluding,
+			}
+			if m.Vulnerable {
+				match.Vulnerable = 1
+			}
+			err = sessionw.Insert(&match)
+			if err != nil {
+				return err
+			}
+		}
+
+		nodeID := node.ID
+		err = insertCVEConfigurations(sessionw, advisoryID, &nodeID, n.Children)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HostContext describes a host for configuration-aware matching by CPE 2.3 names, e.g.
+// cpe:2.3:o:microsoft:windows_10:1909:*:*:*:*:*:*:*. Unknown attributes of the host CPEs can be left as *.
+type HostContext struct {
+	OS           string   // Operating system CPE.
+	Hardware     string   // Hardware CPE.
+	Applications []string // Installed application CPEs.
+}
+
+// cpes returns the parsed CPEs of the host, invalid CPEs are logged and skipped.
+func (h HostContext) cpes() []CPEParts {
+	var cpes []CPEParts
+	for _, cpe := range append([]string{h.OS, h.Hardware}, h.Applications...) {
+		if len(cpe) == 0 {
+			continue
+		}
+		cpeParts, err := ParseCPE(cpe)
+		if err != nil {
+			log.Debugf("Invalid host CPE '%s' - skipping", cpe)
+			continue
+		}
+		cpes = append(cpes, cpeParts)
+	}
+	return cpes
+}
+
+// cpeAttributeMatches returns true if the configuration CPE attribute `pattern` matches the host attribute
+// `value`, i.e. they are not disjoint (see CompareCPEAttribute). An unknown (*) host attribute matches any pattern.
+func cpeAttributeMatches(pattern, value string) bool {
+	relation := CompareCPEAttribute(pattern, value)
+	return relation != CPEDisjoint && relation != CPEUndefined
+}
+
+// matchesHostCPE returns true if the configuration match `m` applies to the host CPE `host`.
+func (m cveConfigurationMatch) matchesHostCPE(host CPEParts) bool {
+	if m.Systype != host.Systype || m.Vendor != host.Vendor || m.Product != host.Product {
+		return false
+	}
+	pattern, err := ParseCPE(m.CPE23)
+	if err != nil {
+		return false
+	}
+	if !cpeAttributeMatches(pattern.Patch, host.Patch) ||
+		!cpeAttributeMatches(pattern.Edition, host.Edition) ||
+		!cpeAttributeMatches(pattern.Language, host.Language) ||
+		!cpeAttributeMatches(pattern.SWEdition, host.SWEdition) ||
+		!cpeAttributeMatches(pattern.TargetSW, host.TargetSW) ||
+		!cpeAttributeMatches(pattern.TargetHW, host.TargetHW) ||
+		!cpeAttributeMatches(pattern.Other, host.Other) {
+		return false
+	}
+
+	hasRange := m.VersionStartIncluding != nil || m.VersionStartExcluding != nil ||
+		m.VersionEndIncluding != nil || m.VersionEndExcluding != nil
+	if !hasRange {
+		return cpeAttributeMatches(pattern.Version, host.Version)
+	}
+	if host.Version == "*" || host.Version == "-" || len(host.Version) == 0 {
+		// Version ranges cannot be evaluated without the host version.
+		return false
+	}
+
+	cmp := func(template string) int {
+		if len(m.VersionScheme) == 0 {
+			return VersionCompareProduct(m.Vendor, m.Product, template, host.Version, "", "")
+		}
+		return VersionCompareScheme(m.VersionScheme, m.Product, template, host.Version, "", "")
+	}
+	if m.VersionStartIncluding != nil {
+		if c := cmp(*m.VersionStartIncluding); c == -1 || c == 2 { // version < startIncluding
+			return false
+		}
+	} else if m.VersionStartExcluding != nil {
+		if c := cmp(*m.VersionStartExcluding); c == 0 || c == -1 || c == 2 { // version <= startExcluding
+			return false
+		}
+	}
+	if m.VersionEndIncluding != nil {
+		if c := cmp(*m.VersionEndIncluding); c == 1 || c == 2 { // version > endIncluding
+			return false
+		}
+	} else if m.VersionEndExcluding != nil {
+		if c := cmp(*m.VersionEndExcluding); c == 0 || c == 1 || c == 2 { // version >= endExcluding
+			return false
+		}
+	}
+	return true
+}
+
+// configurationTree represents the stored configuration nodes of an advisory.
+type configurationTree struct {
+	Roots    []int64
+	Children map[int64][]int64
+	Nodes    map[int64]cveConfigurationNode
+	Matches  map[int64][]cveConfigurationMatch
+}
+
+// evaluate evaluates node `nodeID` against the host CPEs `host`. Returns whether the node applies and whether
+// a vulnerable CPE of the host was matched within it.
+func (t configurationTree) evaluate(nodeID int64, host []CPEParts) (applies bool, vulnerable bool) {
+	node := t.Nodes[nodeID]
+	var results []bool
+	for _, m := range t.Matches[nodeID] {
+		matched := false
+		for _, cpe := range host {
+			if m.matchesHostCPE(cpe) {
+				matched = true
+				break
+			}
+		}
+		if matched && m.Vulnerable == 1 {
+			vulnerable = true
+		}
+		results = append(results, matched)
+	}
+	for _, childID := range t.Children[nodeID] {
+		childApplies, childVulnerable := t.evaluate(childID, host)
+		if childApplies && childVulnerable {
+			vulnerable = true
+		}
+		results = append(results, childApplies)
+	}
+
+	if node.Operator == "AND" {
+		applies = len(results) > 0
+		for _, result := range results {
+			if !result {
+				applies = false
+				break
+			}
+		}
+	} else {
+		for _, result := range results {
+			if result {
+				applies = true
+				break
+			}
+		}
+	}
+	if node.Negate == 1 {
+		applies = !applies
+	}
+	return applies, vulnerable
+}
+
+// MatchCVEsHost matches CVE advisories against a host by evaluating the NVD configurations (AND/OR nodes,
+// negation and non vulnerable "running on" CPEs) with the host context. A CVE is reported when one of its
+// configurations applies to the host and a vulnerable CPE of it matches the host. Rejected CVEs are excluded
+// unless included by the session (see SetIncludeRejected).
+// Returns the advisories sorted by public disclosure date (NVD published date if unknown) and CVE ID.
+func MatchCVEsHost(session *VulnDBSession, host HostContext) ([]CVEMatch, error) {
+	hostCPEs := host.cpes()
+
+	// Candidate advisories are those with a vulnerable configuration CPE of a host product.
+	candidates := map[int64]bool{}
+	var advisoryIDs []int64
+	for _, cpe := range hostCPEs {
+		var matches []cveConfigurationMatch
+		err := session.Where("systype = ? AND vendor = ? AND product = ? AND vulnerable = 1", cpe.Systype, cpe.Vendor, cpe.Product).Find(&matches)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if !candidates[m.AdvisoryID] {
+				candidates[m.AdvisoryID] = true
+				advisoryIDs = append(advisoryIDs, m.AdvisoryID)
+			}
+		}
+	}
+	if len(advisoryIDs) == 0 {
+		return nil, nil
+	}
+
+	var advisories []NVDCVEAdvisory
+	err := common.ProcessChunks(advisoryIDs, 900, func(start, end int) error {
+		advisoryIDs := advisoryIDs[start:end]
+		params := []interface{}{}
+		for _, id := range advisoryIDs {
+			params = append(params, id)
+		}
+
+		var nodes []cveConfigurationNode
+		err := session.Where(common.MakeInSql("advisory_id", len(advisoryIDs)), params...).OrderBy("id").Find(&nodes)
+		if err != nil {
+			return err
+		}
+		var matches []cveConfigurationMatch
+		err = session.Where(common.MakeInSql("advisory_id", len(advisoryIDs)), params...).Find(&matches)
+		if err != nil {
+			return err
+		}
+
+		trees := map[int64]*configurationTree{}
+		for _, node := range nodes {
+			tree, has := trees[node.AdvisoryID]
+			if !has {
+				tree = &configurationTree{
+					Children: map[int64][]int64{},
+					Nodes:    map[int64]cveConfigurationNode{},
+					Matches:  map[int64][]cveConfigurationMatch{},
+				}
+				trees[node.AdvisoryID] = tree
+			}
+			tree.Nodes[node.ID] = node
+			if node.ParentID == nil {
+				tree.Roots = append(tree.Roots, node.ID)
+			} else {
+				tree.Children[*node.ParentID] = append(tree.Children[*node.ParentID], node.ID)
+			}
+		}
+		for _, m := range matches {
+			if tree, has := trees[m.AdvisoryID]; has {
+				m.VersionScheme, err = session.versionScheme(m.Vendor, m.Product)
+				if err != nil {
+					return err
+				}
+				tree.Matches[m.NodeID] = append(tree.Matches[m.NodeID], m)
+			}
+		}
+
+		var matchedIDs []int64
+		for _, advisoryID := range advisoryIDs {
+			tree, has := trees[advisoryID]
+			if !has {
+				continue
+			}
+			for _, rootID := range tree.Roots {
+				if applies, vulnerable := tree.evaluate(rootID, hostCPEs); applies && vulnerable {
+					matchedIDs = append(matchedIDs, advisoryID)
+					break
+				}
+			}
+		}
+		if len(matchedIDs) == 0 {
+			return nil
+		}
+
+		params = []interface{}{}
+		for _, id := range matchedIDs {
+			params = append(params, id)
+		}
+		var advisoriesChunk []NVDCVEAdvisory
+		err = session.Where(common.MakeInSql("id", len(matchedIDs))+session.rejectedFilterSQL(), params...).Find(&advisoriesChunk)
+		if err != nil {
+			return err
+		}
+		advisories = append(advisories, advisoriesChunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyCVSSPolicy(session, advisories)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(advisories, func(i, j int) bool {
+		if advisories[i].DisclosedAt() != advisories[j].DisclosedAt() {
+			return advisories[i].DisclosedAt() < advisories[j].DisclosedAt()
+		}
+		return advisories[i].CVEID < advisories[j].CVEID
+	})
+
+	matches := make([]CVEMatch, 0, len(advisories))
+	for _, advisory := range advisories {
+		matches = append(matches, CVEMatch{Advisory: advisory})
+	}
+	return matches, nil
+}
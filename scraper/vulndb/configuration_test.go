@@ -0,0 +1,93 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationMatchHostCPE(t *testing.T) {
+	end := "2.4.10"
+	m := cveConfigurationMatch{
+		CPE23:               "cpe:2.3:a:apache:http_server:*:*:*:*:*:*:*:*",
+		Systype:             "a",
+		Vendor:              "apache",
+		Product:             "http_server",
+		VersionEndExcluding: &end,
+	}
+
+	testcases := []struct {
+		CPE      string
+		Expected bool
+	}{
+		{"cpe:2.3:a:apache:http_server:2.4.9:*:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:apache:http_server:2.4.10:*:*:*:*:*:*:*", false},
+		{"cpe:2.3:a:apache:http_server:*:*:*:*:*:*:*:*", false},
+		{"cpe:2.3:a:apache:tomcat:2.4.9:*:*:*:*:*:*:*", false},
+	}
+	for _, tcase := range testcases {
+		host, err := ParseCPE(tcase.CPE)
+		require.NoError(t, err)
+		require.Equal(t, tcase.Expected, m.matchesHostCPE(host), "CPE: %s", tcase.CPE)
+	}
+
+	m = cveConfigurationMatch{
+		CPE23:   "cpe:2.3:o:microsoft:windows:-:*:*:*:*:*:x64:*",
+		Systype: "o",
+		Vendor:  "microsoft",
+		Product: "windows",
+	}
+	host, err := ParseCPE("cpe:2.3:o:microsoft:windows:-:*:*:*:*:*:x64:*")
+	require.NoError(t, err)
+	require.True(t, m.matchesHostCPE(host))
+	host, err = ParseCPE("cpe:2.3:o:microsoft:windows:-:*:*:*:*:*:x86:*")
+	require.NoError(t, err)
+	require.False(t, m.matchesHostCPE(host))
+}
+
+func TestConfigurationTreeEvaluate(t *testing.T) {
+	// Application running on a specific OS: AND(OR(vulnerable app), OR(non vulnerable OS)).
+	parent := int64(1)
+	tree := configurationTree{
+		Roots:    []int64{1},
+		Children: map[int64][]int64{1: {2, 3}},
+		Nodes: map[int64]cveConfigurationNode{
+			1: {ID: 1, Operator: "AND"},
+			2: {ID: 2, ParentID: &parent, Operator: "OR"},
+			3: {ID: 3, ParentID: &parent, Operator: "OR"},
+		},
+		Matches: map[int64][]cveConfigurationMatch{
+			2: {{CPE23: "cpe:2.3:a:acme:agent:*:*:*:*:*:*:*:*", Systype: "a", Vendor: "acme", Product: "agent", Vulnerable: 1}},
+			3: {{CPE23: "cpe:2.3:o:microsoft:windows:-:*:*:*:*:*:*:*", Systype: "o", Vendor: "microsoft", Product: "windows"}},
+		},
+	}
+
+	parse := func(cpes ...string) []CPEParts {
+		var parts []CPEParts
+		for _, cpe := range cpes {
+			p, err := ParseCPE(cpe)
+			require.NoError(t, err)
+			parts = append(parts, p)
+		}
+		return parts
+	}
+
+	applies, vulnerable := tree.evaluate(1, parse("cpe:2.3:a:acme:agent:1.0:*:*:*:*:*:*:*", "cpe:2.3:o:microsoft:windows:-:*:*:*:*:*:*:*"))
+	require.True(t, applies)
+	require.True(t, vulnerable)
+
+	applies, _ = tree.evaluate(1, parse("cpe:2.3:a:acme:agent:1.0:*:*:*:*:*:*:*", "cpe:2.3:o:linux:linux_kernel:5.4:*:*:*:*:*:*:*"))
+	require.False(t, applies)
+
+	// Only the platform matches: applies to nothing vulnerable.
+	applies, vulnerable = tree.evaluate(3, parse("cpe:2.3:o:microsoft:windows:-:*:*:*:*:*:*:*"))
+	require.True(t, applies)
+	require.False(t, vulnerable)
+
+	// Negated node.
+	node := tree.Nodes[3]
+	node.Negate = 1
+	tree.Nodes[3] = node
+	applies, _ = tree.evaluate(1, parse("cpe:2.3:a:acme:agent:1.0:*:*:*:*:*:*:*", "cpe:2.3:o:linux:linux_kernel:5.4:*:*:*:*:*:*:*"))
+	require.True(t, applies)
+}
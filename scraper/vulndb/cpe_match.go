@@ -0,0 +1,216 @@
+package vulndb
+
+import (
+	"errors"
+	"strings"
+)
+
+// CPERelation is the set relation of a source and target CPE attribute value or name, as defined by the CPE Name
+// Matching specification (NISTIR 7696).
+type CPERelation int
+
+const (
+	CPEDisjoint  CPERelation = iota // The source and target have no values in common.
+	CPESubset                       // The source is a subset of the target.
+	CPESuperset                     // The source is a superset of the target.
+	CPEEqual                        // The source and target are equal.
+	CPEUndefined                    // The relation is undefined, e.g. target values with wildcards.
+)
+
+func (r CPERelation) String() string {
+	switch r {
+	case CPEDisjoint:
+		return "DISJOINT"
+	case CPESubset:
+		return "SUBSET"
+	case CPESuperset:
+		return "SUPERSET"
+	case CPEEqual:
+		return "EQUAL"
+	}
+	return "UNDEFINED"
+}
+
+// CPENameComparison is the attribute-wise comparison of a source and target CPE name, see CompareCPENames.
+type CPENameComparison []CPERelation
+
+// Disjoint returns true if any attribute of the source and target names is disjoint.
+func (c CPENameComparison) Disjoint() bool {
+	for _, r := range c {
+		if r == CPEDisjoint {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal returns true if all attributes of the source and target names are equal.
+func (c CPENameComparison) Equal() bool {
+	return c.all(CPEEqual)
+}
+
+// Subset returns true if the source name is a subset of (or equal to) the target name.
+func (c CPENameComparison) Subset() bool {
+	return c.all(CPESubset)
+}
+
+// Superset returns true if the source name is a superset of (or equal to) the target name, i.e. the source name
+// matches the target name.
+func (c CPENameComparison) Superset() bool {
+	return c.all(CPESuperset)
+}
+
+// all returns true if all attributes have relation `r` or are equal.
+func (c CPENameComparison) all(r CPERelation) bool {
+	for _, relation := range c {
+		if relation != r && relation != CPEEqual {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareCPENames compares the attributes of the `source` and `target` CPE names (part, vendor, product, version,
+// update, edition, language, sw_edition, target_sw, target_hw and other) with CompareCPEAttribute.
+func CompareCPENames(source, target CPEParts) CPENameComparison {
+	sourceAttrs := source.attributes()
+	targetAttrs := target.attributes()
+	comparison := make(CPENameComparison, len(sourceAttrs))
+	for i := range sourceAttrs {
+		comparison[i] = CompareCPEAttribute(sourceAttrs[i], targetAttrs[i])
+	}
+	return comparison
+}
+
+// CPEMatches returns true if the `source` CPE name (e.g. of an NVD configuration) matches the `target` CPE name,
+// i.e. the source is a superset of or equal to the target. CPE 2.2 and 2.3 names are supported.
+func CPEMatches(source, target string) (bool, error) {
+	sourceParts, err := ParseCPE(source)
+	if err != nil {
+		return false, err
+	}
+	targetParts, err := ParseCPE(target)
+	if err != nil {
+		return false, err
+	}
+	return CompareCPENames(sourceParts, targetParts).Superset(), nil
+}
+
+// MatchCPE matches CVEs for the CPE name `cpe` of installed software as MatchCVEs, additionally filtering the
+// product items by the CPE attributes (see MatchOptions.CPE), e.g. cpe:2.3:a:microsoft:office:2016:*:*:*:*:*:x64:*.
+func MatchCPE(session *VulnDBSession, cpe string) ([]CVEMatch, error) {
+	cpeParts, err := ParseCPE(cpe)
+	if err != nil {
+		return nil, err
+	}
+	if !cpeValueDefined(cpeParts.Version) {
+		return nil, errors.New("cpe without version")
+	}
+	patch := cpeParts.Patch
+	if !cpeValueDefined(patch) {
+		patch = ""
+	}
+	targetSW := cpeParts.TargetSW
+	if !cpeValueDefined(targetSW) {
+		targetSW = ""
+	}
+
+	opts := DefaultMatchOptions()
+	opts.CPE = cpe
+	matches, err := MatchCVEsWithOptions(session, opts, cpeParts.Systype, cpeParts.Vendor, cpeParts.Product,
+		cpeParts.Version, patch, targetSW)
+	if err != nil {
+		return nil, err
+	}
+	return sortByDisclosure(matches), nil
+}
+
+// cpeValueDefined returns true if the attribute value `value` is neither ANY nor NA.
+func cpeValueDefined(value string) bool {
+	return len(value) > 0 && value != "*" && value != "-"
+}
+
+// attributes returns the attribute values of the CPE name in the order of CPE 2.3 formatted strings.
+func (p CPEParts) attributes() []string {
+	return []string{p.Systype, p.Vendor, p.Product, p.Version, p.Patch, p.Edition, p.Language, p.SWEdition,
+		p.TargetSW, p.TargetHW, p.Other}
+}
+
+// CompareCPEAttribute compares the `source` and `target` attribute values of CPE names per NISTIR 7696 table 6-2.
+// The logical value ANY is "*" or empty (attributes missing in CPE 2.2 names) and NA is "-". Values may have the
+// wildcards "*" (zero or more characters) and "?" (exactly one character) at the beginning and end, e.g. "10.*".
+// Values are compared case-insensitively.
+func CompareCPEAttribute(source, target string) CPERelation {
+	source = strings.ToLower(source)
+	target = strings.ToLower(target)
+	sourceAny := source == "*" || len(source) == 0
+	targetAny := target == "*" || len(target) == 0
+
+	switch {
+	case sourceAny && targetAny:
+		return CPEEqual
+	case hasCPEWildcards(target) && !targetAny:
+		return CPEUndefined
+	case sourceAny:
+		return CPESuperset
+	case targetAny:
+		return CPESubset
+	case source == "-" || target == "-":
+		if source == target {
+			return CPEEqual
+		}
+		return CPEDisjoint
+	case source == target:
+		return CPEEqual
+	case hasCPEWildcards(source) && cpeWildcardMatch(source, target):
+		return CPESuperset
+	}
+	return CPEDisjoint
+}
+
+// hasCPEWildcards returns true if the attribute value `value` begins or ends with a wildcard.
+func hasCPEWildcards(value string) bool {
+	return strings.HasPrefix(value, "*") || strings.HasPrefix(value, "?") ||
+		strings.HasSuffix(value, "*") || strings.HasSuffix(value, "?")
+}
+
+// cpeWildcardMatch returns true if `value` matches `pattern` with leading and trailing wildcards. Wildcards
+// embedded in the pattern are matched literally.
+func cpeWildcardMatch(pattern, value string) bool {
+	p := []rune(pattern)
+	v := []rune(value)
+
+	// Leading wildcards: the number of "?" is the minimum (or exact if no "*") number of preceding characters.
+	leadAny, leadCount := false, 0
+	for len(p) > 0 && (p[0] == '*' || p[0] == '?') {
+		if p[0] == '*' {
+			leadAny = true
+		} else {
+			leadCount++
+		}
+		p = p[1:]
+	}
+	trailAny, trailCount := false, 0
+	for len(p) > 0 && (p[len(p)-1] == '*' || p[len(p)-1] == '?') {
+		if p[len(p)-1] == '*' {
+			trailAny = true
+		} else {
+			trailCount++
+		}
+		p = p[:len(p)-1]
+	}
+
+	body := string(p)
+	for start := 0; start+len(p) <= len(v); start++ {
+		if string(v[start:start+len(p)]) != body {
+			continue
+		}
+		before := start
+		after := len(v) - start - len(p)
+		if (before == leadCount || (leadAny && before > leadCount)) &&
+			(after == trailCount || (trailAny && after > trailCount)) {
+			return true
+		}
+	}
+	return false
+}
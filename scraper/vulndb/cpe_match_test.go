@@ -0,0 +1,86 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareCPEAttribute(t *testing.T) {
+	testcases := []struct {
+		source   string
+		target   string
+		expected CPERelation
+	}{
+		// NISTIR 7696 table 6-2.
+		{"*", "*", CPEEqual},
+		{"*", "-", CPESuperset},
+		{"*", "x64", CPESuperset},
+		{"*", "x*", CPEUndefined},
+		{"-", "*", CPESubset},
+		{"-", "-", CPEEqual},
+		{"-", "x64", CPEDisjoint},
+		{"-", "x*", CPEUndefined},
+		{"x64", "*", CPESubset},
+		{"x64", "-", CPEDisjoint},
+		{"x64", "X64", CPEEqual},
+		{"x64", "x86", CPEDisjoint},
+		{"x64", "x*", CPEUndefined},
+		{"x*", "*", CPESubset},
+		{"x*", "-", CPEDisjoint},
+		{"x*", "x64", CPESuperset},
+		{"x*", "arm64", CPEDisjoint},
+		{"x*", "x*", CPEUndefined},
+
+		// CPE 2.2 names have no trailing attributes.
+		{"", "x64", CPESuperset},
+		{"x64", "", CPESubset},
+
+		// Wildcards.
+		{"10.*", "10.0.1", CPESuperset},
+		{"10.*", "10.", CPESuperset},
+		{"10.?", "10.1", CPESuperset},
+		{"10.?", "10.12", CPEDisjoint},
+		{"??.1", "10.1", CPESuperset},
+		{"??.1", "1.1", CPEDisjoint},
+		{"*?.1", "100.1", CPESuperset},
+		{"*?.1", ".1", CPEDisjoint},
+		{"*sp1*", "win_sp1_x64", CPESuperset},
+		{"1*0", "1*0", CPEEqual},
+		{"1*0", "100", CPEDisjoint},
+	}
+
+	for _, tc := range testcases {
+		require.Equal(t, tc.expected, CompareCPEAttribute(tc.source, tc.target), "%s/%s", tc.source, tc.target)
+	}
+}
+
+func TestCompareCPENames(t *testing.T) {
+	source, err := ParseCPE("cpe:2.3:a:microsoft:office:2016:*:*:*:*:*:x64:*")
+	require.NoError(t, err)
+	target, err := ParseCPE("cpe:2.3:a:microsoft:office:2016:sp1:*:en:*:*:x64:*")
+	require.NoError(t, err)
+
+	comparison := CompareCPENames(source, target)
+	require.Len(t, comparison, 11)
+	require.Equal(t, CPESuperset, comparison[4])
+	require.True(t, comparison.Superset())
+	require.False(t, comparison.Subset())
+	require.False(t, comparison.Equal())
+	require.False(t, comparison.Disjoint())
+
+	require.True(t, CompareCPENames(source, source).Equal())
+	require.True(t, CompareCPENames(target, source).Subset())
+
+	matches, err := CPEMatches("cpe:2.3:a:microsoft:office:2016:*:*:*:*:*:x64:*", "cpe:/a:microsoft:office:2016")
+	require.NoError(t, err)
+	require.False(t, matches)
+	matches, err = CPEMatches("cpe:/a:microsoft:office:2016", "cpe:2.3:a:microsoft:office:2016:*:*:*:*:*:x86:*")
+	require.NoError(t, err)
+	require.True(t, matches)
+	matches, err = CPEMatches("cpe:2.3:a:microsoft:office:2016:*:*:*:*:*:x64:*", "cpe:2.3:a:microsoft:office:2016:*:*:*:*:*:x86:*")
+	require.NoError(t, err)
+	require.False(t, matches)
+	_, err = CPEMatches("cpe:2.3:a:microsoft", "cpe:/a:microsoft:office:2016")
+	require.Error(t, err)
+}
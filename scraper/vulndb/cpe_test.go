@@ -11,6 +11,17 @@ func TestParseCPE(t *testing.T) {
 		CPE      string
 		Expected CPEParts
 	}{
+		{
+			"cpe:/a:Microsoft:Internet_Explorer:8.0.6001:Beta",
+			CPEParts{
+				CPEVersion: 22,
+				Systype:    "a",
+				Vendor:     "Microsoft",
+				Product:    "Internet_Explorer",
+				Version:    "8.0.6001",
+				Patch:      "Beta",
+			},
+		},
 		{
 			"cpe:/a:microsoft:internet_explorer:8.0.6001:beta",
 			CPEParts{
@@ -145,6 +156,35 @@ func TestParseCPE(t *testing.T) {
 				Other:      "*",
 			},
 		},
+		{
+			// Escaped parentheses.
+			"cpe:/a:foo:bar%5c%28x%5c%29:1.0",
+			CPEParts{
+				CPEVersion: 22,
+				Systype:    "a",
+				Vendor:     "foo",
+				Product:    "bar(x)",
+				Version:    "1.0",
+			},
+		},
+		{
+			// Embedded wildcards of NVD names.
+			"cpe:2.3:a:foo:bar:1.*.2:*:*:*:*:*:*:*",
+			CPEParts{
+				CPEVersion: 23,
+				Systype:    "a",
+				Vendor:     "foo",
+				Product:    "bar",
+				Version:    "1.*.2",
+				Patch:      "*",
+				Edition:    "*",
+				Language:   "*",
+				SWEdition:  "*",
+				TargetSW:   "*",
+				TargetHW:   "*",
+				Other:      "*",
+			},
+		},
 	}
 
 	for _, tcase := range testcases {
@@ -153,3 +193,37 @@ func TestParseCPE(t *testing.T) {
 		require.Equal(t, tcase.Expected, cpeParts, "CPE: %s", tcase.CPE)
 	}
 }
+
+func TestCPEPartsWFN(t *testing.T) {
+	testcases := []struct {
+		CPE      CPEParts
+		Expected string
+	}{
+		{
+			CPEParts{Systype: "a", Vendor: "adobe", Product: "airsdk&_compiler", Version: "18.0.0.180"},
+			`cpe:2.3:a:adobe:airsdk\&_compiler:18.0.0.180:*:*:*:*:*:*:*`,
+		},
+		{
+			CPEParts{Systype: "a", Vendor: "hp", Product: "insight_diagnostics", Version: "8.*", Patch: "es?",
+				Language: "-", SWEdition: "-", TargetSW: "x32"},
+			"cpe:2.3:a:hp:insight_diagnostics:8.*:es?:*:-:-:x32:*:*",
+		},
+		{
+			CPEParts{Systype: "a", Vendor: "archive::tar_project", Product: "archive::tar", TargetSW: "perl"},
+			`cpe:2.3:a:archive\:\:tar_project:archive\:\:tar:*:*:*:*:*:perl:*:*`,
+		},
+	}
+
+	for _, tcase := range testcases {
+		fs := tcase.CPE.WFN().BindToFS()
+		require.Equal(t, tcase.Expected, fs)
+		cpeParts, err := ParseCPE(fs)
+		require.NoError(t, err)
+		require.Equal(t, tcase.CPE.WFN(), cpeParts.WFN())
+	}
+
+	for _, invalid := range []string{"cpe:/x:foo:bar", "cpe:2.3:a:foo:bar:1.0"} {
+		_, err := ParseCPE(invalid)
+		require.Error(t, err, invalid)
+	}
+}
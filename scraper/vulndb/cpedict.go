@@ -4,6 +4,7 @@ import (
 	"compress/gzip"
 	"encoding/xml"
 	"os"
+	"strings"
 )
 
 // xmlCPEDict represents content of the official CPE dictionary xml file.
@@ -29,6 +30,7 @@ func loadCPEDict(inputPath string) (*xmlCPEDict, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
 	gzReader, err := gzip.NewReader(f)
 	if err != nil {
@@ -45,3 +47,53 @@ func loadCPEDict(inputPath string) (*xmlCPEDict, error) {
 
 	return &cpeDict, err
 }
+
+// processCPEDictionary loads the product titles of the CPE dictionary at `cpeDictPath` into vulndb for fuzzy
+// product resolution. Deprecated items are skipped and the titles are stored once per product without the version.
+func processCPEDictionary(sessionw *VulnDBSession, cpeDictPath string) error {
+	if len(cpeDictPath) == 0 {
+		return nil
+	}
+
+	cpeDict, err := loadCPEDict(cpeDictPath)
+	if err != nil {
+		return err
+	}
+
+	seen := map[cpeDictionaryTitle]bool{}
+	for _, item := range cpeDict.Items {
+		if item.Deprecated != nil && *item.Deprecated == "true" {
+			continue
+		}
+		cpeParts, err := ParseCPE(item.CPE23.Name)
+		if err != nil {
+			log.Debugf("Invalid CPE dictionary CPE '%s' - skipping", item.CPE23.Name)
+			continue
+		}
+		title := item.Title
+		if len(cpeParts.Version) > 0 && cpeParts.Version != "*" && cpeParts.Version != "-" {
+			title = strings.Replace(title, cpeParts.Version, "", 1)
+		}
+		title = strings.Join(strings.Fields(title), " ")
+		if len(title) == 0 {
+			continue
+		}
+
+		entry := cpeDictionaryTitle{
+			Vendor:  cpeParts.Vendor,
+			Product: cpeParts.Product,
+			Title:   title,
+		}
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		err = sessionw.Insert(&entry)
+		if err != nil {
+			return err
+		}
+	}
+	log.Debugf("Loaded %d CPE dictionary titles", len(seen))
+
+	return nil
+}
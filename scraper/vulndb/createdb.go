@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"regexp"
 	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"xorm.io/xorm"
@@ -24,6 +24,15 @@ type CreateDBParams struct {
 	ProductAliasesPath    string
 	ProductIgnoreListPath string
 	MSRCDataPath          string
+	JuniperJSAPath        string // Optional directory of Juniper JSA advisories (HTML/JSON).
+	CSAFPath              string // Optional directory of CSAF 2.0 documents.
+	MozillaMFSAPath       string // Optional directory of Mozilla MFSA advisories (foundation-security-advisories announce dir).
+	RedhatCVEDatesPath    string // Optional Red Hat cve_dates.txt file.
+	PreviousVulnDBPath    string // Optional previous vulndb to record removed CVEs, defaults to an existing VulnDBPath.
+	CPEDictionaryPath     string // Optional gzipped official CPE dictionary for fuzzy product resolution.
+	PURLAliasesPath       string // Optional purl aliases mapping package URLs to vulndb products.
+	VersionSchemesPath    string // Version scheme assignments of vendor/product globs, e.g. data/version_schemes.xml.
+	PlatformsPath         string // Platform definitions (CPE and product name patterns), e.g. data/platforms.xml.
 	//CiscoDataPath          string
 	ProductPlatformMapping map[string][]string
 }
@@ -50,6 +59,14 @@ func (p CreateDBParams) Validate() bool {
 		return false
 	}
 
+	if len(p.PlatformsPath) == 0 {
+		return false
+	}
+
+	if len(p.VersionSchemesPath) == 0 {
+		return false
+	}
+
 	return true
 }
 
@@ -59,6 +76,16 @@ func CreateDB(params CreateDBParams) error {
 		return errors.New("invalid params")
 	}
 
+	// Load the CVEs of the previous build before it is replaced.
+	previousPath := params.PreviousVulnDBPath
+	if len(previousPath) == 0 {
+		previousPath = params.VulnDBPath
+	}
+	previous, err := loadPreviousBuild(previousPath)
+	if err != nil {
+		return err
+	}
+
 	// Remove if exists.
 	os.Remove(params.VulnDBPath)
 
@@ -79,6 +106,12 @@ func CreateDB(params CreateDBParams) error {
 		return err
 	}
 
+	// Platform definitions, mapping the NVD CVE data and vendor advisories to platforms.
+	err = processPlatforms(sessionw, params.PlatformsPath)
+	if err != nil {
+		return err
+	}
+
 	// Load NVD CVE data into vulndb.
 	err = processNVDCVE(sessionw, params.CVEPaths)
 	if err != nil {
@@ -104,12 +137,48 @@ func CreateDB(params CreateDBParams) error {
 		return err
 	}
 
+	// Process purl aliases.
+	err = processPURLAliases(sessionw, params.PURLAliasesPath)
+	if err != nil {
+		return err
+	}
+
 	// Process ignore list.
 	err = processProductIgnoreList(sessionw, params.ProductIgnoreListPath)
 	if err != nil {
 		return err
 	}
 
+	// Process version scheme assignments.
+	err = processVersionSchemes(sessionw, params.VersionSchemesPath)
+	if err != nil {
+		return err
+	}
+
+	// Process CPE dictionary product titles.
+	err = processCPEDictionary(sessionw, params.CPEDictionaryPath)
+	if err != nil {
+		return err
+	}
+
+	// Process Juniper JSA advisories (replacing NVD Junos ranges).
+	err = processJuniperJSA(sessionw, params.JuniperJSAPath)
+	if err != nil {
+		return err
+	}
+
+	// Process CSAF 2.0 advisories.
+	err = processCSAF(sessionw, params.CSAFPath)
+	if err != nil {
+		return err
+	}
+
+	// Process Mozilla MFSA advisories (replacing NVD Firefox/Thunderbird ranges).
+	err = processMozillaMFSA(sessionw, params.MozillaMFSAPath)
+	if err != nil {
+		return err
+	}
+
 	err = processMSRCData(sessionw, params.MSRCDataPath)
 	if err != nil {
 		return err
@@ -120,6 +189,18 @@ func CreateDB(params CreateDBParams) error {
 		return err
 	}
 
+	// Process Red Hat CVE dates (after all advisories are in place).
+	err = processRedhatCVEDates(sessionw, params.RedhatCVEDatesPath)
+	if err != nil {
+		return err
+	}
+
+	// Record CVEs removed since the previous build (after all advisories are in place).
+	err = processRemovedCVEs(sessionw, previous, time.Now().UTC().Unix())
+	if err != nil {
+		return err
+	}
+
 	//err = processCiscoData(sessionw, params.CiscoDataPath, params.ProductPlatformMapping)
 	//if err != nil {
 	//	return err
@@ -136,16 +217,10 @@ func CreateDB(params CreateDBParams) error {
 // processNVDCVE loads and processes NVD CVE advisories, outputting to the Nanitor vulndb.
 // `cvePaths` specifies an input slice of NVD CVE files to be processed, e.g. 2002-2018.
 func processNVDCVE(sessionw *VulnDBSession, cvePaths []string) error {
-	var platforms []platforms
-	if err := sessionw.curSession.Find(&platforms); err != nil {
+	platformMapping, err := loadPlatformMappingRules(sessionw, SourceCPE)
+	if err != nil {
 		return err
 	}
-	platformMapping := make(map[int64][]*regexp.Regexp)
-	for _, p := range platforms {
-		for _, exp := range strings.Split(p.Rule, ",") {
-			platformMapping[p.ID] = append(platformMapping[p.ID], regexp.MustCompile(exp))
-		}
-	}
 	platformVulnExist := make(map[string]bool)
 	for _, cvePath := range cvePaths {
 		log.Debugf("Processing %s", cvePath)
@@ -161,6 +236,7 @@ func processNVDCVE(sessionw *VulnDBSession, cvePaths []string) error {
 			advisory := NVDCVEAdvisory{}
 			advisory.CVEID = cve.CVEID
 			advisory.Summary = cve.Summary
+			advisory.Status = &cve.Status
 			advisory.PublishedAt = cve.PublishedAtInt
 			advisory.LastModifiedAt = cve.LastModifiedAtInt
 
@@ -171,6 +247,20 @@ func processNVDCVE(sessionw *VulnDBSession, cvePaths []string) error {
 				advisory.CVSS2AccessComplexity = cve.CVSS2.AccessComplexity
 				advisory.CVSS2Authentication = cve.CVSS2.Authentication
 				advisory.CVSS2ConfidentialityImpact = cve.CVSS2.ConfidentialityImpact
+				advisory.CVSS2IntegrityImpact = cve.CVSS2.IntegrityImpact
+				advisory.CVSS2AvailabilityImpact = cve.CVSS2.AvailabilityImpact
+				if len(cve.CVSS2.VectorString) > 0 {
+					advisory.CVSS2VectorString = &cve.CVSS2.VectorString
+				}
+				if cve.CVSS2.Severity != 0 {
+					advisory.CVSS2Severity = &cve.CVSS2.Severity
+				}
+				advisory.CVSS2ExploitabilityScore = cve.CVSS2.ExploitabilityScore
+				advisory.CVSS2ImpactScore = cve.CVSS2.ImpactScore
+				advisory.CVSS2ObtainAllPrivilege = cve.CVSS2.ObtainAllPrivilege
+				advisory.CVSS2ObtainUserPrivilege = cve.CVSS2.ObtainUserPrivilege
+				advisory.CVSS2ObtainOtherPrivilege = cve.CVSS2.ObtainOtherPrivilege
+				advisory.CVSS2UserInteractionRequired = cve.CVSS2.UserInteractionRequired
 			}
 			if cve.CVSS3 != nil {
 				advisory.CVSS3BaseScore = &cve.CVSS3.BaseScore
@@ -185,6 +275,13 @@ func processNVDCVE(sessionw *VulnDBSession, cvePaths []string) error {
 				advisory.CVSS3VectorString = &cve.CVSS3.VectorString
 				advisory.CVSS3ExploitabilityScore = cve.CVSS3.ExploitabilityScore
 			}
+			if cve.CVSS4 != nil {
+				advisory.CVSS4VectorString = &cve.CVSS4.VectorString
+				advisory.CVSS4BaseScore = &cve.CVSS4.BaseScore
+				if cve.CVSS4.BaseSeverity != 0 {
+					advisory.CVSS4BaseSeverity = &cve.CVSS4.BaseSeverity
+				}
+			}
 
 			if len(cve.VendorRefURL) > 0 {
 				advisory.VendorRefUrl = &cve.VendorRefURL
@@ -212,6 +309,16 @@ func processNVDCVE(sessionw *VulnDBSession, cvePaths []string) error {
 			}
 
 			advisoryIDs[advisory.CVEID] = advisory.Id
+
+			err = insertCVEReferences(sessionw, cve.CVEID, cve.References)
+			if err != nil {
+				return err
+			}
+
+			err = insertCVEConfigurations(sessionw, advisory.Id, nil, cve.Configurations)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Vulnerabilities down to systype - vendor - product - version - patch/update.
@@ -328,6 +435,10 @@ func processNVDCVE(sessionw *VulnDBSession, cvePaths []string) error {
 						var vuln vulndbVulnerability
 						vuln.ProductItemID = prodItem.ID
 						vuln.AdvisoryID = advisoryIDs[entry.CVEID]
+						if len(entry.RawCPE23) > 0 {
+							cpe23 := entry.RawCPE23
+							vuln.CPE23 = &cpe23
+						}
 						err = sessionw.Insert(&vuln)
 						if err != nil {
 							return err
@@ -341,6 +452,89 @@ func processNVDCVE(sessionw *VulnDBSession, cvePaths []string) error {
 	return nil
 }
 
+// getOrCreateVendor returns the vendor by `name`, creating it if not present.
+func getOrCreateVendor(sessionw *VulnDBSession, name string) (*VulndbVendor, error) {
+	var vendor VulndbVendor
+	has, err := sessionw.Where("name = ?", name).Get(&vendor)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		vendor.Name = name
+		err := sessionw.Insert(&vendor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &vendor, nil
+}
+
+// getOrCreateProduct returns the product by `vendorID` and `name`, creating it if not present.
+func getOrCreateProduct(sessionw *VulnDBSession, vendorID int64, name string) (*vulndbProduct, error) {
+	var prod vulndbProduct
+	has, err := sessionw.Where("vendor_id = ? AND product_name = ?", vendorID, name).Get(&prod)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		prod.VendorID = vendorID
+		prod.ProductName = name
+		err := sessionw.Insert(&prod)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &prod, nil
+}
+
+// getOrCreateProductItem returns the product item for `productID` and `systype` matching the version range `r`
+// exactly, creating it if not present.
+func getOrCreateProductItem(sessionw *VulnDBSession, productID int64, systype string, r versionRange) (*vulndbProductItem, error) {
+	item := vulndbProductItem{
+		ProductID: productID,
+		Systype:   systype,
+	}
+	cols := []struct {
+		Name  string
+		Value string
+		Dst   **string
+	}{
+		{"version", r.Version, &item.Version},
+		{"version_start_including", r.VersionStartIncluding, &item.VersionStartIncluding},
+		{"version_start_excluding", r.VersionStartExcluding, &item.VersionStartExcluding},
+		{"version_end_including", r.VersionEndIncluding, &item.VersionEndIncluding},
+		{"version_end_excluding", r.VersionEndExcluding, &item.VersionEndExcluding},
+	}
+
+	whereSQL := `product_id = ? AND systype = ? AND patch = '' AND sw_target IS NULL`
+	params := []interface{}{productID, systype}
+	for _, col := range cols {
+		if len(col.Value) == 0 {
+			whereSQL += ` AND ` + col.Name + ` IS NULL`
+			continue
+		}
+		whereSQL += ` AND ` + col.Name + ` = ?`
+		params = append(params, col.Value)
+		val := col.Value
+		*col.Dst = &val
+	}
+
+	var existing vulndbProductItem
+	has, err := sessionw.Where(whereSQL, params...).Get(&existing)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return &existing, nil
+	}
+
+	err = sessionw.Insert(&item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
 // processVendorAliases loads vendor aliases for XML and puts into vulndb.
 func processVendorAliases(sessionw *VulnDBSession, vendorAliasesPath string) error {
 	valiases, err := loadVendorAliases(vendorAliasesPath)
@@ -445,16 +639,10 @@ func processMSRCData(sessionw *VulnDBSession, msrcJSONPath string) error {
 	if err := json.Unmarshal(content, &data); err != nil {
 		return err
 	}
-	var platforms []platforms
-	if err := sessionw.curSession.Find(&platforms); err != nil {
+	platformMappingRules, err := loadPlatformMappingRules(sessionw, SourceMSRC)
+	if err != nil {
 		return err
 	}
-	platformMappingRules := make(map[int64][]*regexp.Regexp)
-	for _, p := range platforms {
-		for _, exp := range strings.Split(p.Rule, ",") {
-			platformMappingRules[p.ID] = append(platformMappingRules[p.ID], regexp.MustCompile(exp))
-		}
-	}
 	uniquePlatformVuln := make(map[string]bool)
 	for cveID, patchInfo := range data.Vulnerabilities {
 		var advisory NVDCVEAdvisory
@@ -488,16 +676,9 @@ func processMSRCData(sessionw *VulnDBSession, msrcJSONPath string) error {
 			}
 		}
 	}
-	return nil
-}
 
-func isPlatformMatchRulePassed(rules []*regexp.Regexp, value string) bool {
-	for _, rule := range rules {
-		if rule.MatchString(value) {
-			return true
-		}
-	}
-	return false
+	// Vendor CVSS scores.
+	return processMSRCCVSS(sessionw, content, time.Now().UTC().Unix())
 }
 
 func processWindowsVersions(session *VulnDBSession) error {
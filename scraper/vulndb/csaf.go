@@ -0,0 +1,737 @@
+package vulndb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CSAF product status categories (vulnerabilities[].product_status).
+const (
+	CSAFStatusKnownAffected      = "known_affected"
+	CSAFStatusFirstAffected      = "first_affected"
+	CSAFStatusLastAffected       = "last_affected"
+	CSAFStatusFixed              = "fixed"
+	CSAFStatusFirstFixed         = "first_fixed"
+	CSAFStatusKnownNotAffected   = "known_not_affected"
+	CSAFStatusUnderInvestigation = "under_investigation"
+)
+
+// csafDocument represents a CSAF 2.0 document (only the parts used by vulndb).
+type csafDocument struct {
+	Document struct {
+		Category  string `json:"category"`
+		Title     string `json:"title"`
+		Publisher struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+			Category  string `json:"category"`
+		} `json:"publisher"`
+		Tracking struct {
+			ID                 string `json:"id"`
+			InitialReleaseDate string `json:"initial_release_date"`
+			CurrentReleaseDate string `json:"current_release_date"`
+		} `json:"tracking"`
+		References []struct {
+			Category string `json:"category"`
+			URL      string `json:"url"`
+		} `json:"references"`
+	} `json:"document"`
+	ProductTree struct {
+		Branches         []csafBranch          `json:"branches"`
+		FullProductNames []csafFullProductName `json:"full_product_names"`
+		Relationships    []struct {
+			Category                  string              `json:"category"`
+			ProductReference          string              `json:"product_reference"`
+			RelatesToProductReference string              `json:"relates_to_product_reference"`
+			FullProductName           csafFullProductName `json:"full_product_name"`
+		} `json:"relationships"`
+	} `json:"product_tree"`
+	Vulnerabilities []csafVulnerability `json:"vulnerabilities"`
+}
+
+// csafBranch represents a (recursive) branch of the CSAF product tree.
+type csafBranch struct {
+	Category string               `json:"category"`
+	Name     string               `json:"name"`
+	Branches []csafBranch         `json:"branches"`
+	Product  *csafFullProductName `json:"product"`
+}
+
+// csafFullProductName represents a leaf product in the CSAF product tree.
+type csafFullProductName struct {
+	ProductID                   string `json:"product_id"`
+	Name                        string `json:"name"`
+	ProductIdentificationHelper *struct {
+		CPE  string `json:"cpe"`
+		PURL string `json:"purl"`
+	} `json:"product_identification_helper"`
+}
+
+// csafVulnerability represents an item of the CSAF vulnerabilities list.
+type csafVulnerability struct {
+	CVE           string              `json:"cve"`
+	Title         string              `json:"title"`
+	ProductStatus map[string][]string `json:"product_status"`
+	Remediations  []csafRemediation   `json:"remediations"`
+	Flags         []struct {
+		Label      string   `json:"label"`
+		ProductIDs []string `json:"product_ids"`
+	} `json:"flags"`
+	Threats []struct {
+		Category   string   `json:"category"`
+		Details    string   `json:"details"`
+		ProductIDs []string `json:"product_ids"`
+	} `json:"threats"`
+}
+
+// csafRemediation represents a remediation for a set of products, e.g. vendor_fix, workaround, mitigation.
+type csafRemediation struct {
+	Category   string   `json:"category"`
+	Details    string   `json:"details"`
+	URL        string   `json:"url"`
+	ProductIDs []string `json:"product_ids"`
+}
+
+// csafProduct represents a product resolved from the CSAF product tree.
+type csafProduct struct {
+	ProductID string
+	Name      string // Full product name.
+	Vendor    string // Vendor branch name (or publisher if none).
+	Product   string // Product name branch name (or full product name if none).
+	Version   string // Exact version if given by a product_version branch.
+	Ranges    []versionRange
+	CPE       string
+	PURL      string
+	Platform  *csafProduct // Set for relationship products, e.g. component installed_on platform.
+}
+
+// csafProductStatus represents the status of a resolved product for a CVE.
+type csafProductStatus struct {
+	CVEID       string
+	Status      string
+	Product     *csafProduct
+	Remediation *csafRemediation
+}
+
+// csafAdvisory represents a loaded and resolved CSAF document.
+type csafAdvisory struct {
+	TrackingID     string
+	Title          string
+	Publisher      string
+	URL            string
+	PublishedAt    int64
+	LastModifiedAt int64
+	CVEIDs         []string
+	Statuses       []csafProductStatus
+}
+
+// versionRange represents a version or version range as used in vulndb product items.
+type versionRange struct {
+	Version               string
+	VersionStartIncluding string
+	VersionStartExcluding string
+	VersionEndIncluding   string
+	VersionEndExcluding   string
+}
+
+var reVersConstraint = regexp.MustCompile(`^(>=|<=|!=|<|>|=)?\s*(.+)$`)
+
+// parseVersionRange parses a product version range, either in vers format (e.g. "vers:generic/>=1.0|<2.0")
+// or as plain constraints (e.g. ">= 1.0, < 2.0"). Disjoint ranges are returned as separate items.
+func parseVersionRange(rng string) ([]versionRange, error) {
+	rng = strings.TrimSpace(rng)
+	if strings.HasPrefix(rng, "vers:") {
+		idx := strings.Index(rng, "/")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid vers range: %s", rng)
+		}
+		rng = rng[idx+1:]
+	}
+	if rng == "*" || len(rng) == 0 {
+		return nil, fmt.Errorf("unbounded version range: %s", rng)
+	}
+
+	var constraints []string
+	for _, part := range strings.FieldsFunc(rng, func(r rune) bool { return r == '|' || r == ',' }) {
+		part = strings.TrimSpace(part)
+		if len(part) > 0 {
+			constraints = append(constraints, part)
+		}
+	}
+
+	var ranges []versionRange
+	var cur versionRange
+	hasCur := false
+	for _, c := range constraints {
+		parts := reVersConstraint.FindStringSubmatch(c)
+		if parts == nil {
+			return nil, fmt.Errorf("invalid version constraint: %s", c)
+		}
+		op, ver := parts[1], strings.TrimSpace(parts[2])
+		switch op {
+		case "", "=":
+			if hasCur {
+				ranges = append(ranges, cur)
+			}
+			ranges = append(ranges, versionRange{Version: ver})
+			cur, hasCur = versionRange{}, false
+		case ">=", ">":
+			if hasCur {
+				ranges = append(ranges, cur)
+			}
+			cur, hasCur = versionRange{}, true
+			if op == ">=" {
+				cur.VersionStartIncluding = ver
+			} else {
+				cur.VersionStartExcluding = ver
+			}
+		case "<=", "<":
+			if op == "<=" {
+				cur.VersionEndIncluding = ver
+			} else {
+				cur.VersionEndExcluding = ver
+			}
+			ranges = append(ranges, cur)
+			cur, hasCur = versionRange{}, false
+		case "!=":
+			// Exclusions cannot be represented, ignore.
+		}
+	}
+	if hasCur {
+		ranges = append(ranges, cur)
+	}
+
+	return ranges, nil
+}
+
+// hasEnd returns true if the range is an exact version or has an upper bound, as required for matching.
+func (r versionRange) hasEnd() bool {
+	return len(r.Version) > 0 || len(r.VersionEndIncluding) > 0 || len(r.VersionEndExcluding) > 0
+}
+
+var reVersionTrain = regexp.MustCompile(`^\d+(?:\.\d+)*`)
+
+// fixedTrainRange returns the range of the versions of the train (release line) of the `fixed` version before it,
+// e.g. >= 3.3, < 3.3.5 for 3.3.5 or >= 15.2, < 15.2(4)E10 for 15.2(4)E10. The train is the leading numeric
+// version, without its last component if the version is only numeric. Returns false if there is no train, e.g.
+// for 10, as a fixed version does not tell which earlier trains are affected.
+func fixedTrainRange(fixed string) (versionRange, bool) {
+	train := reVersionTrain.FindString(fixed)
+	if len(train) == 0 {
+		return versionRange{}, false
+	}
+	if train == fixed {
+		idx := strings.LastIndex(train, ".")
+		if idx < 0 {
+			return versionRange{}, false
+		}
+		train = train[:idx]
+	}
+	return versionRange{VersionStartIncluding: train, VersionEndExcluding: fixed}, true
+}
+
+// resolveCSAFProducts resolves all products in the product tree of `doc` by product id.
+func resolveCSAFProducts(doc *csafDocument) map[string]*csafProduct {
+	products := map[string]*csafProduct{}
+
+	var walk func(branches []csafBranch, parent csafProduct)
+	walk = func(branches []csafBranch, parent csafProduct) {
+		for _, branch := range branches {
+			cur := parent
+			switch branch.Category {
+			case "vendor":
+				cur.Vendor = branch.Name
+			case "product_name", "product_family":
+				cur.Product = branch.Name
+			case "product_version":
+				cur.Version = branch.Name
+			case "product_version_range":
+				ranges, err := parseVersionRange(branch.Name)
+				if err != nil {
+					log.Debugf("CSAF: %v - ignoring", err)
+				}
+				cur.Ranges = ranges
+			}
+
+			if branch.Product != nil {
+				prod := cur
+				prod.ProductID = branch.Product.ProductID
+				prod.Name = branch.Product.Name
+				if helper := branch.Product.ProductIdentificationHelper; helper != nil {
+					prod.CPE = helper.CPE
+					prod.PURL = helper.PURL
+				}
+				if len(prod.Product) == 0 {
+					prod.Product = prod.Name
+				}
+				products[prod.ProductID] = &prod
+			}
+			walk(branch.Branches, cur)
+		}
+	}
+	walk(doc.ProductTree.Branches, csafProduct{Vendor: doc.Document.Publisher.Name})
+
+	for _, fpn := range doc.ProductTree.FullProductNames {
+		prod := &csafProduct{
+			ProductID: fpn.ProductID,
+			Name:      fpn.Name,
+			Vendor:    doc.Document.Publisher.Name,
+			Product:   fpn.Name,
+		}
+		if helper := fpn.ProductIdentificationHelper; helper != nil {
+			prod.CPE = helper.CPE
+			prod.PURL = helper.PURL
+		}
+		products[prod.ProductID] = prod
+	}
+
+	// Relationships combine a component with a platform, e.g. a package installed on an OS release.
+	for _, rel := range doc.ProductTree.Relationships {
+		component, has := products[rel.ProductReference]
+		if !has {
+			log.Debugf("CSAF: Unknown product reference '%s' - skipping", rel.ProductReference)
+			continue
+		}
+		platform := products[rel.RelatesToProductReference]
+
+		prod := *component
+		prod.ProductID = rel.FullProductName.ProductID
+		prod.Name = rel.FullProductName.Name
+		prod.Platform = platform
+		products[prod.ProductID] = &prod
+	}
+
+	return products
+}
+
+// parseCSAF parses and resolves the CSAF document `data`.
+func parseCSAF(data []byte) (*csafAdvisory, error) {
+	var doc csafDocument
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	adv := &csafAdvisory{
+		TrackingID: doc.Document.Tracking.ID,
+		Title:      doc.Document.Title,
+		Publisher:  doc.Document.Publisher.Name,
+	}
+	for _, ref := range doc.Document.References {
+		if ref.Category == "self" {
+			adv.URL = ref.URL
+			break
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, doc.Document.Tracking.InitialReleaseDate); err == nil {
+		adv.PublishedAt = t.Unix()
+	}
+	if t, err := time.Parse(time.RFC3339, doc.Document.Tracking.CurrentReleaseDate); err == nil {
+		adv.LastModifiedAt = t.Unix()
+	}
+
+	products := resolveCSAFProducts(&doc)
+	for _, vuln := range doc.Vulnerabilities {
+		if len(vuln.CVE) == 0 {
+			continue
+		}
+		adv.CVEIDs = append(adv.CVEIDs, vuln.CVE)
+
+		remediations := map[string]*csafRemediation{}
+		for i, rem := range vuln.Remediations {
+			for _, pid := range rem.ProductIDs {
+				if _, has := remediations[pid]; !has || rem.Category == "vendor_fix" {
+					remediations[pid] = &vuln.Remediations[i]
+				}
+			}
+		}
+
+		var statuses []string
+		for status := range vuln.ProductStatus {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			for _, pid := range vuln.ProductStatus[status] {
+				prod, has := products[pid]
+				if !has {
+					log.Debugf("CSAF: Unknown product id '%s' in %s - skipping", pid, adv.TrackingID)
+					continue
+				}
+				adv.Statuses = append(adv.Statuses, csafProductStatus{
+					CVEID:       vuln.CVE,
+					Status:      status,
+					Product:     prod,
+					Remediation: remediations[pid],
+				})
+			}
+		}
+	}
+
+	return adv, nil
+}
+
+// loadCSAFDir loads all CSAF documents (*.json) recursively from the directory `csafDir`.
+func loadCSAFDir(csafDir string) ([]*csafAdvisory, error) {
+	var advisories []*csafAdvisory
+	err := filepath.Walk(csafDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.ToLower(filepath.Ext(path)) != ".json" {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		adv, err := parseCSAF(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if len(adv.CVEIDs) == 0 {
+			log.Debugf("CSAF document %s without CVEs - skipping", path)
+			return nil
+		}
+		advisories = append(advisories, adv)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return advisories, nil
+}
+
+// csafVulndbProduct resolves the vulndb vendor and product name and systype for `prod`.
+// Uses the CPE identification helper if present, otherwise prepares CPE friendly names from the product tree.
+func csafVulndbProduct(sessionw *VulnDBSession, prod *csafProduct) (vendorName, productName, systype string) {
+	if len(prod.CPE) > 0 {
+		cpeParts, err := ParseCPE(prod.CPE)
+		if err == nil && len(cpeParts.Vendor) > 0 && len(cpeParts.Product) > 0 {
+			return cpeParts.Vendor, cpeParts.Product, cpeParts.Systype
+		}
+	}
+
+	vendor, err := GetVendor(sessionw, prod.Vendor)
+	if err == nil && vendor != nil {
+		vendorName = vendor.Name
+	} else {
+		vendorName = prepVendorName(prod.Vendor)
+	}
+	return vendorName, prepProductName(prod.Product, vendorName), "a"
+}
+
+// csafVersionRanges returns the version ranges of `prod`, including exact version from branches and CPE.
+func csafVersionRanges(prod *csafProduct) []versionRange {
+	if len(prod.Ranges) > 0 {
+		return prod.Ranges
+	}
+	if len(prod.Version) > 0 {
+		return []versionRange{{Version: prod.Version}}
+	}
+	if len(prod.CPE) > 0 {
+		cpeParts, err := ParseCPE(prod.CPE)
+		if err == nil && len(cpeParts.Version) > 0 && cpeParts.Version != "*" && cpeParts.Version != "-" {
+			return []versionRange{{Version: cpeParts.Version}}
+		}
+	}
+	return nil
+}
+
+// csafVersionBounds are the exact first affected, last affected and fixed versions of a product of a CVE, pairing
+// the first and last affected versions of the product into ranges.
+type csafVersionBounds struct {
+	FirstAffected []string
+	LastAffected  []string
+	Fixed         []string // Fixed and first fixed versions.
+}
+
+// add adds the exact versions of `prod` with product status `status` to the bounds.
+func (b *csafVersionBounds) add(status string, prod *csafProduct) {
+	for _, r := range csafVersionRanges(prod) {
+		if len(r.Version) == 0 {
+			continue
+		}
+		switch status {
+		case CSAFStatusFirstAffected:
+			b.FirstAffected = append(b.FirstAffected, r.Version)
+		case CSAFStatusLastAffected:
+			b.LastAffected = append(b.LastAffected, r.Version)
+		case CSAFStatusFixed, CSAFStatusFirstFixed:
+			b.Fixed = append(b.Fixed, r.Version)
+		}
+	}
+}
+
+// affectedRange returns the version range of the exact `version` with product status `status`, first_affected or
+// last_affected. A first affected version starts the range, ended by the nearest last affected version not below
+// it or fixed version above it. A last affected version ends the range, started by the highest
+// first affected version not above it. The versions are compared by `compare` (see VersionComparator).
+func (b csafVersionBounds) affectedRange(status, version string, compare func(templateVer, targetVer string) int) versionRange {
+	if status == CSAFStatusLastAffected {
+		r := versionRange{VersionEndIncluding: version}
+		for _, first := range b.FirstAffected {
+			if cmpVal := compare(version, first); cmpVal != -1 && cmpVal != 0 {
+				continue
+			}
+			if len(r.VersionStartIncluding) == 0 || compare(r.VersionStartIncluding, first) == 1 {
+				r.VersionStartIncluding = first
+			}
+		}
+		return r
+	}
+
+	r := versionRange{VersionStartIncluding: version}
+	for _, last := range b.LastAffected {
+		if cmpVal := compare(version, last); cmpVal != 0 && cmpVal != 1 {
+			continue
+		}
+		if len(r.VersionEndIncluding) == 0 || compare(r.VersionEndIncluding, last) == -1 {
+			r.VersionEndIncluding = last
+		}
+	}
+	for _, fixed := range b.Fixed {
+		if compare(version, fixed) != 1 {
+			continue
+		}
+		if len(r.VersionEndExcluding) == 0 || compare(r.VersionEndExcluding, fixed) == -1 {
+			r.VersionEndExcluding = fixed
+		}
+	}
+	// The nearest bound ends the range.
+	if len(r.VersionEndIncluding) > 0 && len(r.VersionEndExcluding) > 0 {
+		if cmpVal := compare(r.VersionEndIncluding, r.VersionEndExcluding); cmpVal == -1 || cmpVal == 0 {
+			r.VersionEndIncluding = ""
+		} else {
+			r.VersionEndExcluding = ""
+		}
+	}
+	return r
+}
+
+// processCSAF loads CSAF 2.0 documents from `csafDir` and inserts affected product items, platform mappings
+// and product statuses into vulndb. Documents are linked to the publishing vendor.
+func processCSAF(sessionw *VulnDBSession, csafDir string) error {
+	if len(csafDir) == 0 {
+		return nil
+	}
+
+	advisories, err := loadCSAFDir(csafDir)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Loaded %d CSAF documents", len(advisories))
+
+	var platformList []platforms
+	if err := sessionw.Find(&platformList); err != nil {
+		return err
+	}
+	cpeRules, err := loadPlatformMappingRules(sessionw, SourceCPE)
+	if err != nil {
+		return err
+	}
+	productRules, err := loadPlatformMappingRules(sessionw, SourceCSAF)
+	if err != nil {
+		return err
+	}
+
+	uniquePlatformVuln := map[string]bool{}
+	linked := map[string]bool{}
+	for _, adv := range advisories {
+		publisher, err := GetVendor(sessionw, adv.Publisher)
+		if err != nil {
+			return err
+		}
+		if publisher == nil {
+			publisher, err = getOrCreateVendor(sessionw, prepVendorName(adv.Publisher))
+			if err != nil {
+				return err
+			}
+		}
+
+		csafIDs := map[string]int64{}
+		advisoryIDs := map[string]int64{}
+		for _, cveID := range adv.CVEIDs {
+			var advisory NVDCVEAdvisory
+			has, err := sessionw.Where("cve_id = ?", cveID).Get(&advisory)
+			if err != nil {
+				return err
+			}
+			if !has {
+				advisory.CVEID = cveID
+				advisory.Summary = adv.Title
+				advisory.PublishedAt = adv.PublishedAt
+				advisory.LastModifiedAt = adv.LastModifiedAt
+				if err = sessionw.Insert(&advisory); err != nil {
+					return err
+				}
+			}
+			advisoryIDs[cveID] = advisory.Id
+
+			csafAdv := csafAdvisoryItem{
+				TrackingID:     adv.TrackingID,
+				VendorID:       publisher.ID,
+				CVEID:          cveID,
+				Title:          adv.Title,
+				URL:            adv.URL,
+				PublishedAt:    adv.PublishedAt,
+				LastModifiedAt: adv.LastModifiedAt,
+			}
+			if err = sessionw.Insert(&csafAdv); err != nil {
+				return err
+			}
+			csafIDs[cveID] = csafAdv.ID
+		}
+
+		// Products with affected version information, fixed versions only used as upper bound of their train
+		// otherwise.
+		hasAffectedVersions := map[string]bool{}
+		bounds := map[string]*csafVersionBounds{}
+		for _, st := range adv.Statuses {
+			key := st.CVEID + ":" + st.Product.Vendor + ":" + st.Product.Product
+			switch st.Status {
+			case CSAFStatusKnownAffected, CSAFStatusFirstAffected, CSAFStatusLastAffected:
+				if len(csafVersionRanges(st.Product)) > 0 {
+					hasAffectedVersions[key] = true
+				}
+			}
+			if bounds[key] == nil {
+				bounds[key] = &csafVersionBounds{}
+			}
+			bounds[key].add(st.Status, st.Product)
+		}
+
+		for _, st := range adv.Statuses {
+			vendorName, productName, systype := csafVulndbProduct(sessionw, st.Product)
+			if len(vendorName) == 0 || len(productName) == 0 {
+				continue
+			}
+			vendor, err := getOrCreateVendor(sessionw, vendorName)
+			if err != nil {
+				return err
+			}
+			prod, err := getOrCreateProduct(sessionw, vendor.ID, productName)
+			if err != nil {
+				return err
+			}
+
+			status := csafProductStatusItem{
+				CSAFAdvisoryID: csafIDs[st.CVEID],
+				ProductID:      prod.ID,
+				Version:        st.Product.Version,
+				Status:         st.Status,
+			}
+			if st.Remediation != nil {
+				status.RemediationCategory = st.Remediation.Category
+				status.RemediationDetails = st.Remediation.Details
+				status.RemediationURL = st.Remediation.URL
+			}
+			if err = sessionw.Insert(&status); err != nil {
+				return err
+			}
+
+			key := st.CVEID + ":" + st.Product.Vendor + ":" + st.Product.Product
+			var ranges []versionRange
+			switch st.Status {
+			case CSAFStatusKnownAffected:
+				ranges = csafVersionRanges(st.Product)
+			case CSAFStatusFirstAffected, CSAFStatusLastAffected:
+				scheme, err := sessionw.versionScheme(vendorName, productName)
+				if err != nil {
+					return err
+				}
+				compare := func(templateVer, targetVer string) int {
+					return VersionCompareScheme(scheme, productName, templateVer, targetVer, "", "")
+				}
+				for _, r := range csafVersionRanges(st.Product) {
+					if len(r.Version) > 0 {
+						r = bounds[key].affectedRange(st.Status, r.Version, compare)
+					}
+					ranges = append(ranges, r)
+				}
+			case CSAFStatusFixed, CSAFStatusFirstFixed:
+				if hasAffectedVersions[key] {
+					continue
+				}
+				for _, r := range csafVersionRanges(st.Product) {
+					if len(r.Version) == 0 {
+						continue
+					}
+					trainRange, ok := fixedTrainRange(r.Version)
+					if !ok {
+						log.Debugf("CSAF: No train for fixed version %s of %s/%s in %s - skipping", r.Version, vendorName, productName, adv.TrackingID)
+						continue
+					}
+					ranges = append(ranges, trainRange)
+				}
+			default:
+				continue
+			}
+
+			advisoryID := advisoryIDs[st.CVEID]
+			for _, r := range ranges {
+				if !r.hasEnd() {
+					log.Debugf("CSAF: Unbounded range for %s/%s in %s - skipping", vendorName, productName, adv.TrackingID)
+					continue
+				}
+				prodItem, err := getOrCreateProductItem(sessionw, prod.ID, systype, r)
+				if err != nil {
+					return err
+				}
+				key := fmt.Sprintf("%v:%v", advisoryID, prodItem.ID)
+				if linked[key] {
+					continue
+				}
+				vuln := vulndbVulnerability{
+					ProductItemID: prodItem.ID,
+					AdvisoryID:    advisoryID,
+				}
+				if err = sessionw.Insert(&vuln); err != nil {
+					return err
+				}
+				linked[key] = true
+			}
+
+			// Platform mapping.
+			platform := st.Product.Platform
+			if platform == nil && systype == "o" {
+				platform = st.Product
+			}
+			if platform == nil {
+				continue
+			}
+			for _, candidate := range platformList {
+				platformID := candidate.ID
+				if !isPlatformMatchRulePassed(cpeRules[platformID], platform.CPE) &&
+					!isPlatformMatchRulePassed(productRules[platformID], platform.Name) &&
+					!isPlatformMatchRulePassed(productRules[platformID], platform.Product) {
+					continue
+				}
+				key := fmt.Sprintf("%v:%v", platformID, advisoryID)
+				if uniquePlatformVuln[key] {
+					continue
+				}
+				platformVuln := platformVulnerabilities{
+					PlatformID:      platformID,
+					VulnerabilityId: advisoryID,
+					Source:          SourceCSAF,
+				}
+				if err = sessionw.Insert(&platformVuln); err != nil {
+					return err
+				}
+				uniquePlatformVuln[key] = true
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersionRange(t *testing.T) {
+	testcases := []struct {
+		Range    string
+		Expected []versionRange
+	}{
+		{"vers:generic/>=2.0|<2.9.4", []versionRange{{VersionStartIncluding: "2.0", VersionEndExcluding: "2.9.4"}}},
+		{"vers:npm/1.2.3", []versionRange{{Version: "1.2.3"}}},
+		{"<= 4.2", []versionRange{{VersionEndIncluding: "4.2"}}},
+		{">1.0, <=1.5", []versionRange{{VersionStartExcluding: "1.0", VersionEndIncluding: "1.5"}}},
+		{"vers:generic/>=1.0|<1.5|>=2.0|<2.5", []versionRange{
+			{VersionStartIncluding: "1.0", VersionEndExcluding: "1.5"},
+			{VersionStartIncluding: "2.0", VersionEndExcluding: "2.5"},
+		}},
+		{"vers:generic/>=3.0", []versionRange{{VersionStartIncluding: "3.0"}}},
+	}
+
+	for _, tcase := range testcases {
+		ranges, err := parseVersionRange(tcase.Range)
+		require.NoError(t, err)
+		require.Equal(t, tcase.Expected, ranges, "Range: %s", tcase.Range)
+	}
+
+	_, err := parseVersionRange("vers:generic/*")
+	require.Error(t, err)
+}
+
+func TestLoadCSAF(t *testing.T) {
+	advisories, err := loadCSAFDir("testdata/csaf")
+	require.NoError(t, err)
+	require.Len(t, advisories, 1)
+
+	adv := advisories[0]
+	require.Equal(t, "cisco-sa-example-priv-esc", adv.TrackingID)
+	require.Equal(t, "Cisco", adv.Publisher)
+	require.Equal(t, []string{"CVE-2021-1234"}, adv.CVEIDs)
+	require.Equal(t, int64(1616601600), adv.PublishedAt)
+	require.Contains(t, adv.URL, "cisco-sa-example-priv-esc")
+
+	type statusCheck struct {
+		Status      string
+		ProductID   string
+		Product     string
+		Version     string
+		Ranges      []versionRange
+		Platform    string
+		Remediation string
+	}
+	var checks []statusCheck
+	for _, st := range adv.Statuses {
+		check := statusCheck{
+			Status:    st.Status,
+			ProductID: st.Product.ProductID,
+			Product:   st.Product.Product,
+			Version:   st.Product.Version,
+			Ranges:    st.Product.Ranges,
+		}
+		if st.Product.Platform != nil {
+			check.Platform = st.Product.Platform.CPE
+		}
+		if st.Remediation != nil {
+			check.Remediation = st.Remediation.Category
+		}
+		checks = append(checks, check)
+	}
+	require.Equal(t, []statusCheck{
+		{Status: "fixed", ProductID: "CSAFPID-0002", Product: "Cisco Example Software", Version: "3.3.0"},
+		{Status: "known_affected", ProductID: "CSAFPID-0003", Product: "Cisco Example Software",
+			Ranges: []versionRange{{VersionStartIncluding: "2.0", VersionEndExcluding: "2.9.4"}}, Remediation: "vendor_fix"},
+		{Status: "known_affected", ProductID: "CSAFPID-0001:CSAFPID-0004", Product: "Cisco Example Software", Version: "3.2.1",
+			Platform: `cpe:2.3:o:cisco:ios:15.2\(4\)e10:*:*:*:*:*:*:*`, Remediation: "vendor_fix"},
+		{Status: "known_not_affected", ProductID: "CSAFPID-0005", Product: "Cisco Other Appliance", Version: "1.0"},
+	}, checks)
+}
+
+func TestFixedTrainRange(t *testing.T) {
+	testcases := []struct {
+		Fixed    string
+		Expected versionRange
+		OK       bool
+	}{
+		{"3.3.5", versionRange{VersionStartIncluding: "3.3", VersionEndExcluding: "3.3.5"}, true},
+		{"2.5", versionRange{VersionStartIncluding: "2", VersionEndExcluding: "2.5"}, true},
+		{"15.2(4)E10", versionRange{VersionStartIncluding: "15.2", VersionEndExcluding: "15.2(4)E10"}, true},
+		{"10", versionRange{}, false},
+		{"v2", versionRange{}, false},
+	}
+	for _, tcase := range testcases {
+		r, ok := fixedTrainRange(tcase.Fixed)
+		require.Equal(t, tcase.OK, ok, tcase.Fixed)
+		require.Equal(t, tcase.Expected, r, tcase.Fixed)
+	}
+
+	// Fixed in two trains: only the earlier releases of each train are affected.
+	var items []vulndbProductItem
+	for _, fixed := range []string{"2.5.3", "3.1.2"} {
+		r, ok := fixedTrainRange(fixed)
+		require.True(t, ok)
+		start, end := r.VersionStartIncluding, r.VersionEndExcluding
+		items = append(items, vulndbProductItem{VersionStartIncluding: &start, VersionEndExcluding: &end})
+	}
+	affected := func(version string) bool {
+		for _, item := range items {
+			if matches, _ := item.matchVersion(VersionSchemeGeneric, "example", version, ""); matches {
+				return true
+			}
+		}
+		return false
+	}
+	require.True(t, affected("2.5.1"))
+	require.True(t, affected("3.1.0"))
+	require.False(t, affected("2.5.3"))
+	require.False(t, affected("2.4.9"))
+	require.False(t, affected("3.0.5"))
+	require.False(t, affected("3.1.2"))
+	require.False(t, affected("1.0"))
+}
+
+func TestCSAFAffectedRange(t *testing.T) {
+	compare := func(templateVer, targetVer string) int {
+		return VersionCompareScheme(VersionSchemeGeneric, "example", templateVer, targetVer, "", "")
+	}
+	bounds := csafVersionBounds{
+		FirstAffected: []string{"2.0", "3.0"},
+		LastAffected:  []string{"3.4"},
+		Fixed:         []string{"2.5", "3.5"},
+	}
+	testcases := []struct {
+		Status   string
+		Version  string
+		Expected versionRange
+	}{
+		// First affected, ended by the last affected version or else the fixed version of the same train.
+		{CSAFStatusFirstAffected, "2.0", versionRange{VersionStartIncluding: "2.0", VersionEndExcluding: "2.5"}},
+		{CSAFStatusFirstAffected, "3.0", versionRange{VersionStartIncluding: "3.0", VersionEndIncluding: "3.4"}},
+		{CSAFStatusFirstAffected, "4.0", versionRange{VersionStartIncluding: "4.0"}},
+		// Last affected, started by the first affected version.
+		{CSAFStatusLastAffected, "3.4", versionRange{VersionStartIncluding: "3.0", VersionEndIncluding: "3.4"}},
+		{CSAFStatusLastAffected, "1.5", versionRange{VersionEndIncluding: "1.5"}},
+	}
+	for _, tcase := range testcases {
+		require.Equal(t, tcase.Expected, bounds.affectedRange(tcase.Status, tcase.Version, compare), "%s %s", tcase.Status, tcase.Version)
+	}
+
+	// first_affected 2.0 with first_fixed 2.5 matches the versions in between.
+	r := bounds.affectedRange(CSAFStatusFirstAffected, "2.0", compare)
+	item := vulndbProductItem{VersionStartIncluding: &r.VersionStartIncluding, VersionEndExcluding: &r.VersionEndExcluding}
+	for version, expected := range map[string]bool{"1.9": false, "2.0": true, "2.3": true, "2.5": false} {
+		matches, _ := item.matchVersion(VersionSchemeGeneric, "example", version, "")
+		require.Equal(t, expected, matches, version)
+	}
+}
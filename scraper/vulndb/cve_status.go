@@ -0,0 +1,165 @@
+package vulndb
+
+import (
+	"os"
+	"strings"
+
+	"xorm.io/xorm"
+)
+
+// CVE statuses.
+const (
+	CVEStatusPublished = "PUBLISHED"
+	CVEStatusRejected  = "REJECTED" // Rejected or withdrawn by the CNA, should not be reported.
+	CVEStatusDisputed  = "DISPUTED" // Disputed by the vendor or other parties.
+)
+
+// parseCVEStatus returns the status of a CVE given its `summary` and the NVD `vulnStatus` (newer formats only).
+// Older feeds only mark rejected and disputed CVEs in the summary, e.g. "** REJECT ** DO NOT USE THIS
+// CANDIDATE NUMBER." or "** DISPUTED ** ...".
+func parseCVEStatus(summary, vulnStatus string) string {
+	if strings.EqualFold(vulnStatus, "Rejected") {
+		return CVEStatusRejected
+	}
+
+	summary = strings.TrimSpace(summary)
+	switch {
+	case strings.HasPrefix(summary, "** REJECT **"), strings.HasPrefix(summary, "** REJECTED **"):
+		return CVEStatusRejected
+	case strings.HasPrefix(summary, "** DISPUTED **"):
+		return CVEStatusDisputed
+	}
+	return CVEStatusPublished
+}
+
+// IsRejected returns true if the CVE was rejected.
+func (cve NVDCVEAdvisory) IsRejected() bool {
+	return cve.Status != nil && *cve.Status == CVEStatusRejected
+}
+
+// IsDisputed returns true if the CVE is disputed, see MatchOptions.ExcludeDisputed.
+func (cve NVDCVEAdvisory) IsDisputed() bool {
+	return cve.Status != nil && *cve.Status == CVEStatusDisputed
+}
+
+// SetIncludeRejected sets whether MatchCVEs reports rejected CVEs, clearing cached results. Rejected CVEs
+// are excluded by default.
+func (sw *VulnDBSession) SetIncludeRejected(include bool) {
+	sw.includeRejected = include
+	sw.cached = map[string][]CVEMatch{}
+}
+
+// rejectedFilterSQL returns the SQL condition on nvd_cve_advisories excluding rejected CVEs unless included by the
+// session, empty if not filtered.
+func (sw *VulnDBSession) rejectedFilterSQL() string {
+	if sw.includeRejected {
+		return ""
+	}
+	return " AND (status IS NULL OR status != '" + CVEStatusRejected + "')"
+}
+
+// previousBuild represents the CVEs of a previous build of vulndb.
+type previousBuild struct {
+	CVEIDs  []string
+	Removed []RemovedCVE
+}
+
+// loadPreviousBuild loads the CVEs from the previous vulndb at `vulndbPath`. Returns nil if there is none.
+func loadPreviousBuild(vulndbPath string) (*previousBuild, error) {
+	if len(vulndbPath) == 0 {
+		return nil, nil
+	}
+	if _, err := os.Stat(vulndbPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	orm, err := xorm.NewEngine("sqlite3", vulndbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer orm.Close()
+
+	prev := &previousBuild{}
+	err = orm.Table("nvd_cve_advisories").Cols("cve_id").Find(&prev.CVEIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Builds before removed CVEs were recorded do not have the table.
+	has, err := orm.IsTableExist("removed_cves")
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		err = orm.Find(&prev.Removed)
+		if err != nil {
+			return nil, err
+		}
+	}
+	log.Debugf("Loaded %d CVEs (%d removed) of previous build %s", len(prev.CVEIDs), len(prev.Removed), vulndbPath)
+
+	return prev, nil
+}
+
+// processRemovedCVEs records the CVEs of the `previous` build that are no longer present as removed at
+// `buildTime`, and carries over the CVEs removed in earlier builds unless they have reappeared.
+func processRemovedCVEs(sessionw *VulnDBSession, previous *previousBuild, buildTime int64) error {
+	if previous == nil {
+		return nil
+	}
+
+	session, err := sessionw.Raw()
+	if err != nil {
+		return err
+	}
+	var cveIDs []string
+	err = session.Table("nvd_cve_advisories").Cols("cve_id").Find(&cveIDs)
+	if err != nil {
+		return err
+	}
+	current := map[string]bool{}
+	for _, cveID := range cveIDs {
+		current[cveID] = true
+	}
+
+	recorded := map[string]bool{}
+	for _, removed := range previous.Removed {
+		if current[removed.CVEID] || recorded[removed.CVEID] {
+			continue
+		}
+		recorded[removed.CVEID] = true
+		item := removed
+		err = sessionw.Insert(&item)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, cveID := range previous.CVEIDs {
+		if current[cveID] || recorded[cveID] {
+			continue
+		}
+		recorded[cveID] = true
+		item := RemovedCVE{
+			CVEID:     cveID,
+			RemovedAt: buildTime,
+		}
+		err = sessionw.Insert(&item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListRemovedCVEs returns the CVEs removed from vulndb at or after `since` (unix time), e.g. for closing
+// findings of CVEs that no longer exist.
+func ListRemovedCVEs(session *VulnDBSession, since int64) ([]RemovedCVE, error) {
+	var removed []RemovedCVE
+	err := session.Where("removed_at >= ?", since).OrderBy("removed_at, cve_id").Find(&removed)
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
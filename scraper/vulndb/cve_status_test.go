@@ -0,0 +1,27 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCVEStatus(t *testing.T) {
+	require.Equal(t, CVEStatusRejected, parseCVEStatus("** REJECT ** DO NOT USE THIS CANDIDATE NUMBER.", ""))
+	require.Equal(t, CVEStatusRejected, parseCVEStatus("Rejected reason: duplicate of CVE-2020-1234.", "Rejected"))
+	require.Equal(t, CVEStatusDisputed, parseCVEStatus(" ** DISPUTED ** An issue was discovered.", ""))
+	require.Equal(t, CVEStatusPublished, parseCVEStatus("An issue was discovered.", "Analyzed"))
+	require.Equal(t, CVEStatusPublished, parseCVEStatus("", ""))
+}
+
+func TestCVEStatus(t *testing.T) {
+	statuses := []string{CVEStatusPublished, CVEStatusRejected, CVEStatusDisputed}
+	advisories := []NVDCVEAdvisory{{}, {Status: &statuses[0]}, {Status: &statuses[1]}, {Status: &statuses[2]}}
+	var rejected, disputed []bool
+	for _, advisory := range advisories {
+		rejected = append(rejected, advisory.IsRejected())
+		disputed = append(disputed, advisory.IsDisputed())
+	}
+	require.Equal(t, []bool{false, false, true, false}, rejected)
+	require.Equal(t, []bool{false, false, false, true}, disputed)
+}
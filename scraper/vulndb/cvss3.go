@@ -0,0 +1,36 @@
+package vulndb
+
+import (
+	"nanscraper/cvss"
+)
+
+// CVSS3Vector returns the parsed CVSS3 vector of the advisory, nil if it has none.
+// Also accepts the score prefixed vectors from Red Hat OVAL, e.g. 7.5/CVSS:3.0/AV:N/...
+func (cve NVDCVEAdvisory) CVSS3Vector() (*cvss.CVSS3Vector, error) {
+	if cve.CVSS3VectorString == nil || len(*cve.CVSS3VectorString) == 0 {
+		return nil, nil
+	}
+	vector := *cve.CVSS3VectorString
+	if _, v, ok := parseScoredCVSS3(vector); ok {
+		vector = v
+	}
+	return cvss.ParseCVSS3(vector)
+}
+
+// ScoreMatches returns `matches` with the CVSS3 environmental score set for an asset with the security
+// requirements `req`. Matches without a (valid) CVSS3 vector are left without an environmental score.
+func ScoreMatches(matches []CVEMatch, req cvss.Requirements) []CVEMatch {
+	scored := make([]CVEMatch, 0, len(matches))
+	for _, match := range matches {
+		match.EnvironmentalScore = nil
+		v, err := match.Advisory.CVSS3Vector()
+		if err != nil {
+			log.Debugf("%s: Invalid CVSS3 vector: %v", match.Advisory.CVEID, err)
+		} else if v != nil {
+			score := v.WithRequirements(req).EnvironmentalScore()
+			match.EnvironmentalScore = &score
+		}
+		scored = append(scored, match)
+	}
+	return scored
+}
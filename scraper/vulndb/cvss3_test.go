@@ -0,0 +1,32 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"nanscraper/cvss"
+)
+
+func TestScoreMatches(t *testing.T) {
+	nvdVector := "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"
+	ovalVector := "7.5/CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"
+	invalidVector := "CVSS:3.1/AV:N"
+	matches := []CVEMatch{
+		{Advisory: NVDCVEAdvisory{CVEID: "CVE-2020-0001", CVSS3VectorString: &nvdVector}},
+		{Advisory: NVDCVEAdvisory{CVEID: "CVE-2020-0002", CVSS3VectorString: &ovalVector}},
+		{Advisory: NVDCVEAdvisory{CVEID: "CVE-2020-0003"}},
+		{Advisory: NVDCVEAdvisory{CVEID: "CVE-2020-0004", CVSS3VectorString: &invalidVector}},
+	}
+
+	scored := ScoreMatches(matches, cvss.Requirements{Confidentiality: "H"})
+	require.Len(t, scored, 4)
+	require.Equal(t, 9.3, *scored[0].EnvironmentalScore)
+	require.Equal(t, 9.3, *scored[1].EnvironmentalScore)
+	require.Nil(t, scored[2].EnvironmentalScore)
+	require.Nil(t, scored[3].EnvironmentalScore)
+	require.Nil(t, matches[0].EnvironmentalScore)
+
+	scored = ScoreMatches(matches, cvss.Requirements{Confidentiality: "L"})
+	require.Equal(t, 5.7, *scored[0].EnvironmentalScore)
+}
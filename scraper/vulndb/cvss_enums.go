@@ -0,0 +1,269 @@
+package vulndb
+
+import (
+	"strings"
+
+	"nanscraper/vulndb/nvdjson"
+)
+
+// cvssEnum maps a CVSS metric value of the NVD JSON feed to the vulndb value and vector string abbreviation.
+type cvssEnum struct {
+	Name   string // NVD JSON value, e.g. NETWORK.
+	Value  int    // vulndb value, e.g. CVSSAccessVectorNetwork.
+	Abbrev string // Vector string abbreviation, e.g. N.
+}
+
+// cvssMetric defines the mapping of a CVSS metric. Where several NVD JSON values map to the same vulndb value,
+// the first one is the canonical value used when mapping back.
+type cvssMetric struct {
+	Label  string // Used in log messages, e.g. CVSS2 access vector.
+	Abbrev string // Vector string abbreviation, e.g. AV.
+	Values []cvssEnum
+}
+
+// value returns the vulndb value of the NVD JSON value `name`, or nil if not supported.
+func (m cvssMetric) value(name string) *int {
+	for _, e := range m.Values {
+		if e.Name == name {
+			val := e.Value
+			return &val
+		}
+	}
+	log.Debugf("ERROR: Unsupported %s: '%s' - ignoring", m.Label, name)
+	return nil
+}
+
+// lookup returns the canonical enum of the vulndb `value`. Returns false if `value` is nil or unknown.
+func (m cvssMetric) lookup(value *int) (cvssEnum, bool) {
+	if value == nil {
+		return cvssEnum{}, false
+	}
+	for _, e := range m.Values {
+		if e.Value == *value {
+			return e, true
+		}
+	}
+	return cvssEnum{}, false
+}
+
+// valueOf returns the vulndb value of the vector string abbreviation `abbrev`, or nil if unknown.
+func (m cvssMetric) valueOf(abbrev string) *int {
+	for _, e := range m.Values {
+		if e.Abbrev == abbrev {
+			val := e.Value
+			return &val
+		}
+	}
+	return nil
+}
+
+// name returns the NVD JSON value of the vulndb `value`, empty if unknown.
+func (m cvssMetric) name(value *int) string {
+	e, _ := m.lookup(value)
+	return e.Name
+}
+
+// CVSS2 metrics.
+var (
+	cvss2AccessVector = cvssMetric{"CVSS2 access vector", "AV", []cvssEnum{
+		{"LOCAL", CVSSAccessVectorLocal, "L"},
+		{"ADJACENT_NETWORK", CVSSAccessVectorAdjacentNetwork, "A"},
+		{"NETWORK", CVSSAccessVectorNetwork, "N"},
+	}}
+	cvss2AccessComplexity = cvssMetric{"CVSS2 access complexity", "AC", []cvssEnum{
+		{"HIGH", CVSSAccessComplexityHigh, "H"},
+		{"MEDIUM", CVSSAccessComplexityMedium, "M"},
+		{"LOW", CVSSAccessComplexityLow, "L"},
+	}}
+	cvss2Authentication = cvssMetric{"CVSS2 authentication", "Au", []cvssEnum{
+		{"MULTIPLE", CVSSAuthenticationMultipleInstances, "M"},
+		{"MULTIPLE_INSTANCES", CVSSAuthenticationMultipleInstances, "M"},
+		{"SINGLE", CVSSAuthenticationSingleInstance, "S"},
+		{"SINGLE_INSTANCE", CVSSAuthenticationSingleInstance, "S"},
+		{"NONE", CVSSAuthenticationNone, "N"},
+	}}
+	cvss2ConfidentialityImpact = cvssMetric{"CVSS2 confidentiality impact", "C", []cvssEnum{
+		{"NONE", CVSSConfidentialityImpactNone, "N"},
+		{"PARTIAL", CVSSConfidentialityImpactPartial, "P"},
+		{"COMPLETE", CVSSConfidentialityImpactComplete, "C"},
+	}}
+	cvss2IntegrityImpact = cvssMetric{"CVSS2 integrity impact", "I", []cvssEnum{
+		{"NONE", CVSSIntegrityImpactNone, "N"},
+		{"PARTIAL", CVSSIntegrityImpactPartial, "P"},
+		{"COMPLETE", CVSSIntegrityImpactComplete, "C"},
+	}}
+	cvss2AvailabilityImpact = cvssMetric{"CVSS2 availability impact", "A", []cvssEnum{
+		{"NONE", CVSSAvailabilityImpactNone, "N"},
+		{"PARTIAL", CVSSAvailabilityImpactPartial, "P"},
+		{"COMPLETE", CVSSAvailabilityImpactComplete, "C"},
+	}}
+	cvss2Severity = cvssMetric{"CVSS2 severity", "", []cvssEnum{
+		{"LOW", SeverityTypeLow, ""},
+		{"MEDIUM", SeverityTypeMedium, ""},
+		{"HIGH", SeverityTypeHigh, ""},
+	}}
+)
+
+// cvss2VectorMetrics lists the CVSS2 base metrics in vector string order.
+var cvss2VectorMetrics = []cvssMetric{
+	cvss2AccessVector,
+	cvss2AccessComplexity,
+	cvss2Authentication,
+	cvss2ConfidentialityImpact,
+	cvss2IntegrityImpact,
+	cvss2AvailabilityImpact,
+}
+
+// CVSS3 metrics.
+var (
+	cvss3AttackVector = cvssMetric{"CVSS3 attack vector", "AV", []cvssEnum{
+		{"NETWORK", AttackVectorTypeNetwork, "N"},
+		{"ADJACENT_NETWORK", AttackVectorTypeAdjacentNetwork, "A"},
+		{"LOCAL", AttackVectorTypeLocal, "L"},
+		{"PHYSICAL", AttackVectorTypePhysical, "P"},
+	}}
+	cvss3AttackComplexity = cvssMetric{"CVSS3 attack complexity", "AC", []cvssEnum{
+		{"HIGH", AttackComplexityTypeHigh, "H"},
+		{"LOW", AttackComplexityTypeLow, "L"},
+	}}
+	cvss3PrivilegesRequired = cvssMetric{"CVSS3 privileges required", "PR", []cvssEnum{
+		{"HIGH", PrivilegesRequiredTypeHigh, "H"},
+		{"LOW", PrivilegesRequiredTypeLow, "L"},
+		{"NONE", PrivilegesRequiredTypeNone, "N"},
+	}}
+	cvss3UserInteraction = cvssMetric{"CVSS3 user interaction", "UI", []cvssEnum{
+		{"NONE", UserInteractionTypeNone, "N"},
+		{"REQUIRED", UserInteractionTypeRequired, "R"},
+	}}
+	cvss3Scope = cvssMetric{"CVSS3 scope", "S", []cvssEnum{
+		{"UNCHANGED", ScopeTypeUnchanged, "U"},
+		{"CHANGED", ScopeTypeChanged, "C"},
+	}}
+	cvss3ConfidentialityImpact = cvssMetric{"CVSS3 confidentiality impact", "C", cvss3CiaValues}
+	cvss3IntegrityImpact       = cvssMetric{"CVSS3 integrity impact", "I", cvss3CiaValues}
+	cvss3AvailabilityImpact    = cvssMetric{"CVSS3 availability impact", "A", cvss3CiaValues}
+	cvss3BaseSeverity          = cvssMetric{"CVSS3 base severity", "", []cvssEnum{
+		{"NONE", SeverityTypeNone, ""},
+		{"LOW", SeverityTypeLow, ""},
+		{"MEDIUM", SeverityTypeMedium, ""},
+		{"HIGH", SeverityTypeHigh, ""},
+		{"CRITICAL", SeverityTypeCritical, ""},
+	}}
+	cvss3CiaValues = []cvssEnum{
+		{"HIGH", CiaTypeHigh, "H"},
+		{"LOW", CiaTypeLow, "L"},
+		{"NONE", CiaTypeNone, "N"},
+	}
+)
+
+// boolToInt returns `b` as stored in vulndb (0 or 1).
+func boolToInt(b bool) *int {
+	val := 0
+	if b {
+		val = 1
+	}
+	return &val
+}
+
+// newCVECVSS2 maps the NVD JSON CVSS2 base metric `m` to vulndb values.
+func newCVECVSS2(m nvdjson.BaseMetricV2) *CVECVSS2 {
+	exploitabilityScore := m.ExploitabilityScore
+	impactScore := m.ImpactScore
+	cvss2 := &CVECVSS2{
+		BaseScore:               m.CVSSV2.BaseScore,
+		AccessVector:            cvss2AccessVector.value(m.CVSSV2.AccessVector),
+		AccessComplexity:        cvss2AccessComplexity.value(m.CVSSV2.AccessComplexity),
+		Authentication:          cvss2Authentication.value(m.CVSSV2.Authentication),
+		ConfidentialityImpact:   cvss2ConfidentialityImpact.value(m.CVSSV2.ConfidentialityImpact),
+		IntegrityImpact:         cvss2IntegrityImpact.value(m.CVSSV2.IntegrityImpact),
+		AvailabilityImpact:      cvss2AvailabilityImpact.value(m.CVSSV2.AvailabilityImpact),
+		VectorString:            m.CVSSV2.VectorString,
+		ExploitabilityScore:     &exploitabilityScore,
+		ImpactScore:             &impactScore,
+		ObtainAllPrivilege:      boolToInt(m.ObtainAllPrivilege),
+		ObtainUserPrivilege:     boolToInt(m.ObtainUserPrivilege),
+		ObtainOtherPrivilege:    boolToInt(m.ObtainOtherPrivilege),
+		UserInteractionRequired: boolToInt(m.UserInteractionRequired),
+	}
+	if severity := cvss2Severity.value(m.Severity); severity != nil {
+		cvss2.Severity = *severity
+	}
+	return cvss2
+}
+
+// newCVECVSS3 maps the NVD JSON CVSS3 metrics `m` to vulndb values.
+func newCVECVSS3(m nvdjson.CVSSV3) *CVECVSS3 {
+	cvss3 := &CVECVSS3{
+		AttackComplexity:      cvss3AttackComplexity.value(m.AttackComplexity),
+		AttackVector:          cvss3AttackVector.value(m.AttackVector),
+		AvailabilityImpact:    cvss3AvailabilityImpact.value(m.AvailabilityImpact),
+		BaseScore:             m.BaseScore,
+		ConfidentialityImpact: cvss3ConfidentialityImpact.value(m.ConfidentialityImpact),
+		IntegrityImpact:       cvss3IntegrityImpact.value(m.IntegrityImpact),
+		PrivilegesRequired:    cvss3PrivilegesRequired.value(m.PrivilegesRequired),
+		Scope:                 cvss3Scope.value(m.Scope),
+		UserInteraction:       cvss3UserInteraction.value(m.UserInteraction),
+		VectorString:          m.VectorString,
+	}
+	if severity := cvss3BaseSeverity.value(m.BaseSeverity); severity != nil {
+		cvss3.BaseSeverity = *severity
+	}
+	return cvss3
+}
+
+// ParseSeverity returns the severity (SeverityType*) of the CVSS3 severity name `name`, e.g. high or CRITICAL.
+// Returns false if unknown.
+func ParseSeverity(name string) (int, bool) {
+	severity := cvss3BaseSeverity.value(strings.ToUpper(strings.TrimSpace(name)))
+	if severity == nil {
+		return 0, false
+	}
+	return *severity, true
+}
+
+// CVSS2VectorFromMetrics rebuilds the CVSS2 vector string of the advisory from its stored metrics, e.g.
+// AV:N/AC:L/Au:N/C:P/I:P/A:P. Returns false if any of the base metrics is unknown.
+func (cve NVDCVEAdvisory) CVSS2VectorFromMetrics() (string, bool) {
+	values := []*int{
+		cve.CVSS2AccessVector,
+		cve.CVSS2AccessComplexity,
+		cve.CVSS2Authentication,
+		cve.CVSS2ConfidentialityImpact,
+		cve.CVSS2IntegrityImpact,
+		cve.CVSS2AvailabilityImpact,
+	}
+	parts := make([]string, 0, len(values))
+	for i, metric := range cvss2VectorMetrics {
+		e, ok := metric.lookup(values[i])
+		if !ok {
+			return "", false
+		}
+		parts = append(parts, metric.Abbrev+":"+e.Abbrev)
+	}
+	return strings.Join(parts, "/"), true
+}
+
+// CVSS2 returns the CVSS2 metrics of the advisory as in the NVD JSON feed, nil if it has no CVSS2 score.
+// The vector string is rebuilt from the metrics when not stored.
+func (cve NVDCVEAdvisory) CVSS2() *nvdjson.CVSSV2 {
+	if cve.CVSS2BaseScore == nil {
+		return nil
+	}
+
+	m := &nvdjson.CVSSV2{
+		Version:               "2.0",
+		AccessVector:          cvss2AccessVector.name(cve.CVSS2AccessVector),
+		AccessComplexity:      cvss2AccessComplexity.name(cve.CVSS2AccessComplexity),
+		Authentication:        cvss2Authentication.name(cve.CVSS2Authentication),
+		ConfidentialityImpact: cvss2ConfidentialityImpact.name(cve.CVSS2ConfidentialityImpact),
+		IntegrityImpact:       cvss2IntegrityImpact.name(cve.CVSS2IntegrityImpact),
+		AvailabilityImpact:    cvss2AvailabilityImpact.name(cve.CVSS2AvailabilityImpact),
+		BaseScore:             *cve.CVSS2BaseScore,
+	}
+	if cve.CVSS2VectorString != nil && len(*cve.CVSS2VectorString) > 0 {
+		m.VectorString = *cve.CVSS2VectorString
+	} else if vector, ok := cve.CVSS2VectorFromMetrics(); ok {
+		m.VectorString = vector
+	}
+	return m
+}
@@ -0,0 +1,104 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"nanscraper/vulndb/nvdjson"
+)
+
+func TestCVSS2RoundTrip(t *testing.T) {
+	testcases := []nvdjson.CVSSV2{
+		{
+			Version:               "2.0",
+			VectorString:          "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+			AccessVector:          "NETWORK",
+			AccessComplexity:      "LOW",
+			Authentication:        "NONE",
+			ConfidentialityImpact: "PARTIAL",
+			IntegrityImpact:       "PARTIAL",
+			AvailabilityImpact:    "PARTIAL",
+			BaseScore:             7.5,
+		},
+		{
+			Version:               "2.0",
+			VectorString:          "AV:L/AC:M/Au:S/C:C/I:N/A:C",
+			AccessVector:          "LOCAL",
+			AccessComplexity:      "MEDIUM",
+			Authentication:        "SINGLE",
+			ConfidentialityImpact: "COMPLETE",
+			IntegrityImpact:       "NONE",
+			AvailabilityImpact:    "COMPLETE",
+			BaseScore:             5.7,
+		},
+		{
+			Version:               "2.0",
+			VectorString:          "AV:A/AC:H/Au:M/C:N/I:C/A:N",
+			AccessVector:          "ADJACENT_NETWORK",
+			AccessComplexity:      "HIGH",
+			Authentication:        "MULTIPLE",
+			ConfidentialityImpact: "NONE",
+			IntegrityImpact:       "COMPLETE",
+			AvailabilityImpact:    "NONE",
+			BaseScore:             3.8,
+		},
+	}
+
+	for _, tcase := range testcases {
+		cvss2 := newCVECVSS2(nvdjson.BaseMetricV2{CVSSV2: tcase, Severity: "HIGH"})
+		require.Equal(t, SeverityTypeHigh, cvss2.Severity)
+
+		advisory := NVDCVEAdvisory{
+			CVSS2BaseScore:             &cvss2.BaseScore,
+			CVSS2AccessVector:          cvss2.AccessVector,
+			CVSS2AccessComplexity:      cvss2.AccessComplexity,
+			CVSS2Authentication:        cvss2.Authentication,
+			CVSS2ConfidentialityImpact: cvss2.ConfidentialityImpact,
+			CVSS2IntegrityImpact:       cvss2.IntegrityImpact,
+			CVSS2AvailabilityImpact:    cvss2.AvailabilityImpact,
+		}
+		vector, ok := advisory.CVSS2VectorFromMetrics()
+		require.True(t, ok)
+		require.Equal(t, tcase.VectorString, vector)
+		require.Equal(t, &tcase, advisory.CVSS2())
+	}
+
+	// Alternative NVD names map to the same value, mapped back to the canonical name.
+	cvss2 := newCVECVSS2(nvdjson.BaseMetricV2{CVSSV2: nvdjson.CVSSV2{Authentication: "SINGLE_INSTANCE"}})
+	require.Equal(t, CVSSAuthenticationSingleInstance, *cvss2.Authentication)
+	require.Equal(t, "SINGLE", cvss2Authentication.name(cvss2.Authentication))
+
+	// Incomplete metrics cannot be rebuilt.
+	advisory := NVDCVEAdvisory{CVSS2AccessVector: cvss2.AccessVector}
+	_, ok := advisory.CVSS2VectorFromMetrics()
+	require.False(t, ok)
+	require.Nil(t, advisory.CVSS2())
+}
+
+func TestCVSSMetricsReversible(t *testing.T) {
+	metrics := append([]cvssMetric{cvss2Severity, cvss3AttackVector, cvss3AttackComplexity,
+		cvss3PrivilegesRequired, cvss3UserInteraction, cvss3Scope, cvss3ConfidentialityImpact,
+		cvss3IntegrityImpact, cvss3AvailabilityImpact, cvss3BaseSeverity}, cvss2VectorMetrics...)
+	for _, metric := range metrics {
+		for _, e := range metric.Values {
+			value := metric.value(e.Name)
+			require.NotNil(t, value, "%s: %s", metric.Label, e.Name)
+			canonical, ok := metric.lookup(value)
+			require.True(t, ok, "%s: %s", metric.Label, e.Name)
+			require.Equal(t, e.Abbrev, canonical.Abbrev, "%s: %s", metric.Label, e.Name)
+			require.Equal(t, *value, *metric.value(canonical.Name), "%s: %s", metric.Label, e.Name)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, ok := ParseSeverity("high")
+	require.True(t, ok)
+	require.Equal(t, SeverityTypeHigh, severity)
+	severity, ok = ParseSeverity(" CRITICAL ")
+	require.True(t, ok)
+	require.Equal(t, SeverityTypeCritical, severity)
+	_, ok = ParseSeverity("severe")
+	require.False(t, ok)
+}
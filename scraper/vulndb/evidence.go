@@ -0,0 +1,153 @@
+package vulndb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Product resolution steps of MatchCVEs.
+const (
+	ResolvedByProductAlias = "product_alias" // Vendor/product alias (vulndb_product_aliases).
+	ResolvedByVendor       = "vendor"        // Vendor by name and product by candidate name.
+	ResolvedByVendorAlias  = "vendor_alias"  // Vendor by alias (vulndb_vendor_aliases) and product by candidate name.
+	ResolvedByCPEFriendly  = "cpe_friendly"  // Vendor by cpe-friendly name and product by candidate name.
+	ResolvedByFuzzy        = "fuzzy"         // Product by fuzzy resolution (see SetFuzzyThreshold).
+)
+
+// Version bounds of product items compared by MatchCVEs.
+const (
+	BoundVersion        = "version"
+	BoundStartIncluding = "start_including"
+	BoundStartExcluding = "start_excluding"
+	BoundEndIncluding   = "end_including"
+	BoundEndExcluding   = "end_excluding"
+)
+
+// VersionComparison is a comparison of the target version against a version bound of a product item.
+type VersionComparison struct {
+	Bound    string // Bound* constant.
+	Template string // Version of the bound.
+	Result   int    // Comparator result: -1 if the target is lower, 0 if equal, 1 if higher, 2 if incompatible.
+}
+
+// MatchedProductItem is the vulndb_product_items row that matched the target version.
+type MatchedProductItem struct {
+	ID                    int64
+	ProductID             int64
+	Systype               string
+	Version               *string
+	VersionStartExcluding *string
+	VersionStartIncluding *string
+	VersionEndExcluding   *string
+	VersionEndIncluding   *string
+	Patch                 string
+	SWTarget              *string
+	CPE23                 *string // NVD CPE name of the advisory the product item is vulnerable by, nil if not from NVD.
+}
+
+// MatchEvidence explains why MatchCVEs matched an advisory, e.g. for disputing false positives.
+type MatchEvidence struct {
+	ResolvedBy     string   // How the product was resolved (ResolvedBy* constant).
+	VendorName     string   // Matched vendor.
+	ProductName    string   // Matched product.
+	CandidateNames []string // Product names tried under the vendor: the title and alternativeNames, nil for aliases.
+	Confidence     float64  // Confidence of fuzzy resolution, 0 if resolved exactly.
+	ProductItem    MatchedProductItem
+	Comparator     string              // Version scheme of the comparator, e.g. cisco.
+	Comparisons    []VersionComparison // Comparisons of the target version with the product item bounds.
+}
+
+// productResolution is how a product was resolved from the publisher and title.
+type productResolution struct {
+	ResolvedBy     string
+	CandidateNames []string
+	Confidence     float64
+}
+
+// resolvedBy returns the resolution step (ResolvedBy* constant) of the vendor match.
+func (m VendorMatchResult) resolvedBy() string {
+	switch {
+	case m.FromAlias:
+		return ResolvedByVendorAlias
+	case m.CPEFriendlyMatch:
+		return ResolvedByCPEFriendly
+	}
+	return ResolvedByVendor
+}
+
+// newMatchEvidence returns the evidence of `item` of product `productName` by `vendorName` matching with
+// `comparisons` of version scheme `scheme`, vulnerable by `vuln`.
+func newMatchEvidence(resolution productResolution, vendorName, productName, scheme string, item vulndbProductItem, vuln vulndbVulnerability, comparisons []VersionComparison) MatchEvidence {
+	return MatchEvidence{
+		ResolvedBy:     resolution.ResolvedBy,
+		VendorName:     vendorName,
+		ProductName:    productName,
+		CandidateNames: resolution.CandidateNames,
+		Confidence:     resolution.Confidence,
+		ProductItem: MatchedProductItem{
+			ID:                    item.ID,
+			ProductID:             item.ProductID,
+			Systype:               item.Systype,
+			Version:               item.Version,
+			VersionStartExcluding: item.VersionStartExcluding,
+			VersionStartIncluding: item.VersionStartIncluding,
+			VersionEndExcluding:   item.VersionEndExcluding,
+			VersionEndIncluding:   item.VersionEndIncluding,
+			Patch:                 item.Patch,
+			SWTarget:              item.SWTarget,
+			CPE23:                 vuln.CPE23,
+		},
+		Comparator:  scheme,
+		Comparisons: comparisons,
+	}
+}
+
+// Explain returns a human readable explanation of why the advisory matched, one line per matched product item.
+func (m CVEMatch) Explain() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s matched", m.Advisory.CVEID)
+	if len(m.Evidence) == 0 {
+		sb.WriteString(" (no evidence)")
+	}
+	for _, e := range m.Evidence {
+		fmt.Fprintf(&sb, "\n  %s/%s resolved by %s", e.VendorName, e.ProductName, e.ResolvedBy)
+		if e.ResolvedBy == ResolvedByFuzzy {
+			fmt.Fprintf(&sb, " (%s, confidence %.2f)", strings.Join(e.CandidateNames, ", "), e.Confidence)
+		} else if len(e.CandidateNames) > 0 {
+			fmt.Fprintf(&sb, " (candidates: %s)", strings.Join(e.CandidateNames, ", "))
+		}
+		fmt.Fprintf(&sb, ", product item %d [%s]", e.ProductItem.ID, e.ProductItem.versionRange())
+		if e.ProductItem.SWTarget != nil && len(*e.ProductItem.SWTarget) > 0 {
+			fmt.Fprintf(&sb, " sw_target=%s", *e.ProductItem.SWTarget)
+		}
+		fmt.Fprintf(&sb, ", %s:", e.Comparator)
+		for _, c := range e.Comparisons {
+			fmt.Fprintf(&sb, " %s %s => %d", c.Bound, c.Template, c.Result)
+		}
+	}
+	return sb.String()
+}
+
+// versionRange returns the version or version range of the product item, e.g. ">= 1.0, < 2.0".
+func (item MatchedProductItem) versionRange() string {
+	if item.Version != nil && len(*item.Version) > 0 && *item.Version != "*" {
+		version := "= " + *item.Version
+		if len(item.Patch) > 0 {
+			version += " " + item.Patch
+		}
+		return version
+	}
+
+	var bounds []string
+	if item.VersionStartIncluding != nil {
+		bounds = append(bounds, ">= "+*item.VersionStartIncluding)
+	} else if item.VersionStartExcluding != nil {
+		bounds = append(bounds, "> "+*item.VersionStartExcluding)
+	}
+	if item.VersionEndIncluding != nil {
+		bounds = append(bounds, "<= "+*item.VersionEndIncluding)
+	} else if item.VersionEndExcluding != nil {
+		bounds = append(bounds, "< "+*item.VersionEndExcluding)
+	}
+	return strings.Join(bounds, ", ")
+}
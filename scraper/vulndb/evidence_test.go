@@ -0,0 +1,32 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchVersionEvidence(t *testing.T) {
+	start := "9.1"
+	end := "9.1(7)"
+	item := vulndbProductItem{ID: 12, Systype: "a", VersionStartIncluding: &start, VersionEndExcluding: &end}
+
+	matches, comparisons := item.matchVersion(VersionSchemeCiscoASA, "adaptive_security_appliance_software", "9.1(5)", "")
+	require.True(t, matches)
+	require.Equal(t, []VersionComparison{
+		{Bound: BoundStartIncluding, Template: "9.1", Result: 1},
+		{Bound: BoundEndExcluding, Template: "9.1(7)", Result: -1},
+	}, comparisons)
+
+	resolution := productResolution{ResolvedBy: ResolvedByVendorAlias, CandidateNames: []string{"ASA", "asa"}}
+	cpe23 := "cpe:2.3:a:cisco:adaptive_security_appliance_software:*:*:*:*:*:*:*:*"
+	vuln := vulndbVulnerability{AdvisoryID: 3, ProductItemID: 12, CPE23: &cpe23}
+	evidence := newMatchEvidence(resolution, "cisco", "adaptive_security_appliance_software", VersionSchemeCiscoASA, item, vuln, comparisons)
+	require.Equal(t, "cisco-asa", evidence.Comparator)
+	require.Equal(t, int64(12), evidence.ProductItem.ID)
+	require.Equal(t, &cpe23, evidence.ProductItem.CPE23)
+
+	match := CVEMatch{Advisory: NVDCVEAdvisory{CVEID: "CVE-2018-0101"}, Evidence: []MatchEvidence{evidence}}
+	require.Equal(t, `CVE-2018-0101 matched
+  cisco/adaptive_security_appliance_software resolved by vendor_alias (candidates: ASA, asa), product item 12 [>= 9.1, < 9.1(7)], cisco-asa: start_including 9.1 => 1 end_excluding 9.1(7) => -1`, match.Explain())
+}
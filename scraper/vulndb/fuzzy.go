@@ -0,0 +1,227 @@
+package vulndb
+
+import (
+	"sort"
+	"strings"
+)
+
+// Number of candidates returned by ResolveProductFuzzy.
+const maxFuzzyCandidates = 5
+
+// ProductCandidate is a candidate product of fuzzy product resolution, see ResolveProductFuzzy.
+type ProductCandidate struct {
+	ProductID   int64
+	VendorName  string
+	ProductName string
+	MatchedName string  // Product name or CPE dictionary title most similar to the title.
+	Confidence  float64 // Similarity of the title and MatchedName, from 0 (none) to 1 (same).
+}
+
+// FuzzyResolution is a software title whose fuzzy product candidates were below the acceptance threshold,
+// reported for alias curation (see FuzzyReport).
+type FuzzyResolution struct {
+	Publisher  string
+	Title      string
+	Candidates []ProductCandidate
+}
+
+// SetFuzzyThreshold enables fuzzy product resolution in MatchCVEs for titles without an exact product match,
+// accepting the best candidate with a confidence of at least `threshold`, clearing cached results. A `threshold`
+// of 0 disables fuzzy resolution (default).
+func (sw *VulnDBSession) SetFuzzyThreshold(threshold float64) {
+	sw.fuzzyThreshold = threshold
+	sw.cached = map[string][]CVEMatch{}
+}
+
+// FuzzyReport returns the titles that MatchCVEs could only resolve to low confidence candidates, ordered by
+// publisher and title.
+func (sw *VulnDBSession) FuzzyReport() []FuzzyResolution {
+	var report []FuzzyResolution
+	for _, resolution := range sw.fuzzyReport {
+		report = append(report, resolution)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Publisher != report[j].Publisher {
+			return report[i].Publisher < report[j].Publisher
+		}
+		return report[i].Title < report[j].Title
+	})
+	return report
+}
+
+// ResolveProductFuzzy returns the products of the vendor of `publisher` most similar to `title`, ranked by
+// confidence. The title is compared to the product names and the CPE dictionary titles of the products, using
+// token and edit distance similarity. Returns nil if the vendor is unknown.
+func ResolveProductFuzzy(session *VulnDBSession, publisher, title string) ([]ProductCandidate, error) {
+	vendor, err := GetVendor(session, publisher)
+	if err != nil || vendor == nil {
+		return nil, err
+	}
+	return fuzzyProductCandidates(session, vendor, title)
+}
+
+// fuzzyProductCandidates returns up to `maxFuzzyCandidates` products of `vendor` most similar to `title`.
+func fuzzyProductCandidates(session *VulnDBSession, vendor *VulndbVendor, title string) ([]ProductCandidate, error) {
+	var products []vulndbProduct
+	err := session.Where("vendor_id = ?", vendor.ID).Find(&products)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, nil
+	}
+
+	// The CPE dictionary titles are only present in vulndbs built with the dictionary.
+	var titles []cpeDictionaryTitle
+	hasTitles, err := session.IsTableExist(cpeDictionaryTitle{})
+	if err != nil {
+		return nil, err
+	}
+	if hasTitles {
+		err = session.Where("vendor = ?", vendor.Name).Find(&titles)
+		if err != nil {
+			return nil, err
+		}
+	}
+	titlesByProduct := map[string][]string{}
+	for _, t := range titles {
+		titlesByProduct[t.Product] = append(titlesByProduct[t.Product], t.Title)
+	}
+
+	name := prepProductName(title, vendor.Name)
+	var candidates []ProductCandidate
+	for _, product := range products {
+		candidate := ProductCandidate{
+			ProductID:   product.ID,
+			VendorName:  vendor.Name,
+			ProductName: product.ProductName,
+			MatchedName: product.ProductName,
+			Confidence:  nameSimilarity(name, product.ProductName),
+		}
+		for _, dictTitle := range titlesByProduct[product.ProductName] {
+			confidence := nameSimilarity(name, prepProductName(dictTitle, vendor.Name))
+			if confidence > candidate.Confidence {
+				candidate.MatchedName = dictTitle
+				candidate.Confidence = confidence
+			}
+		}
+		if candidate.Confidence > 0 {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].ProductName < candidates[j].ProductName
+	})
+	if len(candidates) > maxFuzzyCandidates {
+		candidates = candidates[0:maxFuzzyCandidates]
+	}
+	return candidates, nil
+}
+
+// resolveFuzzy resolves `title` of `publisher` to a product of `vendor` if fuzzy resolution is enabled and the best
+// candidate has at least the threshold confidence. Titles with only low confidence candidates are recorded for the
+// FuzzyReport. Returns nil if not resolved.
+func (sw *VulnDBSession) resolveFuzzy(vendor *VulndbVendor, publisher, title string) (*ProductCandidate, error) {
+	if sw.fuzzyThreshold <= 0 {
+		return nil, nil
+	}
+	candidates, err := fuzzyProductCandidates(sw, vendor, title)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+	if candidates[0].Confidence >= sw.fuzzyThreshold {
+		return &candidates[0], nil
+	}
+
+	sw.fuzzyReport[publisher+"|"+title] = FuzzyResolution{
+		Publisher:  publisher,
+		Title:      title,
+		Candidates: candidates,
+	}
+	return nil, nil
+}
+
+// resolution returns the product resolution of the accepted candidate for the match evidence.
+func (c ProductCandidate) resolution() productResolution {
+	return productResolution{
+		ResolvedBy:     ResolvedByFuzzy,
+		CandidateNames: []string{c.MatchedName},
+		Confidence:     c.Confidence,
+	}
+}
+
+// nameSimilarity returns the similarity of the cpe-friendly product names `a` and `b` from 0 to 1, as the average
+// of the token (Dice coefficient) and edit distance similarities.
+func nameSimilarity(a, b string) float64 {
+	a = strings.ToLower(a)
+	b = strings.ToLower(b)
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	return (tokenSimilarity(a, b) + editSimilarity(a, b)) / 2
+}
+
+// tokenSimilarity returns the Dice coefficient of the tokens of the names `a` and `b`.
+func tokenSimilarity(a, b string) float64 {
+	split := func(r rune) bool { return r == '_' || r == '-' || r == ' ' }
+	aTokens := strings.FieldsFunc(a, split)
+	bTokens := strings.FieldsFunc(b, split)
+	if len(aTokens) == 0 || len(bTokens) == 0 {
+		return 0
+	}
+
+	bCounts := map[string]int{}
+	for _, token := range bTokens {
+		bCounts[token]++
+	}
+	common := 0
+	for _, token := range aTokens {
+		if bCounts[token] > 0 {
+			bCounts[token]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(aTokens)+len(bTokens))
+}
+
+// editSimilarity returns 1 minus the Levenshtein distance of `a` and `b` relative to the longer of them.
+func editSimilarity(a, b string) float64 {
+	ar := []rune(a)
+	br := []rune(b)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	longest := len(ar)
+	if len(br) > longest {
+		longest = len(br)
+	}
+	return 1 - float64(prev[len(br)])/float64(longest)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,28 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	require.Equal(t, 1.0, nameSimilarity("acrobat_reader_dc", "Acrobat_Reader_DC"))
+	require.Equal(t, 0.0, nameSimilarity("", "acrobat_reader_dc"))
+	require.InDelta(t, 0.5, tokenSimilarity("acrobat_reader", "reader_dc"), 0.001)
+	require.InDelta(t, 0.8, editSimilarity("flash", "flush"), 0.001)
+
+	// Closer names rank higher.
+	title := prepProductName("Adobe Acrobat Reader DC (64-bit)", "adobe")
+	require.Greater(t, nameSimilarity(title, "acrobat_reader_dc"), nameSimilarity(title, "acrobat_reader"))
+	require.Greater(t, nameSimilarity(title, "acrobat_reader"), nameSimilarity(title, "flash_player"))
+}
+
+func TestLoadCPEDict(t *testing.T) {
+	cpeDict, err := loadCPEDict("testdata/cpedict/official-cpe-dictionary_v2.3.xml.gz")
+	require.NoError(t, err)
+	require.Len(t, cpeDict.Items, 4)
+	require.Equal(t, "Adobe Acrobat Reader DC 15.006.30033", cpeDict.Items[0].Title)
+	require.Equal(t, "cpe:2.3:a:adobe:acrobat_reader_dc:15.006.30033:*:*:*:*:*:*:*", cpeDict.Items[0].CPE23.Name)
+	require.NotNil(t, cpeDict.Items[3].Deprecated)
+}
@@ -0,0 +1,656 @@
+package vulndb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"nanscraper/common"
+)
+
+// SoftwareItem is an installed software item of an inventory, as matched by MatchCVEs.
+type SoftwareItem struct {
+	Systype       string // "a" for applications, "o" for operating systems.
+	Publisher     string
+	Title         string
+	Version       string
+	Patch         string
+	TargetSW      string
+	VersionScheme string // Registered version scheme (VersionScheme*) overriding the assignment of the product, e.g. rpm.
+}
+
+// InventoryMatch is the result of MatchInventory for a software item.
+type InventoryMatch struct {
+	Item    SoftwareItem
+	Matches []CVEMatch
+	Err     error // Error matching the item, does not affect the other items.
+}
+
+// itemProduct is a resolved product of a software item.
+type itemProduct struct {
+	Product    vulndbProduct
+	Vendor     VulndbVendor
+	Resolution productResolution
+}
+
+// productItemMatch is a product item of a resolved product matching the version of a software item.
+type productItemMatch struct {
+	Product     itemProduct
+	Scheme      string // Version scheme of the comparator.
+	Item        vulndbProductItem
+	Comparisons []VersionComparison
+}
+
+// MatchInventory matches the CVEs of a batch of software `items` in the same way as MatchCVEs, sharing the
+// lookups of identical items, vendors and products. Vendors, products, product items and advisories are looked up
+// with set based queries instead of per item (see matchSoftwareItems). Returns the results in the order of `items`.
+// If the batch cannot be matched, the items are matched one at a time and the error is set on the failing items
+// only.
+func MatchInventory(session *VulnDBSession, items []SoftwareItem) []InventoryMatch {
+	results := make([]InventoryMatch, len(items))
+	opts := DefaultMatchOptions()
+
+	// Deduplicate the items, skipping those already cached by the session.
+	indexes := map[SoftwareItem][]int{}
+	var pending []SoftwareItem
+	for i, item := range items {
+		results[i].Item = item
+		cacheKey := matchCacheKey(opts, item)
+		if cached, has := session.cached[cacheKey]; has {
+			results[i].Matches = sortByDisclosure(cached)
+			continue
+		}
+		if _, has := indexes[item]; !has {
+			pending = append(pending, item)
+		}
+		indexes[item] = append(indexes[item], i)
+	}
+	if len(pending) == 0 {
+		return results
+	}
+
+	matches, err := matchSoftwareItems(session, opts, pending)
+	if err != nil {
+		log.Debugf("ERROR matching inventory, matching the %d items one at a time: %v", len(pending), err)
+		for _, item := range pending {
+			itemMatches, err := matchSoftwareItems(session, opts, []SoftwareItem{item})
+			if err != nil {
+				log.Debugf("ERROR matching %s/%s %s: %v", item.Publisher, item.Title, item.Version, err)
+				for _, i := range indexes[item] {
+					results[i].Err = err
+				}
+				continue
+			}
+			setInventoryMatches(session, opts, results, indexes[item], item, itemMatches[item])
+		}
+		return results
+	}
+
+	for _, item := range pending {
+		setInventoryMatches(session, opts, results, indexes[item], item, matches[item])
+	}
+	return results
+}
+
+// setInventoryMatches caches the `matches` of `item` by the session and sets them on the `results` at `indexes`.
+func setInventoryMatches(session *VulnDBSession, opts MatchOptions, results []InventoryMatch, indexes []int, item SoftwareItem, matches []CVEMatch) {
+	session.cached[matchCacheKey(opts, item)] = matches
+	for _, i := range indexes {
+		results[i].Matches = sortByDisclosure(matches)
+	}
+}
+
+// matchSoftwareItems matches the unique software `items` with `opts` as described by MatchCVEsWithOptions,
+// returning the matches by item. The lookups are set based, shared by the items.
+func matchSoftwareItems(session *VulnDBSession, opts MatchOptions, items []SoftwareItem) (map[SoftwareItem][]CVEMatch, error) {
+	for _, item := range items {
+		if len(item.VersionScheme) > 0 && !HasVersionComparator(item.VersionScheme) {
+			return nil, fmt.Errorf("unknown version scheme '%s' of %s/%s", item.VersionScheme, item.Publisher, item.Title)
+		}
+	}
+
+	var targetCPE *CPEParts
+	if len(opts.CPE) > 0 {
+		cpeParts, err := ParseCPE(opts.CPE)
+		if err != nil {
+			return nil, err
+		}
+		targetCPE = &cpeParts
+	}
+
+	// Step 0, Java distributions (Oracle, OpenJDK builds) in any version form match the Oracle JDK/JRE product
+	// items, see normalizeJavaItem.
+	normalized := map[SoftwareItem]SoftwareItem{}
+	var targets []SoftwareItem
+	seenTargets := map[SoftwareItem]bool{}
+	for _, item := range items {
+		target := item
+		if publisher, title, version, patch, ok := normalizeJavaItem(item.Publisher, item.Title, item.Version, item.Patch); ok {
+			target.Publisher, target.Title, target.Version, target.Patch = publisher, title, version, patch
+		}
+		normalized[item] = target
+		if !seenTargets[target] {
+			seenTargets[target] = true
+			targets = append(targets, target)
+		}
+	}
+
+	// Steps 1-3, resolve the products.
+	products, err := resolveItemProducts(session, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 4, product items matching the version.
+	var productIDs []int64
+	seenProducts := map[int64]bool{}
+	for _, target := range targets {
+		for _, p := range products[target] {
+			if !seenProducts[p.Product.ID] {
+				seenProducts[p.Product.ID] = true
+				productIDs = append(productIDs, p.Product.ID)
+			}
+		}
+	}
+	productItems := map[int64][]vulndbProductItem{}
+	err = common.ProcessChunks(productIDs, 900, func(start, end int) error {
+		var chunk []vulndbProductItem
+		err := session.Where(common.MakeInSql("product_id", end-start), int64Params(productIDs[start:end])...).Find(&chunk)
+		if err != nil {
+			return err
+		}
+		for _, productItem := range chunk {
+			productItems[productItem.ProductID] = append(productItems[productItem.ProductID], productItem)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ignored, err := ignoredProducts(session, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	itemProductItemIDs := map[SoftwareItem][]int64{}
+	specificMatches := map[SoftwareItem]map[int64]bool{} // SW Target specific matches.
+	itemMatches := map[SoftwareItem]map[int64]productItemMatch{}
+	var productItemIDs []int64
+	seenProductItems := map[int64]bool{}
+	for _, target := range targets {
+		specificMatches[target] = map[int64]bool{}
+		itemMatches[target] = map[int64]productItemMatch{}
+		for _, p := range products[target] {
+			if ignored[p.Product.ID] {
+				continue
+			}
+			for _, productItem := range productItems[p.Product.ID] {
+				if !strings.EqualFold(productItem.Systype, target.Systype) {
+					continue
+				}
+				specific := false
+				if productItem.SWTarget != nil && len(*productItem.SWTarget) > 0 {
+					if !matchSWTarget(*productItem.SWTarget, target.TargetSW) {
+						continue
+					}
+					specific = true
+				}
+				scheme := target.VersionScheme
+				if len(scheme) == 0 {
+					scheme, err = session.versionScheme(p.Vendor.Name, p.Product.ProductName)
+					if err != nil {
+						return nil, err
+					}
+				}
+				matches, comparisons := productItem.matchVersion(scheme, p.Product.ProductName, target.Version, target.Patch)
+				if !matches {
+					continue
+				}
+				itemProductItemIDs[target] = append(itemProductItemIDs[target], productItem.ID)
+				itemMatches[target][productItem.ID] = productItemMatch{Product: p, Scheme: scheme, Item: productItem, Comparisons: comparisons}
+				if specific {
+					specificMatches[target][productItem.ID] = true
+				}
+				if !seenProductItems[productItem.ID] {
+					seenProductItems[productItem.ID] = true
+					productItemIDs = append(productItemIDs, productItem.ID)
+				}
+			}
+		}
+	}
+
+	// Step 5, advisories of the product items, by the CPE attributes of the target CPE if any.
+	vulns := map[int64][]vulndbVulnerability{}
+	var advisoryIDs []int64
+	seenAdvisories := map[int64]bool{}
+	seenVulns := map[vulndbVulnerability]bool{}
+	err = common.ProcessChunks(productItemIDs, 900, func(start, end int) error {
+		var chunk []vulndbVulnerability
+		err := session.Where(common.MakeInSql("product_item_id", end-start), int64Params(productItemIDs[start:end])...).Find(&chunk)
+		if err != nil {
+			return err
+		}
+		for _, vuln := range chunk {
+			if targetCPE != nil && !vuln.matchCPEAttributes(*targetCPE) {
+				continue
+			}
+			// The product item may be vulnerable by several CPE names of the advisory.
+			key := vulndbVulnerability{AdvisoryID: vuln.AdvisoryID, ProductItemID: vuln.ProductItemID}
+			if seenVulns[key] {
+				continue
+			}
+			seenVulns[key] = true
+			vulns[vuln.ProductItemID] = append(vulns[vuln.ProductItemID], vuln)
+			if !seenAdvisories[vuln.AdvisoryID] {
+				seenAdvisories[vuln.AdvisoryID] = true
+				advisoryIDs = append(advisoryIDs, vuln.AdvisoryID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	advisories := map[int64]NVDCVEAdvisory{}
+	err = common.ProcessChunks(advisoryIDs, 900, func(start, end int) error {
+		whereSQL := common.MakeInSql("id", end-start) + session.rejectedFilterSQL()
+		params := int64Params(advisoryIDs[start:end])
+		if opts.ExcludeDisputed {
+			whereSQL += " AND (status IS NULL OR status != '" + CVEStatusDisputed + "')"
+		}
+		if opts.PublishedAfter != 0 {
+			whereSQL += " AND " + disclosedAtSQL + " >= ?"
+			params = append(params, opts.PublishedAfter)
+		}
+		if opts.PublishedBefore != 0 {
+			whereSQL += " AND " + disclosedAtSQL + " < ?"
+			params = append(params, opts.PublishedBefore)
+		}
+
+		var chunk []NVDCVEAdvisory
+		err := session.Where(whereSQL, params...).Find(&chunk)
+		if err != nil {
+			return err
+		}
+		err = applyCVSSPolicy(session, chunk)
+		if err != nil {
+			return err
+		}
+		for _, advisory := range chunk {
+			advisories[advisory.Id] = advisory
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 6.
+	targetMatches := map[SoftwareItem][]CVEMatch{}
+	for _, target := range targets {
+		var targetAdvisories []NVDCVEAdvisory
+		specificCVEMatches := map[int64]bool{}
+		evidence := map[int64][]MatchEvidence{}
+		added := map[int64]bool{}
+		for _, productItemID := range itemProductItemIDs[target] {
+			for _, vuln := range vulns[productItemID] {
+				advisoryID := vuln.AdvisoryID
+				advisory, has := advisories[advisoryID]
+				if !has {
+					continue
+				}
+				if specificMatches[target][productItemID] {
+					specificCVEMatches[advisoryID] = true
+				}
+				m := itemMatches[target][productItemID]
+				e := newMatchEvidence(m.Product.Resolution, m.Product.Vendor.Name, m.Product.Product.ProductName, m.Scheme, m.Item, vuln, m.Comparisons)
+				evidence[advisoryID] = append(evidence[advisoryID], e)
+				if !added[advisoryID] {
+					added[advisoryID] = true
+					targetAdvisories = append(targetAdvisories, advisory)
+				}
+			}
+		}
+		if len(targetAdvisories) > 0 {
+			targetMatches[target] = selectMatches(targetAdvisories, specificCVEMatches, evidence, opts)
+		}
+	}
+
+	matches := map[SoftwareItem][]CVEMatch{}
+	for _, item := range items {
+		matches[item] = targetMatches[normalized[item]]
+	}
+	return matches, nil
+}
+
+// resolveItemProducts resolves the products of the software `items` by product aliases, or by vendor and product
+// name (see MatchCVEsWithOptions steps 1-3).
+func resolveItemProducts(session *VulnDBSession, items []SoftwareItem) (map[SoftwareItem][]itemProduct, error) {
+	// Step 1.
+	productIDs, err := resolveProductAliases(session, items)
+	if err != nil {
+		return nil, err
+	}
+	var vendorNames []string
+	seenVendorNames := map[string]bool{}
+	for _, item := range items {
+		if len(productIDs[item]) == 0 && !seenVendorNames[item.Publisher] {
+			seenVendorNames[item.Publisher] = true
+			vendorNames = append(vendorNames, item.Publisher)
+		}
+	}
+
+	// Step 2.
+	vendors, err := resolveVendors(session, vendorNames)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3.
+	// Try both title directly, and prepared cpe-friendly product name.
+	candidates := map[SoftwareItem][]string{}
+	var names []string
+	seenNames := map[string]bool{}
+	for _, item := range items {
+		vendorMatch := vendors[item.Publisher]
+		if len(productIDs[item]) > 0 || vendorMatch == nil {
+			continue
+		}
+		cpeFriendly := prepProductName(item.Title, vendorMatch.Vendor.Name)
+		candidates[item] = append([]string{item.Title}, alternativeNames(cpeFriendly)...)
+		for _, name := range candidates[item] {
+			if !seenNames[name] {
+				seenNames[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	productsByName := map[string][]vulndbProduct{}
+	err = processStringChunks(names, 900, func(chunk []string) error {
+		var products []vulndbProduct
+		err := session.Where(common.MakeInSql("product_name", len(chunk)), stringParams(chunk)...).Find(&products)
+		if err != nil {
+			return err
+		}
+		for _, product := range products {
+			productsByName[product.ProductName] = append(productsByName[product.ProductName], product)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := map[SoftwareItem][]itemProduct{}
+	for _, item := range items {
+		for _, productID := range productIDs[item] {
+			product, err := session.GetProductById(productID)
+			if err != nil {
+				return nil, err
+			}
+			if product == nil {
+				continue
+			}
+			vendor, err := session.GetVendorById(product.VendorID)
+			if err != nil {
+				return nil, err
+			}
+			if vendor == nil {
+				continue
+			}
+			resolved[item] = append(resolved[item], itemProduct{
+				Product:    *product,
+				Vendor:     *vendor,
+				Resolution: productResolution{ResolvedBy: ResolvedByProductAlias},
+			})
+		}
+
+		vendorMatch := vendors[item.Publisher]
+		if vendorMatch == nil {
+			continue
+		}
+		added := map[int64]bool{}
+		for _, name := range candidates[item] {
+			for _, product := range productsByName[name] {
+				if product.VendorID == vendorMatch.Vendor.ID && !added[product.ID] {
+					added[product.ID] = true
+					resolved[item] = append(resolved[item], itemProduct{
+						Product: product,
+						Vendor:  *vendorMatch.Vendor,
+						Resolution: productResolution{
+							ResolvedBy:     vendorMatch.resolvedBy(),
+							CandidateNames: candidates[item],
+						},
+					})
+				}
+			}
+		}
+
+		// Step 3c.
+		if len(productIDs[item]) == 0 && len(resolved[item]) == 0 {
+			candidate, err := session.resolveFuzzy(vendorMatch.Vendor, item.Publisher, item.Title)
+			if err != nil {
+				return nil, err
+			}
+			if candidate != nil {
+				product, err := session.GetProductById(candidate.ProductID)
+				if err != nil {
+					return nil, err
+				}
+				if product == nil {
+					continue
+				}
+				resolved[item] = append(resolved[item], itemProduct{
+					Product:    *product,
+					Vendor:     *vendorMatch.Vendor,
+					Resolution: candidate.resolution(),
+				})
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// resolveVendors resolves the vendors of the publishers `vendorNames` (see MatchVendor) by name, vendor alias or
+// cpe-friendly name. Publishers without a matching vendor are mapped to nil.
+func resolveVendors(session *VulnDBSession, vendorNames []string) (map[string]*VendorMatchResult, error) {
+	names := append([]string{}, vendorNames...)
+	for _, vendorName := range vendorNames {
+		names = append(names, prepVendorName(vendorName))
+	}
+	byName := map[string]*VulndbVendor{}
+	err := processStringChunks(names, 900, func(chunk []string) error {
+		var vendors []VulndbVendor
+		err := session.Where(common.MakeInSql("name", len(chunk)), stringParams(chunk)...).Find(&vendors)
+		if err != nil {
+			return err
+		}
+		for i := range vendors {
+			byName[vendors[i].Name] = &vendors[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byAlias := map[string]int64{}
+	err = processStringChunks(vendorNames, 900, func(chunk []string) error {
+		var vendorAliases []VulndbVendorAlias
+		err := session.Where(common.MakeInSql("alias", len(chunk)), stringParams(chunk)...).Find(&vendorAliases)
+		if err != nil {
+			return err
+		}
+		for _, vendorAlias := range vendorAliases {
+			if _, has := byAlias[vendorAlias.Alias]; !has {
+				byAlias[vendorAlias.Alias] = vendorAlias.VendorID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vendors := map[string]*VendorMatchResult{}
+	for _, vendorName := range vendorNames {
+		cpeFriendly := prepVendorName(vendorName)
+		if vendor, has := byName[vendorName]; has {
+			vendors[vendorName] = &VendorMatchResult{Vendor: vendor}
+		} else if vendorID, has := byAlias[vendorName]; has {
+			vendor, err := session.GetVendorById(vendorID)
+			if err != nil {
+				return nil, err
+			}
+			if vendor != nil {
+				vendors[vendorName] = &VendorMatchResult{Vendor: vendor, FromAlias: true, VendorAlias: vendorName}
+			}
+		} else if vendor, has := byName[cpeFriendly]; has {
+			vendors[vendorName] = &VendorMatchResult{Vendor: vendor, CPEFriendlyMatch: true, CPEFriendlyName: cpeFriendly}
+		}
+	}
+	return vendors, nil
+}
+
+// productAliasRow is a product alias matching a software item.
+type productAliasRow struct {
+	Publisher string `xorm:"publisher"`
+	Title     string `xorm:"title"`
+	ProductID int64  `xorm:"product_id"`
+}
+
+// resolveProductAliases returns the product of the first product alias of the vendor alias (publisher) matching
+// the title of the software `items` by GLOB.
+func resolveProductAliases(session *VulnDBSession, items []SoftwareItem) (map[SoftwareItem][]int64, error) {
+	type publisherTitle struct {
+		Publisher string
+		Title     string
+	}
+	var pairs []publisherTitle
+	seen := map[publisherTitle]bool{}
+	for _, item := range items {
+		pair := publisherTitle{item.Publisher, item.Title}
+		if !seen[pair] {
+			seen[pair] = true
+			pairs = append(pairs, pair)
+		}
+	}
+
+	aliases := map[publisherTitle]int64{}
+	for start := 0; start < len(pairs); start += 450 {
+		end := start + 450
+		if end > len(pairs) {
+			end = len(pairs)
+		}
+		var values []string
+		var params []interface{}
+		for _, pair := range pairs[start:end] {
+			values = append(values, "(?, ?)")
+			params = append(params, pair.Publisher, pair.Title)
+		}
+		sql := `
+WITH items(publisher, title) AS (VALUES ` + strings.Join(values, ", ") + `)
+SELECT
+items.publisher AS publisher,
+items.title AS title,
+pa.product_id AS product_id
+FROM items
+INNER JOIN vulndb_product_aliases pa
+ON pa.vendor_alias = items.publisher AND items.title GLOB pa.product_alias
+ORDER BY pa.rowid
+`
+		var rows []productAliasRow
+		err := session.Sql(sql, params...).Find(&rows)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			pair := publisherTitle{row.Publisher, row.Title}
+			if _, has := aliases[pair]; !has {
+				aliases[pair] = row.ProductID
+			}
+		}
+	}
+
+	productIDs := map[SoftwareItem][]int64{}
+	for _, item := range items {
+		if productID, has := aliases[publisherTitle{item.Publisher, item.Title}]; has {
+			productIDs[item] = []int64{productID}
+		}
+	}
+	return productIDs, nil
+}
+
+// ignoredProducts returns the products of `productIDs` on the ignore list.
+func ignoredProducts(session *VulnDBSession, productIDs []int64) (map[int64]bool, error) {
+	ignored := map[int64]bool{}
+	err := common.ProcessChunks(productIDs, 900, func(start, end int) error {
+		sql := `
+SELECT DISTINCT p.id
+FROM vulndb_products p
+INNER JOIN vulndb_vendors v
+ON v.id = p.vendor_id
+INNER JOIN vulndb_ignore_list il
+ON il.vendor_name = v.name AND p.product_name GLOB il.product_name_glob
+WHERE ` + common.MakeInSql("p.id", end-start)
+		var ids []int64
+		err := session.Sql(sql, int64Params(productIDs[start:end])...).Find(&ids)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			ignored[id] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ignored, nil
+}
+
+// sortByDisclosure returns a copy of `matches` sorted by public disclosure date (NVD published date if unknown)
+// and CVE ID.
+func sortByDisclosure(matches []CVEMatch) []CVEMatch {
+	matches = append([]CVEMatch(nil), matches...)
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Advisory.DisclosedAt() != matches[j].Advisory.DisclosedAt() {
+			return matches[i].Advisory.DisclosedAt() < matches[j].Advisory.DisclosedAt()
+		}
+		return matches[i].Advisory.CVEID < matches[j].Advisory.CVEID
+	})
+	return matches
+}
+
+// processStringChunks calls `fn` for chunks of `values` of up to `chunkSize` values.
+func processStringChunks(values []string, chunkSize int, fn func(chunk []string) error) error {
+	for start := 0; start < len(values); start += chunkSize {
+		end := start + chunkSize
+		if end > len(values) {
+			end = len(values)
+		}
+		err := fn(values[start:end])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func int64Params(values []int64) []interface{} {
+	params := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		params = append(params, value)
+	}
+	return params
+}
+
+func stringParams(values []string) []interface{} {
+	params := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		params = append(params, value)
+	}
+	return params
+}
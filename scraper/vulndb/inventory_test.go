@@ -0,0 +1,87 @@
+package vulndb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchInventoryEqualsMatchCVEs(t *testing.T) {
+	vdbPath := os.Getenv(`VULNDB_PATH`)
+	if len(vdbPath) == 0 {
+		t.Skipf("Skipped, VULNDB_PATH not set")
+		return
+	}
+
+	vdb, err := New(vdbPath)
+	require.NoError(t, err)
+	defer vdb.Close()
+
+	items := []SoftwareItem{
+		{Systype: "a", Publisher: "Google Inc.", Title: "Google Chrome", Version: "62.0.3202.94"},
+		{Systype: "a", Publisher: "Igor Pavlov", Title: "7-Zip 9.20 (x64 edition)", Version: "9.20"},
+		{Systype: "a", Publisher: "Mozilla", Title: "Mozilla Firefox 52.5.0 ESR (x64 en-US)", Version: "52.5.0"},
+		{Systype: "a", Publisher: "Adobe Systems, Inc.", Title: "Adobe ColdFusion 11", Version: "11.0.0.0"},
+		{Systype: "a", Publisher: "Oracle Corporation", Title: "Java 8 Update 201", Version: "8.0.2010.9"},
+		{Systype: "a", Publisher: "Simon Tatham", Title: "PuTTY release 0.70 (64-bit)", Version: "0.70"},
+		{Systype: "o", Publisher: "cisco", Title: "ios", Version: "15.2(4)E10"},
+		{Systype: "a", Publisher: "", Title: "Google Chrome", Version: "62.0.3202.94"},
+		{Systype: "a", Publisher: "Unknown Publisher", Title: "Unknown", Version: "1.0"},
+		{Systype: "a", Publisher: "Google Inc.", Title: "Google Chrome", Version: "62.0.3202.94"},
+	}
+
+	// Separate sessions, as the matches are cached by session.
+	inventorySession, err := vdb.NewSession()
+	require.NoError(t, err)
+	defer inventorySession.Close()
+	results := MatchInventory(inventorySession, items)
+	require.Len(t, results, len(items))
+
+	vdbSession, err := vdb.NewSession()
+	require.NoError(t, err)
+	defer vdbSession.Close()
+	for i, item := range items {
+		require.NoError(t, results[i].Err)
+		matches, err := MatchCVEs(vdbSession, item.Systype, item.Publisher, item.Title, item.Version, item.Patch, item.TargetSW)
+		require.NoError(t, err)
+		require.Equal(t, matches, results[i].Matches, "%s/%s %s", item.Publisher, item.Title, item.Version)
+	}
+}
+
+func TestMatchInventoryItemError(t *testing.T) {
+	vdbPath := os.Getenv(`VULNDB_PATH`)
+	if len(vdbPath) == 0 {
+		t.Skipf("Skipped, VULNDB_PATH not set")
+		return
+	}
+
+	vdb, err := New(vdbPath)
+	require.NoError(t, err)
+	defer vdb.Close()
+
+	items := []SoftwareItem{
+		{Systype: "a", Publisher: "Google Inc.", Title: "Google Chrome", Version: "62.0.3202.94"},
+		{Systype: "a", Publisher: "Mozilla", Title: "Mozilla Firefox 52.5.0 ESR (x64 en-US)", Version: "52.5.0", VersionScheme: "unknown"},
+		{Systype: "a", Publisher: "Simon Tatham", Title: "PuTTY release 0.70 (64-bit)", Version: "0.70"},
+	}
+
+	inventorySession, err := vdb.NewSession()
+	require.NoError(t, err)
+	defer inventorySession.Close()
+	results := MatchInventory(inventorySession, items)
+	require.Len(t, results, len(items))
+	require.Error(t, results[1].Err)
+	require.Empty(t, results[1].Matches)
+
+	vdbSession, err := vdb.NewSession()
+	require.NoError(t, err)
+	defer vdbSession.Close()
+	for _, i := range []int{0, 2} {
+		require.NoError(t, results[i].Err)
+		require.NotEmpty(t, results[i].Matches)
+		matches, err := MatchCVEs(vdbSession, items[i].Systype, items[i].Publisher, items[i].Title, items[i].Version, items[i].Patch, items[i].TargetSW)
+		require.NoError(t, err)
+		require.Equal(t, matches, results[i].Matches)
+	}
+}
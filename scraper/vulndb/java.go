@@ -0,0 +1,180 @@
+package vulndb
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// javaVersion is a normalized Oracle/OpenJDK version. Legacy versions 1.x.y_u are represented as feature x,
+// interim y and update u, e.g. 1.8.0_291 is 8.0.291.
+type javaVersion struct {
+	Feature   int
+	Interim   int
+	Update    int  // Update/security release, e.g. 291 for 8u291, 2 for 17.0.2.
+	Patch     int  // Emergency patch release, e.g. 1 for 11.0.9.1.
+	AnyUpdate bool // Update not specified (CPE update ANY), matching all updates.
+}
+
+var (
+	// Update form: e.g. 8u291, jdk-8u291-b10, Java 8 Update 291 or Java(TM) SE Development Kit 8 Update 291.
+	reJavaUpdateForm = regexp.MustCompile(`(?i)(?:^|[\s-])(?:jdk|jre)?(?:1\.)?([1-8])\s*(?:u|\s+update\s+)(\d+)\b`)
+	// Windows installer form (8.0.<update>0.<build>): e.g. 8.0.2910.10 for 8u291 b10.
+	reJavaInstallerForm = regexp.MustCompile(`^([1-8])\.0\.(\d+)0\.\d+$`)
+	// Legacy form: e.g. 1.8.0, 1.8.0_291, 1.8.0_291-b10 or jdk1.8.0_291.
+	reJavaLegacyForm = regexp.MustCompile(`(?i)^(?:jdk|jre)?-?1\.([1-8])(?:\.(\d+))?(?:_(\d+))?(?:-b\d+)?$`)
+	// Modern (JEP 322) form: e.g. 17, 17.0.2, jdk-17.0.2+8, 11.0.13_8 or 11.0.9.1-LTS.
+	reJavaModernForm = regexp.MustCompile(`(?i)^(?:jdk|jre)?-?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.(\d+))?(?:[+_-][0-9a-z.+-]*)?$`)
+	// Update as CPE update: e.g. update_291, update291, u291 or 291.
+	reJavaUpdatePatch = regexp.MustCompile(`(?i)^(?:update|u)?[_-]?0*(\d+)$`)
+)
+
+// parseJavaVersion parses the Oracle/OpenJDK version `ver` in any of the common inventory forms (8u291,
+// 1.8.0_291, Java 8 Update 291, 8.0.2910.10, jdk-17.0.2+8) with the update given by the CPE update `patch` if
+// not in the version. Returns false if not a Java version.
+func parseJavaVersion(ver, patch string) (javaVersion, bool) {
+	var v javaVersion
+	ver = strings.TrimSpace(ver)
+	hasUpdate := true
+	if parts := reJavaUpdateForm.FindStringSubmatch(ver); parts != nil {
+		nums := atoiParts(parts[1], parts[2])
+		v.Feature, v.Update = nums[0], nums[1]
+	} else if parts := reJavaInstallerForm.FindStringSubmatch(ver); parts != nil {
+		nums := atoiParts(parts[1], parts[2])
+		v.Feature, v.Update = nums[0], nums[1]
+	} else if parts := reJavaLegacyForm.FindStringSubmatch(ver); parts != nil {
+		nums := atoiParts(parts[1], parts[2], parts[3])
+		v.Feature, v.Interim, v.Update = nums[0], nums[1], nums[2]
+		hasUpdate = len(parts[3]) > 0
+	} else if parts := reJavaModernForm.FindStringSubmatch(ver); parts != nil {
+		nums := atoiParts(parts[1], parts[2], parts[3], parts[4])
+		v.Feature, v.Interim, v.Update, v.Patch = nums[0], nums[1], nums[2], nums[3]
+		if v.Feature <= 8 {
+			// E.g. 8 or 8.0.291, legacy update releases.
+			hasUpdate = len(parts[3]) > 0
+		}
+	} else {
+		return v, false
+	}
+	if v.Feature < 1 {
+		return v, false
+	}
+
+	if !hasUpdate {
+		patch = strings.TrimSpace(patch)
+		if parts := reJavaUpdatePatch.FindStringSubmatch(patch); parts != nil {
+			v.Update = atoiParts(parts[1])[0]
+		} else if patch == "*" {
+			v.AnyUpdate = true
+		}
+	}
+	return v, true
+}
+
+// CPEVersion returns the version and CPE update as used by NVD for the Oracle JDK/JRE product items, e.g.
+// 1.8.0 and update_291 for 8u291, or 17.0.2 and no update for jdk-17.0.2+8.
+func (v javaVersion) CPEVersion() (version, update string) {
+	if v.Feature <= 8 {
+		version = fmt.Sprintf("1.%d.%d", v.Feature, v.Interim)
+		if v.Update > 0 {
+			update = "update_" + strconv.Itoa(v.Update)
+		}
+		return version, update
+	}
+	version = fmt.Sprintf("%d.%d.%d", v.Feature, v.Interim, v.Update)
+	if v.Patch > 0 {
+		version += "." + strconv.Itoa(v.Patch)
+	}
+	return version, ""
+}
+
+// Compare compares Java version `v` against `another` and returns 1 if `v` > `another`, 0 if equal, -1 if
+// `v` < `another`. Updates are not compared if either is any update.
+func (v javaVersion) Compare(another javaVersion) int {
+	if v.AnyUpdate || another.AnyUpdate {
+		return compareVersionNumbers([]int{v.Feature, v.Interim}, []int{another.Feature, another.Interim})
+	}
+	return compareVersionNumbers([]int{v.Feature, v.Interim, v.Update, v.Patch},
+		[]int{another.Feature, another.Interim, another.Update, another.Patch})
+}
+
+// VersionCompareJava compares versions for Oracle/OpenJDK Java products, normalizing the inventory forms of the
+// versions (see parseJavaVersion), e.g. 8u291 equals 1.8.0 update_291. Falls back to generic version handling if
+// either version is not a Java version.
+func VersionCompareJava(templateVer, targetVer, templatePatch, targetPatch string) int {
+	verTpl, okTpl := parseJavaVersion(templateVer, templatePatch)
+	verTgt, okTgt := parseJavaVersion(targetVer, targetPatch)
+	if !okTpl || !okTgt {
+		return withPatchCompare(VersionCompare(templateVer, targetVer), templatePatch, targetPatch)
+	}
+	return verTgt.Compare(verTpl)
+}
+
+var (
+	// Java distribution titles: e.g. Java 8 Update 291, Java(TM) SE Development Kit 17.0.2, Zulu JDK,
+	// Eclipse Temurin JDK with Hotspot, Amazon Corretto or Microsoft Build of OpenJDK.
+	reJavaTitle = regexp.MustCompile(`(?i)\b(?:java|jdk|jre|openjdk|zulu|temurin|corretto|liberica)\b`)
+	// Java tools and components other than the runtime and development kit, e.g. Eclipse IDE for Java Developers.
+	reJavaTitleExcluded = regexp.MustCompile(`(?i)updater|auto update|javafx|access bridge|mission control|javascript|java db|plug-?in|\bide\b|for java`)
+	// Java runtime titles: e.g. Java 8 Update 291 (the Oracle JRE installer) or Zulu JRE.
+	reJavaRuntimeTitle = regexp.MustCompile(`(?i)\bjre\b|runtime|^java(?:\(tm\))?\s+\d+\s+update\b`)
+)
+
+// javaPublishers are the publishers of Oracle/OpenJDK distributions, matched as whole words of the publisher.
+// IBM and SAP are not included, as their builds are not versioned as Oracle/OpenJDK, e.g. IBM SDK 8.0.7.0 is
+// service refresh 7 rather than 8u7.
+var javaPublishers = []string{"oracle", "sun microsystems", "azul", "adoptium", "adoptopenjdk", "eclipse", "amazon",
+	"red hat", "microsoft", "bellsoft"}
+
+// reNonWord matches the separators of the publisher words.
+var reNonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// javaProduct returns the Oracle product (jdk or jre) of the Java distribution `title` by `publisher`, false if
+// not a Java distribution. The NVD product names jdk, jre and openjdk by Oracle are kept.
+func javaProduct(publisher, title string) (string, bool) {
+	publisher = strings.ToLower(strings.TrimSpace(publisher))
+	title = strings.TrimSpace(title)
+	publisherWords := " " + strings.TrimSpace(reNonWord.ReplaceAllString(publisher, " ")) + " "
+	isJavaPublisher := false
+	for _, javaPublisher := range javaPublishers {
+		if strings.Contains(publisherWords, " "+javaPublisher+" ") {
+			isJavaPublisher = true
+			break
+		}
+	}
+	if !isJavaPublisher || !reJavaTitle.MatchString(title) || reJavaTitleExcluded.MatchString(title) {
+		return "", false
+	}
+
+	switch lowerTitle := strings.ToLower(title); lowerTitle {
+	case "jdk", "jre", "openjdk":
+		if publisher == "oracle" {
+			return lowerTitle, true
+		}
+	}
+	if reJavaRuntimeTitle.MatchString(title) {
+		return "jre", true
+	}
+	return "jdk", true
+}
+
+// normalizeJavaItem returns the Oracle vendor and product with the NVD version and update (see
+// javaVersion.CPEVersion) for Java distributions in any of the inventory forms, so that they match the Oracle
+// JDK/JRE product items. Returns false if not a Java distribution.
+func normalizeJavaItem(publisher, title, version, patch string) (string, string, string, string, bool) {
+	product, ok := javaProduct(publisher, title)
+	if !ok {
+		return "", "", "", "", false
+	}
+	ver, ok := parseJavaVersion(version, patch)
+	if !ok {
+		// E.g. Java 8 Update 291 without version.
+		ver, ok = parseJavaVersion(title, patch)
+	}
+	if !ok || ver.AnyUpdate {
+		return "oracle", product, version, patch, true
+	}
+	version, patch = ver.CPEVersion()
+	return "oracle", product, version, patch, true
+}
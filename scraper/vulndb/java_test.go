@@ -0,0 +1,90 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJavaVersion(t *testing.T) {
+	testcases := []struct {
+		Version string
+		Patch   string
+		CPEVer  string
+		CPEUpd  string
+	}{
+		{"1.8.0_291", "", "1.8.0", "update_291"},
+		{"1.8.0_291-b10", "", "1.8.0", "update_291"},
+		{"8u291", "", "1.8.0", "update_291"},
+		{"jdk-8u291-b10", "", "1.8.0", "update_291"},
+		{"Java 8 Update 291", "", "1.8.0", "update_291"},
+		{"8.0.2910.10", "", "1.8.0", "update_291"},
+		{"1.8.0", "update_291", "1.8.0", "update_291"},
+		{"1.8.0", "update291", "1.8.0", "update_291"},
+		{"1.7.0_80", "", "1.7.0", "update_80"},
+		{"1.8.0", "", "1.8.0", ""},
+		{"jdk-17.0.2+8", "", "17.0.2", ""},
+		{"17.0.2", "", "17.0.2", ""},
+		{"11.0.13_8", "", "11.0.13", ""},
+		{"11.0.9.1-LTS", "", "11.0.9.1", ""},
+	}
+
+	for _, tcase := range testcases {
+		v, ok := parseJavaVersion(tcase.Version, tcase.Patch)
+		require.True(t, ok, tcase.Version)
+		version, update := v.CPEVersion()
+		require.Equal(t, tcase.CPEVer, version, tcase.Version)
+		require.Equal(t, tcase.CPEUpd, update, tcase.Version)
+	}
+
+	_, ok := parseJavaVersion("latest", "")
+	require.False(t, ok)
+}
+
+func TestVersionCompareJava(t *testing.T) {
+	require.Equal(t, 0, VersionCompareJava("1.8.0", "8u291", "update_291", ""))
+	require.Equal(t, 1, VersionCompareJava("1.8.0", "1.8.0_291", "update_281", ""))
+	require.Equal(t, -1, VersionCompareJava("1.8.0", "Java 8 Update 281", "update_291", ""))
+	require.Equal(t, 0, VersionCompareJava("1.8.0", "8u291", "*", ""))
+	require.Equal(t, 1, VersionCompareJava("11.0.13", "jdk-17.0.2+8", "", ""))
+	require.Equal(t, -1, VersionCompareJava("17.0.3", "jdk-17.0.2+8", "", ""))
+	require.Equal(t, 1, VersionCompareJava("11.0.9", "11.0.9.1", "", ""))
+}
+
+func TestJavaProduct(t *testing.T) {
+	testcases := []struct {
+		Publisher string
+		Title     string
+		Product   string
+	}{
+		{"Oracle Corporation", "Java 8 Update 291 (64-bit)", "jre"},
+		{"Oracle Corporation", "Java(TM) SE Development Kit 8 Update 291", "jdk"},
+		{"Oracle Corporation", "Java SE Runtime Environment 8", "jre"},
+		{"oracle", "jdk", "jdk"},
+		{"oracle", "openjdk", "openjdk"},
+		{"Azul Systems, Inc.", "Zulu JDK 11.50+19", "jdk"},
+		{"Eclipse Adoptium", "Eclipse Temurin JDK with Hotspot 17.0.2+8 (x64)", "jdk"},
+		{"Eclipse Adoptium", "Eclipse Temurin JRE with Hotspot 17.0.2+8 (x64)", "jre"},
+		{"Amazon.com Inc.", "Amazon Corretto", "jdk"},
+		{"Microsoft", "Microsoft Build of OpenJDK with Hotspot 17.0.2+8 (x64)", "jdk"},
+		{"Red Hat, Inc.", "OpenJDK 1.8.0_322-1 64-bit", "jdk"},
+	}
+	for _, tcase := range testcases {
+		product, ok := javaProduct(tcase.Publisher, tcase.Title)
+		require.True(t, ok, tcase.Title)
+		require.Equal(t, tcase.Product, product, tcase.Title)
+	}
+
+	for _, title := range []string{"Java Auto Updater", "Eclipse IDE for Java Developers", "JavaScript Debugger"} {
+		_, ok := javaProduct("Oracle Corporation", title)
+		require.False(t, ok, title)
+	}
+	for _, publisher := range []string{"Acme", "IBM Corporation", "SAP SE", "Sapient Corp.", "Isoracle Ltd."} {
+		_, ok := javaProduct(publisher, "Java 8 Update 291")
+		require.False(t, ok, publisher)
+	}
+	_, ok := javaProduct("IBM Corporation", "IBM 64-bit SDK, Java Technology Edition, Version 8")
+	require.False(t, ok)
+	_, _, _, _, ok = normalizeJavaItem("IBM Corporation", "IBM 64-bit SDK, Java Technology Edition, Version 8", "8.0.7.0", "")
+	require.False(t, ok)
+}
@@ -0,0 +1,377 @@
+package vulndb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// jsaAdvisory represents a Juniper Security Advisory (JSA) loaded from a local HTML or JSON file.
+type jsaAdvisory struct {
+	ID     string // e.g. JSA10910
+	Title  string
+	URL    string
+	CVEIDs []string
+	Fixes  []jsaFix
+}
+
+// jsaFix represents the first fixed Junos release within a release train.
+type jsaFix struct {
+	Product string // CPE product name, e.g. junos or junos_os_evolved.
+	Train   string // e.g. 15.1X49 or 18.4R2
+	Fixed   string // e.g. 15.1X49-D160 or 18.4R2-S3
+}
+
+// jsaProducts maps Juniper product names as used in the advisories to CPE product names.
+var jsaProducts = map[string]string{
+	"junos os":         "junos",
+	"junos":            "junos",
+	"junos os evolved": "junos_os_evolved",
+}
+
+var (
+	reJSAID    = regexp.MustCompile(`JSA\d{4,6}`)
+	reJSACVEID = regexp.MustCompile(`CVE-\d{4}-\d{4,}`)
+	// Junos release, e.g. 15.1X49-D160, 18.4R2-S3.2 or 20.4R2-EVO.
+	reJSARelease = regexp.MustCompile(`\b\d{1,2}\.\d[A-Z]\d+(?:-[A-Z]\d+)?(?:\.\d+)?(?:-EVO)?\b`)
+	// Affected statement, e.g. "15.1X49 versions prior to 15.1X49-D160".
+	reJSAPriorTo = regexp.MustCompile(`versions prior to (\d{1,2}\.\d[A-Z]\d+(?:-[A-Z]\d+)?(?:\.\d+)?(?:-EVO)?)`)
+	// Solution statement listing the fixed releases.
+	reJSASolution = regexp.MustCompile(`(?s)updated to resolve this specific issue:(.*?)(?:and all subsequent releases|This issue is being tracked|$)`)
+)
+
+// newJSAFix returns the fix for the `fixed` release string, deriving the release train from it.
+// The returned bool flag is false if the release could not be parsed as a Junos version.
+func newJSAFix(product, fixed string) (jsaFix, bool) {
+	fixed = strings.ToUpper(strings.TrimSpace(fixed))
+	if strings.HasSuffix(fixed, "-EVO") {
+		product = "junos_os_evolved"
+		fixed = strings.TrimSuffix(fixed, "-EVO")
+	}
+
+	ver, found := ParseJunosVersion(fixed)
+	if !found {
+		return jsaFix{}, false
+	}
+
+	return jsaFix{
+		Product: product,
+		Train:   ver.Major + "." + ver.Minor + ver.Type + ver.Build,
+		Fixed:   fixed,
+	}, true
+}
+
+// rangeStart returns the lower bound of the releases affected in the train of `fix`: the train base (e.g. 18.4)
+// for the earliest regular release (R) train fixed in its major and minor, as all the releases of the major and
+// minor before it are affected ("18.4 versions prior to 18.4R2-S3"), otherwise the train (e.g. 18.4R3 or 15.1X49).
+func (adv *jsaAdvisory) rangeStart(fix jsaFix) string {
+	ver, found := ParseJunosVersion(fix.Fixed)
+	if !found || ver.Type != "R" {
+		return fix.Train
+	}
+	build := atoiParts(ver.Build)[0]
+	for _, other := range adv.Fixes {
+		if other.Product != fix.Product {
+			continue
+		}
+		otherVer, found := ParseJunosVersion(other.Fixed)
+		if found && otherVer.Type == "R" && otherVer.Major == ver.Major && otherVer.Minor == ver.Minor &&
+			atoiParts(otherVer.Build)[0] < build {
+			return fix.Train
+		}
+	}
+	return ver.Major + "." + ver.Minor
+}
+
+// addFix adds `fix` to the advisory unless already present.
+func (adv *jsaAdvisory) addFix(fix jsaFix) {
+	for _, f := range adv.Fixes {
+		if f == fix {
+			return
+		}
+	}
+	adv.Fixes = append(adv.Fixes, fix)
+}
+
+// parseJuniperJSAHTML parses a JSA as saved from the Juniper support portal (HTML).
+// The affected trains are taken from the "versions prior to" statements in the problem description
+// and the fixed releases from the solution statement.
+func parseJuniperJSAHTML(data []byte) (*jsaAdvisory, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	adv := &jsaAdvisory{}
+	adv.Title = strings.TrimSpace(doc.Find("h1").First().Text())
+	if len(adv.Title) == 0 {
+		adv.Title = strings.TrimSpace(doc.Find("title").First().Text())
+	}
+	if href, has := doc.Find(`link[rel="canonical"]`).Attr("href"); has {
+		adv.URL = href
+	}
+
+	text := doc.Text()
+	adv.ID = reJSAID.FindString(text)
+	adv.CVEIDs = uniqueStrings(reJSACVEID.FindAllString(text, -1))
+
+	for _, match := range reJSAPriorTo.FindAllStringSubmatch(text, -1) {
+		if fix, ok := newJSAFix("junos", match[1]); ok {
+			adv.addFix(fix)
+		}
+	}
+	if solution := reJSASolution.FindStringSubmatch(text); solution != nil {
+		for _, release := range reJSARelease.FindAllString(solution[1], -1) {
+			if fix, ok := newJSAFix("junos", release); ok {
+				adv.addFix(fix)
+			}
+		}
+	}
+
+	return adv, nil
+}
+
+// jsaCVERecord represents a CVE JSON 5 record as published by the Juniper CNA.
+type jsaCVERecord struct {
+	CVEMetadata struct {
+		CVEID string `json:"cveId"`
+	} `json:"cveMetadata"`
+	Containers struct {
+		CNA struct {
+			Title  string `json:"title"`
+			Source struct {
+				Advisory string `json:"advisory"`
+			} `json:"source"`
+			Affected []struct {
+				Vendor   string `json:"vendor"`
+				Product  string `json:"product"`
+				Versions []struct {
+					Version  string `json:"version"`
+					LessThan string `json:"lessThan"`
+					Status   string `json:"status"`
+				} `json:"versions"`
+			} `json:"affected"`
+			References []struct {
+				URL string `json:"url"`
+			} `json:"references"`
+		} `json:"cna"`
+	} `json:"containers"`
+}
+
+// parseJuniperJSAJSON parses a CVE JSON 5 record published by Juniper. Each affected version range
+// with a `lessThan` boundary yields the fixed release for that train.
+func parseJuniperJSAJSON(data []byte) (*jsaAdvisory, error) {
+	var record jsaCVERecord
+	err := json.Unmarshal(data, &record)
+	if err != nil {
+		return nil, err
+	}
+
+	cna := record.Containers.CNA
+	adv := &jsaAdvisory{
+		ID:    cna.Source.Advisory,
+		Title: cna.Title,
+	}
+	if len(record.CVEMetadata.CVEID) > 0 {
+		adv.CVEIDs = []string{record.CVEMetadata.CVEID}
+	}
+	for _, ref := range cna.References {
+		if reJSAID.MatchString(ref.URL) || strings.Contains(ref.URL, "kb.juniper.net") {
+			adv.URL = ref.URL
+			if len(adv.ID) == 0 {
+				adv.ID = reJSAID.FindString(ref.URL)
+			}
+			break
+		}
+	}
+
+	for _, affected := range cna.Affected {
+		product, has := jsaProducts[strings.ToLower(affected.Product)]
+		if !has {
+			log.Debugf("Unsupported Juniper product '%s' - skipping", affected.Product)
+			continue
+		}
+		for _, v := range affected.Versions {
+			if v.Status != "affected" || len(v.LessThan) == 0 {
+				continue
+			}
+			fix, ok := newJSAFix(product, v.LessThan)
+			if !ok {
+				log.Debugf("Unable to parse Junos release '%s' - skipping", v.LessThan)
+				continue
+			}
+			adv.addFix(fix)
+		}
+	}
+
+	return adv, nil
+}
+
+// loadJuniperJSAs loads all JSA advisories (*.html, *.htm, *.json) from the directory `jsaDir`.
+func loadJuniperJSAs(jsaDir string) ([]*jsaAdvisory, error) {
+	files, err := ioutil.ReadDir(jsaDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var advisories []*jsaAdvisory
+	for _, finfo := range files {
+		if finfo.IsDir() {
+			continue
+		}
+		path := filepath.Join(jsaDir, finfo.Name())
+
+		var parse func([]byte) (*jsaAdvisory, error)
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".html", ".htm":
+			parse = parseJuniperJSAHTML
+		case ".json":
+			parse = parseJuniperJSAJSON
+		default:
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		adv, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if len(adv.CVEIDs) == 0 || len(adv.Fixes) == 0 {
+			log.Debugf("JSA file %s without CVEs or fixed releases - skipping", path)
+			continue
+		}
+		advisories = append(advisories, adv)
+	}
+
+	return advisories, nil
+}
+
+// processJuniperJSA loads the JSA advisories from `jsaDir` and inserts the per train Junos ranges into vulndb
+// (see jsaAdvisory.rangeStart).
+// The JSA data is authoritative, thus NVD derived Junos ranges for the same CVEs are replaced.
+func processJuniperJSA(sessionw *VulnDBSession, jsaDir string) error {
+	if len(jsaDir) == 0 {
+		return nil
+	}
+
+	advisories, err := loadJuniperJSAs(jsaDir)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Loaded %d Juniper JSA advisories", len(advisories))
+
+	vendor, err := getOrCreateVendor(sessionw, "juniper")
+	if err != nil {
+		return err
+	}
+
+	replaced := map[string]bool{}
+	linked := map[string]bool{}
+	jsaLinked := map[string]bool{}
+	for _, adv := range advisories {
+		for _, cveID := range adv.CVEIDs {
+			var advisory NVDCVEAdvisory
+			has, err := sessionw.Where("cve_id = ?", cveID).Get(&advisory)
+			if err != nil {
+				return err
+			}
+			if !has {
+				advisory.CVEID = cveID
+				advisory.Summary = adv.Title
+				if err = sessionw.Insert(&advisory); err != nil {
+					return err
+				}
+			}
+
+			// The same JSA may be loaded from both HTML and JSON.
+			if key := adv.ID + ":" + cveID; !jsaLinked[key] {
+				jsa := juniperJSAAdvisory{
+					JSAID: adv.ID,
+					CVEID: cveID,
+					Title: adv.Title,
+					URL:   adv.URL,
+				}
+				if err = sessionw.Insert(&jsa); err != nil {
+					return err
+				}
+				jsaLinked[key] = true
+			}
+
+			for _, fix := range adv.Fixes {
+				prod, err := getOrCreateProduct(sessionw, vendor.ID, fix.Product)
+				if err != nil {
+					return err
+				}
+
+				// Remove the NVD derived items for the product once per CVE.
+				key := fmt.Sprintf("%v:%v", advisory.Id, prod.ID)
+				if !replaced[key] {
+					err = sessionw.Exec(`
+DELETE FROM vulndb_vulnerabilities
+WHERE advisory_id = ?
+AND product_item_id IN (SELECT id FROM vulndb_product_items WHERE product_id = ?)`, advisory.Id, prod.ID)
+					if err != nil {
+						return err
+					}
+					replaced[key] = true
+				}
+
+				start, fixed := adv.rangeStart(fix), fix.Fixed
+				var prodItem vulndbProductItem
+				has, err := sessionw.Where(`product_id = ? AND systype = ? AND version_start_including = ? AND version_end_excluding = ?`,
+					prod.ID, "o", start, fixed).Get(&prodItem)
+				if err != nil {
+					return err
+				}
+				if !has {
+					prodItem.ProductID = prod.ID
+					prodItem.Systype = "o"
+					prodItem.VersionStartIncluding = &start
+					prodItem.VersionEndExcluding = &fixed
+					if err = sessionw.Insert(&prodItem); err != nil {
+						return err
+					}
+				}
+
+				key = fmt.Sprintf("%v:%v", advisory.Id, prodItem.ID)
+				if linked[key] {
+					continue
+				}
+				vuln := vulndbVulnerability{
+					ProductItemID: prodItem.ID,
+					AdvisoryID:    advisory.Id,
+				}
+				if err = sessionw.Insert(&vuln); err != nil {
+					return err
+				}
+				linked[key] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// uniqueStrings returns the sorted unique entries of `values`.
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,126 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadJuniperJSAs(t *testing.T) {
+	advisories, err := loadJuniperJSAs("testdata/jsa")
+	require.NoError(t, err)
+	require.Len(t, advisories, 2)
+
+	// JSON (CVE JSON 5 record).
+	adv := advisories[0]
+	require.Equal(t, "JSA11114", adv.ID)
+	require.Equal(t, "https://kb.juniper.net/JSA11114", adv.URL)
+	require.Equal(t, []string{"CVE-2021-0223"}, adv.CVEIDs)
+	require.Equal(t, []jsaFix{
+		{Product: "junos", Train: "15.1X49", Fixed: "15.1X49-D240"},
+		{Product: "junos", Train: "18.4R2", Fixed: "18.4R2-S7"},
+		{Product: "junos", Train: "18.4R3", Fixed: "18.4R3-S6"},
+		{Product: "junos_os_evolved", Train: "20.4R2", Fixed: "20.4R2"},
+	}, adv.Fixes)
+
+	// HTML.
+	adv = advisories[1]
+	require.Equal(t, "JSA10905", adv.ID)
+	require.Equal(t, "https://kb.juniper.net/JSA10905", adv.URL)
+	require.Equal(t, []string{"CVE-2019-0001"}, adv.CVEIDs)
+	require.Equal(t, []jsaFix{
+		{Product: "junos", Train: "12.3X48", Fixed: "12.3X48-D75"},
+		{Product: "junos", Train: "15.1X49", Fixed: "15.1X49-D150"},
+		{Product: "junos", Train: "17.3R3", Fixed: "17.3R3-S2"},
+		{Product: "junos", Train: "17.3R2", Fixed: "17.3R2-S4"},
+		{Product: "junos", Train: "18.1R3", Fixed: "18.1R3"},
+	}, adv.Fixes)
+}
+
+func TestJuniperJSAFixBoundaries(t *testing.T) {
+	testcases := []struct {
+		Fixed    string
+		Version  string
+		Patch    string
+		Affected bool
+	}{
+		{"15.1X49-D150", "15.1X49", "D140", true},
+		{"15.1X49-D150", "15.1X49", "D150", false},
+		{"15.1X49-D150", "15.1X49", "D160", false},
+		{"15.1X49-D150", "12.3X48", "D70", false}, // Other train.
+		{"17.3R3-S2", "17.3R3", "", true},
+		{"17.3R3-S2", "17.3R3-S1", "", true},
+		{"17.3R3-S2", "17.3R3-S3", "", false},
+		{"17.3R3-S2", "17.3R2-S1", "", false}, // Other train.
+	}
+
+	for _, tcase := range testcases {
+		fix, ok := newJSAFix("junos", tcase.Fixed)
+		require.True(t, ok)
+
+		startCmp := VersionCompareProduct("juniper", fix.Product, fix.Train, tcase.Version, "", tcase.Patch)
+		endCmp := VersionCompareProduct("juniper", fix.Product, fix.Fixed, tcase.Version, "", tcase.Patch)
+		affected := (startCmp == 0 || startCmp == 1) && endCmp == -1
+		require.Equal(t, tcase.Affected, affected, "Tcase: %+v", tcase)
+	}
+}
+
+func TestJuniperJSARangeStart(t *testing.T) {
+	adv := &jsaAdvisory{}
+	for _, fixed := range []string{"18.4R3-S6", "18.4R2-S7", "15.1X49-D240", "19.1R1-S2"} {
+		fix, ok := newJSAFix("junos", fixed)
+		require.True(t, ok)
+		adv.addFix(fix)
+	}
+	var starts []string
+	for _, fix := range adv.Fixes {
+		starts = append(starts, adv.rangeStart(fix))
+	}
+	require.Equal(t, []string{"18.4R3", "18.4", "15.1X49", "19.1"}, starts)
+
+	testcases := []struct {
+		Version  string
+		Patch    string
+		Affected bool
+	}{
+		{"18.4R1", "", true},
+		{"18.4R1-S3", "", true},
+		{"18.4R2-S1", "", true},
+		{"18.4R2-S7", "", false},
+		{"18.4R2-S8", "", false}, // Fixed in the 18.4R2 train.
+		{"18.4R3", "", true},
+		{"18.4R3-S6", "", false},
+		{"18.4R4", "", false},
+		{"18.3R3", "", false},
+		{"19.1R1", "", true},
+		{"19.1R2", "", false},
+		{"15.1X49", "D230", true},
+		{"15.1X49", "D240", false},
+		{"15.1R7", "", false},
+	}
+	for _, tcase := range testcases {
+		affected := false
+		for _, fix := range adv.Fixes {
+			start := adv.rangeStart(fix)
+			item := vulndbProductItem{VersionStartIncluding: &start, VersionEndExcluding: &fix.Fixed}
+			if matches, _ := item.matchVersion(VersionSchemeJunos, fix.Product, tcase.Version, tcase.Patch); matches {
+				affected = true
+			}
+		}
+		require.Equal(t, tcase.Affected, affected, "Tcase: %+v", tcase)
+	}
+}
+
+func TestJuniperJSARangeBuilds(t *testing.T) {
+	// Only the ranges from a train base compare across the regular release builds.
+	start, end := "18.4", "18.4R2-S7"
+	item := vulndbProductItem{VersionStartIncluding: &start, VersionEndExcluding: &end}
+	matches, comparisons := item.matchVersion(VersionSchemeJunos, "junos", "18.4R1-S3", "")
+	require.True(t, matches)
+	require.Equal(t, -1, comparisons[1].Result)
+
+	start = "18.4R1"
+	matches, comparisons = item.matchVersion(VersionSchemeJunos, "junos", "18.4R1-S3", "")
+	require.False(t, matches)
+	require.Equal(t, 2, comparisons[1].Result)
+}
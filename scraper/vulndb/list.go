@@ -184,10 +184,13 @@ type ListProductItemsProductItem struct {
 	VersionEndExcluding   *string `xorm:"version_end_excluding"`
 	VersionEndIncluding   *string `xorm:"version_end_including"`
 	Patch                 string  `xorm:"patch"`
-	CPE                   string  `xorm:"-"`
+	SWTarget              *string `xorm:"sw_target"`
+	CPE                   string  `xorm:"-"` // CPE 2.3 formatted string, a product level key (version ANY) for ranges.
 }
 
 // ListProductItems looks up products items from product inventory by `vendor` and product `name`.
+// The version ranges are only given by the version fields of the items: the CPE of a range item has version ANY,
+// identifying the product rather than the affected versions.
 // If vendors is specified (not nil) then will limit the products returned to any of the vendors specified.
 func ListProductItems(session *VulnDBSession, name string, vendorIds []int64) (*ListProductItemsResults, error) {
 	ret := ListProductItemsResults{}
@@ -218,7 +221,8 @@ vpi.version_start_excluding AS version_start_excluding,
 vpi.version_start_including AS version_start_including,
 vpi.version_end_excluding AS version_end_excluding,
 vpi.version_end_including AS version_end_including,
-vpi.patch AS patch
+vpi.patch AS patch,
+vpi.sw_target AS sw_target
 FROM vulndb_product_items vpi
 INNER JOIN vulndb_products vp
 ON vp.id = vpi.product_id
@@ -239,30 +243,20 @@ WHERE 1
 
 	// Populate CPE.
 	for i, item := range ret.Items {
-		var version string
-
-		if item.Version != nil && *item.Version != "*" {
-			version = *item.Version
-		} else {
-			if item.VersionStartIncluding != nil {
-				version = fmt.Sprintf("[%s, ", *item.VersionStartIncluding)
-			} else if item.VersionStartExcluding != nil {
-				version = fmt.Sprintf("(%s, ", *item.VersionStartExcluding)
-			} else {
-				version = fmt.Sprintf("(, ")
-			}
-			if item.VersionEndIncluding != nil {
-				version += fmt.Sprintf("%s]", *item.VersionEndIncluding)
-			} else if item.VersionEndExcluding != nil {
-				version += fmt.Sprintf("%s)", *item.VersionEndExcluding)
-			} else {
-				version += ")"
-			}
+		cpeParts := CPEParts{
+			Systype: item.Systype,
+			Vendor:  item.VendorName,
+			Product: item.ProductName,
+			Version: "*",
+			Patch:   item.Patch,
 		}
-
-		cpe := "cpe:/" + item.Systype + ":" + item.VendorName + ":" + item.ProductName + ":" + version + ":" + item.Patch
-
-		ret.Items[i].CPE = cpe
+		if item.Version != nil && len(*item.Version) > 0 {
+			cpeParts.Version = *item.Version
+		}
+		if item.SWTarget != nil {
+			cpeParts.TargetSW = *item.SWTarget
+		}
+		ret.Items[i].CPE = cpeParts.WFN().BindToFS()
 	}
 
 	return &ret, nil
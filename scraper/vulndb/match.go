@@ -1,11 +1,12 @@
 package vulndb
 
 import (
+	"fmt"
 	"regexp"
 	"sort"
 	"strings"
 
-	"nanscraper/common"
+	"nanscraper/cvss"
 )
 
 // GetAdvisory looks up NVD advisory by `cve`.
@@ -18,7 +19,13 @@ func GetAdvisory(session *VulnDBSession, cve string) (*NVDCVEAdvisory, error) {
 	if !has {
 		return nil, nil
 	}
-	return &advisory, nil
+
+	advisories := []NVDCVEAdvisory{advisory}
+	err = applyCVSSPolicy(session, advisories)
+	if err != nil {
+		return nil, err
+	}
+	return &advisories[0], nil
 }
 
 // GetVendor looks up appropriate VulndbVendor for input `vendorName`.
@@ -52,320 +59,267 @@ type VendorMatchResult struct {
 //    the prepared name, returning only on exact match.
 // 6. Otherwise return nil to indicate there was no match.
 func MatchVendor(session *VulnDBSession, vendorName string) (*VendorMatchResult, error) {
-	var result VendorMatchResult
-
-	// Step 1.
-	var vendor VulndbVendor
-	has, err := session.Where(`name = ?`, vendorName).Get(&vendor)
-	if err != nil {
-		return nil, err
-	}
-	// Step 2.
-	if has {
-		result.Vendor = &vendor
-		return &result, nil
-	}
-	// Step 3.
-	var vendorAlias VulndbVendorAlias
-	has, err = session.Where(`alias = ?`, vendorName).Get(&vendorAlias)
-	if err != nil {
-		return nil, err
-	}
-	// Step 4.
-	if has {
-		_, err = session.Where(`id = ?`, vendorAlias.VendorID).Get(&vendor)
-		if err != nil {
-			return nil, err
-		}
-		result.Vendor = &vendor
-		result.FromAlias = true
-		result.VendorAlias = vendorAlias.Alias
-		return &result, nil
-	}
-	// Step 5.
-	cpeFriendly := prepVendorName(vendorName)
-	has, err = session.Where(`name = ?`, cpeFriendly).Get(&vendor)
+	vendors, err := resolveVendors(session, []string{vendorName})
 	if err != nil {
 		return nil, err
 	}
-	if has {
-		result.Vendor = &vendor
-		result.CPEFriendlyMatch = true
-		result.CPEFriendlyName = cpeFriendly
-		return &result, nil
-	}
-
-	// Step 6.
-	return nil, nil
+	return vendors[vendorName], nil
 }
 
-// Number of hits per CVE match for product. Ordered by CVSS3 base score.
+// Number of hits per CVE match for product returned by MatchCVEs. Ordered by the latest CVSS base score: CVSS4,
+// CVSS3 or CVSS2.
 const maxNumHits = 5
 
 // CVEMatch is a result from MatchCVEs containing a match to an advisory and information about the match.
 type CVEMatch struct {
-	Advisory   NVDCVEAdvisory
-	TargetedSW bool // True if match was specific to the target_sw.
+	Advisory           NVDCVEAdvisory
+	TargetedSW         bool            // True if match was specific to the target_sw.
+	VEX                *VEXStatement   // Applicable VEX statement if any (see ApplyVEX).
+	EnvironmentalScore *float64        // CVSS3 environmental score for the asset requirements (see ScoreMatches).
+	Evidence           []MatchEvidence // Why the advisory matched, per matched product item (see Explain).
+}
+
+// MatchSortKey determines the order of the advisories matched by MatchCVEsWithOptions.
+type MatchSortKey int
+
+const (
+	SortByBaseScore MatchSortKey = iota // Base score of the latest CVSS version (see NVDCVEAdvisory.BaseScore), descending.
+	SortByCVSS2                         // CVSS2 base score, descending.
+	SortByPublished                     // NVD published date, newest first.
+	SortByCVEID                         // CVE ID, ascending.
+	SortByDisclosed                     // Public disclosure date (see NVDCVEAdvisory.DisclosedAt), oldest first as MatchCVEs.
+)
+
+// MatchOptions configures the results of MatchCVEsWithOptions. The zero value returns all matches ordered by
+// the latest CVSS base score (see SortByBaseScore).
+type MatchOptions struct {
+	Limit           int          // Maximum number of advisories, 0 for unlimited.
+	Offset          int          // Number of advisories to skip, for pagination with Limit.
+	SortBy          MatchSortKey // Order of the advisories, applied before Offset and Limit.
+	MinSeverity     int          // Minimum CVSS3 severity (SeverityType*) of the latest base score, 0 for any.
+	PublishedAfter  int64        // Only advisories disclosed (see NVDCVEAdvisory.DisclosedAt) at or after (unix time), 0 for any.
+	PublishedBefore int64        // Only advisories disclosed (see NVDCVEAdvisory.DisclosedAt) before (unix time), 0 for any.
+	CPE             string       // Target CPE name to filter product items by edition, language, target_hw etc.
+	ExcludeDisputed bool         // Exclude disputed CVEs (see NVDCVEAdvisory.IsDisputed), reported by default.
+}
+
+// DefaultMatchOptions returns the options of MatchCVEs: up to `maxNumHits` advisories with the highest base score
+// of the latest CVSS version.
+func DefaultMatchOptions() MatchOptions {
+	return MatchOptions{
+		Limit:  maxNumHits,
+		SortBy: SortByBaseScore,
+	}
 }
 
 // MatchCVEs looks up a product by systype ("o"/"a"), publisher, title, version, patch, target_sw and returns a list of CVE ids.
+// Returns up to `maxNumHits` advisories with the highest CVSS base score of the latest version (CVSS4, CVSS3 or
+// CVSS2), sorted by public disclosure date (NVD published date if unknown) and CVE ID. See MatchCVEsWithOptions.
+func MatchCVEs(session *VulnDBSession, systype, publisher, title, version, patch, target_sw string) ([]CVEMatch, error) {
+	matches, err := MatchCVEsWithOptions(session, DefaultMatchOptions(), systype, publisher, title, version, patch, target_sw)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortByDisclosure(matches), nil
+}
+
+// MatchCVEsWithOptions looks up a product by systype ("o"/"a"), publisher, title, version, patch, target_sw and
+// returns the matching CVE advisories according to `opts`.
+// 0. Normalize Java distributions to the Oracle JDK/JRE products and NVD version form (see normalizeJavaItem).
 // 1. Look up vendor/product directly by vendor/product aliases and populate productIDs with match.
 // 2. If no matches. Look up vendor (both directly, checking vendor aliases, and potential cpe-friendly fits).
 // 2b. If no vendor match - return nil.
 // 3. If vendor match, look for matching product under vendor name, and populate the product ids into productIDs.
 // 3b. If no product ID matches, return nil.
+// 3c. If enabled, resolve the product by fuzzy matching under the vendor (see SetFuzzyThreshold).
 // 4. For each productID check all the product items for matching version.
 // 5. For each product item, look up CVEs and populate a list of CVEs.
-// 6. Return the alphabetically sorted list of CVE advisories.
-// Returns up to `maxNumHits` advisories ordered by CVSS3 base score.
-func MatchCVEs(session *VulnDBSession, systype, publisher, title, version, patch, target_sw string) ([]CVEMatch, error) {
-	cacheKey := systype + publisher + title + version + patch + target_sw
+// 6. Filter the CVE advisories by severity, sort them by `opts.SortBy` and return the requested page.
+// The steps are shared with MatchInventory, see matchSoftwareItems.
+func MatchCVEsWithOptions(session *VulnDBSession, opts MatchOptions, systype, publisher, title, version, patch, target_sw string) ([]CVEMatch, error) {
+	item := SoftwareItem{
+		Systype:   systype,
+		Publisher: publisher,
+		Title:     title,
+		Version:   version,
+		Patch:     patch,
+		TargetSW:  target_sw,
+	}
+	cacheKey := matchCacheKey(opts, item)
 	if cachedResult, cached := session.cached[cacheKey]; cached {
 		return cachedResult, nil
 	}
 
-	var productIDs []int64
-
-	// Step 1.
-	var prodalias vulndbProductAlias
-	has, err := session.Where("vendor_alias = ? AND ? GLOB product_alias", publisher, title).Get(&prodalias)
+	matches, err := matchSoftwareItems(session, opts, []SoftwareItem{item})
 	if err != nil {
 		return nil, err
 	}
-	if has {
-		productIDs = append(productIDs, prodalias.ProductID)
-	}
+	session.cached[cacheKey] = matches[item]
+	return matches[item], nil
+}
 
-	if len(productIDs) < 1 {
-		// Step 2.
-		vendor, err := GetVendor(session, publisher)
-		if err != nil {
-			log.Debugf("ERROR getting vendor: %v", err)
-			return nil, err
-		}
-		if vendor == nil {
-			// Step 2b.
-			return nil, err
-		}
+// matchCacheKey returns the session cache key of the matches of software `item` with `opts`.
+func matchCacheKey(opts MatchOptions, item SoftwareItem) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s|%+v", item.Systype, item.Publisher, item.Title, item.Version, item.Patch,
+		item.TargetSW, item.VersionScheme, opts)
+}
 
-		// Step 3.
-		// Try both title directly, and prepared cpe-friendly product name.
-		cpeFriendly := prepProductName(title, vendor.Name)
-		candidates := []string{title}
-		candidates = append(candidates, alternativeNames(cpeFriendly)...)
-		var products []vulndbProduct
-
-		whereSQL := "vendor_id = ? AND " + common.MakeInSql("product_name", len(candidates))
-		params := []interface{}{vendor.ID}
-		for _, candidate := range candidates {
-			params = append(params, candidate)
-		}
-		err = session.Where(whereSQL, params...).Find(&products)
-		if err != nil {
-			return nil, err
-		}
-		for _, product := range products {
-			productIDs = append(productIDs, product.ID)
+// selectMatches filters the `advisories` by severity, sorts them and returns the page according to `opts`.
+// Advisories in `specific` are marked as matched specific to the target_sw, with the `evidence` by advisory.
+func selectMatches(advisories []NVDCVEAdvisory, specific map[int64]bool, evidence map[int64][]MatchEvidence, opts MatchOptions) []CVEMatch {
+	advisories = filterBySeverity(advisories, opts.MinSeverity)
+	sortAdvisories(advisories, opts.SortBy)
+	if opts.Offset > 0 {
+		if opts.Offset >= len(advisories) {
+			advisories = nil
+		} else {
+			advisories = advisories[opts.Offset:]
 		}
 	}
-
-	// Step 3b.
-	if len(productIDs) < 1 {
-		return nil, nil
+	if opts.Limit > 0 && len(advisories) > opts.Limit {
+		advisories = advisories[0:opts.Limit]
 	}
 
-	// Step 4 (version matching).
-	// First pulls all the potential matches by productID and filters out product items by version (in go code).
-	var productItems []vulndbProductItem
-	whereSQL := common.MakeInSql("product_id", len(productIDs)) + " AND LOWER(systype) = LOWER(?)"
-	var params []interface{}
-	for _, productID := range productIDs {
-		params = append(params, productID)
-	}
-	params = append(params, systype)
-	err = session.Where(whereSQL, params...).Find(&productItems)
-	if err != nil {
-		return nil, err
+	matches := make([]CVEMatch, 0, len(advisories))
+	for _, advisory := range advisories {
+		matches = append(matches, CVEMatch{
+			Advisory:   advisory,
+			TargetedSW: specific[advisory.Id],
+			Evidence:   evidence[advisory.Id],
+		})
 	}
-	var productItemIDs []int64
-	specificMatches := map[int64]bool{} // Map of SW Target specific matches.
-	// Filter by version.
-	for _, item := range productItems {
-		specific := false
-		if item.SWTarget != nil && len(*item.SWTarget) > 0 {
-			if !matchSWTarget(*item.SWTarget, target_sw) {
-				continue
-			}
-			specific = true
-		}
-
-		product, err := session.GetProductById(item.ProductID)
-		if err != nil {
-			log.Debugf("ERROR: %v", err)
-			return nil, err
-		}
-		if product == nil {
-			log.Debugf("ERROR: Product ID missing - skipping")
-			continue
-		}
+	return matches
+}
 
-		vendor, err := session.GetVendorById(product.VendorID)
-		if err != nil {
-			log.Debugf("ERROR: %v", err)
-			return nil, err
-		}
-		if vendor == nil {
-			log.Debugf("ERROR: Product ID missing - skipping")
-			continue
+// matchVersion returns true if the product item of product `productName` matches `version` and `patch` by the
+// comparator of version scheme `scheme`, either by version or version range. Returns the version comparisons made
+// as evidence.
+func (item vulndbProductItem) matchVersion(scheme, productName, version, patch string) (bool, []VersionComparison) {
+	var comparisons []VersionComparison
+	compare := func(bound, template string) int {
+		var cmpVal int
+		if scheme == VersionSchemeJunos && (bound == BoundEndIncluding || bound == BoundEndExcluding) &&
+			item.VersionStartIncluding != nil && reJunosTrainBase.MatchString(*item.VersionStartIncluding) {
+			// Ranges from a train base span the regular releases of the major and minor.
+			cmpVal = versionCompareJunosRelease(template, version, item.Patch, patch)
+		} else {
+			cmpVal = VersionCompareScheme(scheme, productName, template, version, item.Patch, patch)
 		}
+		comparisons = append(comparisons, VersionComparison{Bound: bound, Template: template, Result: cmpVal})
+		return cmpVal
+	}
 
-		// Check if is ignored?
-		var ignoreItem []vulndbIgnoreListItem
-		err = session.Where(`vendor_name = ? AND ? GLOB product_name_glob`, vendor.Name, product.ProductName).Find(&ignoreItem)
-		if err != nil {
-			return nil, err
-		}
-		if len(ignoreItem) > 0 {
-			// Ignore the item.
-			continue
+	matches := false
+	if item.Version != nil && len(*item.Version) > 0 && *item.Version != "*" {
+		if compare(BoundVersion, *item.Version) == 0 {
+			matches = true
 		}
-
-		matches := false
-		if item.Version != nil && len(*item.Version) > 0 && *item.Version != "*" {
-			if VersionCompareProduct(vendor.Name, product.ProductName, *item.Version, version, item.Patch, patch) == 0 {
-				matches = true
+	} else {
+		hasStartRange := false
+		hasEndRange := false
+		startRangeMatch := true
+		endRangeMatch := true
+		if item.VersionStartIncluding != nil {
+			hasStartRange = true
+			cmpVal := compare(BoundStartIncluding, *item.VersionStartIncluding)
+			if cmpVal == -1 || cmpVal == 2 { // version < startIncluding
+				startRangeMatch = false
 			}
-		} else {
-			hasStartRange := false
-			hasEndRange := false
-			startRangeMatch := true
-			endRangeMatch := true
-			if item.VersionStartIncluding != nil {
-				hasStartRange = true
-				cmpVal := VersionCompareProduct(vendor.Name, product.ProductName, *item.VersionStartIncluding, version, item.Patch, patch)
-				if cmpVal == -1 || cmpVal == 2 { // version < startIncluding
-					startRangeMatch = false
-				}
-			} else if item.VersionStartExcluding != nil {
-				hasStartRange = true
-				startRangeMatch = true
-				cmpVal := VersionCompareProduct(vendor.Name, product.ProductName, *item.VersionStartExcluding, version, item.Patch, patch)
-				if cmpVal == 0 || cmpVal == -1 || cmpVal == 2 { // version <= startExcluding
-					startRangeMatch = false
-				}
+		} else if item.VersionStartExcluding != nil {
+			hasStartRange = true
+			startRangeMatch = true
+			cmpVal := compare(BoundStartExcluding, *item.VersionStartExcluding)
+			if cmpVal == 0 || cmpVal == -1 || cmpVal == 2 { // version <= startExcluding
+				startRangeMatch = false
 			}
-			if item.VersionEndIncluding != nil {
-				hasEndRange = true
-				cmpVal := VersionCompareProduct(vendor.Name, product.ProductName, *item.VersionEndIncluding, version, item.Patch, patch)
-				if cmpVal == 1 || cmpVal == 2 { // version > endExcluding
-					endRangeMatch = false
-				}
-			} else if item.VersionEndExcluding != nil {
-				hasEndRange = true
-				cmpVal := VersionCompareProduct(vendor.Name, product.ProductName, *item.VersionEndExcluding, version, item.Patch, patch)
-				if cmpVal == 0 || cmpVal == 1 || cmpVal == 2 { // version >= endExcluding
-					endRangeMatch = false
-				}
+		}
+		if item.VersionEndIncluding != nil {
+			hasEndRange = true
+			cmpVal := compare(BoundEndIncluding, *item.VersionEndIncluding)
+			if cmpVal == 1 || cmpVal == 2 { // version > endExcluding
+				endRangeMatch = false
 			}
-			if (!hasStartRange || startRangeMatch) && hasEndRange && endRangeMatch {
-				matches = true
+		} else if item.VersionEndExcluding != nil {
+			hasEndRange = true
+			cmpVal := compare(BoundEndExcluding, *item.VersionEndExcluding)
+			if cmpVal == 0 || cmpVal == 1 || cmpVal == 2 { // version >= endExcluding
+				endRangeMatch = false
 			}
 		}
-
-		if matches {
-			productItemIDs = append(productItemIDs, item.ID)
-			if specific {
-				specificMatches[item.ID] = true
-			}
+		if (!hasStartRange || startRangeMatch) && hasEndRange && endRangeMatch {
+			matches = true
 		}
 	}
-	if len(productItemIDs) == 0 {
-		return nil, nil
-	}
+	return matches, comparisons
+}
 
-	// Step 5.
-	var vulns []vulndbVulnerability
-	whereSQL = common.MakeInSql("product_item_id", len(productItemIDs))
-	params = []interface{}{}
-	for _, id := range productItemIDs {
-		params = append(params, id)
+// matchCPEAttributes returns true if the CPE attributes of the vulnerability not covered by the version, patch and
+// sw_target matching of the product item (edition, language, sw_edition, target_hw and other) match the `target`
+// CPE name. Vulnerabilities without a CPE name always match.
+func (vuln vulndbVulnerability) matchCPEAttributes(target CPEParts) bool {
+	if vuln.CPE23 == nil {
+		return true
 	}
-	err = session.Where(whereSQL, params...).Find(&vulns)
+	cpeParts, err := ParseCPE(*vuln.CPE23)
 	if err != nil {
-		return nil, err
-	}
-	if len(vulns) == 0 {
-		return nil, nil
-	}
-	var advisoryIDs []int64
-	specificCVEMatches := map[int64]bool{}
-	for _, vuln := range vulns {
-		advisoryIDs = append(advisoryIDs, vuln.AdvisoryID)
-		if _, specificMatch := specificMatches[vuln.ProductItemID]; specificMatch {
-			specificCVEMatches[vuln.AdvisoryID] = true
-		}
+		return true
 	}
+	return cpeAttributeMatches(cpeParts.Edition, target.Edition) &&
+		cpeAttributeMatches(cpeParts.Language, target.Language) &&
+		cpeAttributeMatches(cpeParts.SWEdition, target.SWEdition) &&
+		cpeAttributeMatches(cpeParts.TargetHW, target.TargetHW) &&
+		cpeAttributeMatches(cpeParts.Other, target.Other)
+}
 
-	var advisories []NVDCVEAdvisory
-	err = common.ProcessChunks(advisoryIDs, 900, func(start, end int) error {
-		// Redefine locally for each chunk to process.
-		advisoryIDs := advisoryIDs[start:end]
-		whereSQL := common.MakeInSql("id", len(advisoryIDs))
-		params := []interface{}{}
-		for _, id := range advisoryIDs {
-			params = append(params, id)
-		}
-
-		var advisoriesChunk []NVDCVEAdvisory
-		err = session.Where(whereSQL, params...).OrderBy(`cvss3_base_score DESC`).Find(&advisoriesChunk)
-		if err != nil {
-			return err
-		}
-		if len(advisoriesChunk) > maxNumHits {
-			advisoriesChunk = advisoriesChunk[0:maxNumHits]
+// filterBySeverity returns the `advisories` with a CVSS3 severity of the base score (see NVDCVEAdvisory.BaseScore)
+// of at least `minSeverity`.
+func filterBySeverity(advisories []NVDCVEAdvisory, minSeverity int) []NVDCVEAdvisory {
+	if minSeverity <= SeverityTypeNone {
+		return advisories
+	}
+	var filtered []NVDCVEAdvisory
+	for _, advisory := range advisories {
+		severity := cvss3BaseSeverity.value(cvss.Severity(advisory.BaseScore()))
+		if severity != nil && *severity >= minSeverity {
+			filtered = append(filtered, advisory)
 		}
-		advisories = append(advisories, advisoriesChunk...)
-		return nil
-	})
-	if err != nil {
-		return nil, err
 	}
+	return filtered
+}
 
-	// Sort by importance and limit to maxNumHits.
-	if len(advisories) > maxNumHits {
-		// Sort by base score, descending.
-		sort.SliceStable(advisories, func(i, j int) bool {
-			cvss3i := 0.0
-			cvss3j := 0.0
-			if advisories[i].CVSS3BaseScore != nil {
-				cvss3i = *advisories[i].CVSS3BaseScore
+// sortAdvisories sorts `advisories` by `key`, ties ordered by CVE ID.
+func sortAdvisories(advisories []NVDCVEAdvisory, key MatchSortKey) {
+	sort.SliceStable(advisories, func(i, j int) bool {
+		a, b := advisories[i], advisories[j]
+		switch key {
+		case SortByBaseScore:
+			if scoreA, scoreB := a.BaseScore(), b.BaseScore(); scoreA != scoreB {
+				return scoreA > scoreB
 			}
-			if advisories[j].CVSS3BaseScore != nil {
-				cvss3j = *advisories[j].CVSS3BaseScore
+		case SortByCVSS2:
+			cvss2a := 0.0
+			cvss2b := 0.0
+			if a.CVSS2BaseScore != nil {
+				cvss2a = *a.CVSS2BaseScore
+			}
+			if b.CVSS2BaseScore != nil {
+				cvss2b = *b.CVSS2BaseScore
+			}
+			if cvss2a != cvss2b {
+				return cvss2a > cvss2b
+			}
+		case SortByPublished:
+			if a.PublishedAt != b.PublishedAt {
+				return a.PublishedAt > b.PublishedAt
+			}
+		case SortByDisclosed:
+			if a.DisclosedAt() != b.DisclosedAt() {
+				return a.DisclosedAt() < b.DisclosedAt()
 			}
-
-			return cvss3i >= cvss3j
-		})
-		advisories = advisories[0:maxNumHits]
-	}
-
-	// Step 6.
-	sort.Slice(advisories, func(i, j int) bool {
-		return advisories[i].CVEID < advisories[j].CVEID
-	})
-
-	matches := make([]CVEMatch, 0, len(advisories))
-	for _, advisory := range advisories {
-		_, isSpecific := specificCVEMatches[advisory.Id]
-		match := CVEMatch{
-			Advisory:   advisory,
-			TargetedSW: isSpecific,
 		}
-		matches = append(matches, match)
-	}
-
-	session.cached[cacheKey] = matches
-	return matches, nil
+		return a.CVEID < b.CVEID
+	})
 }
 
 func normalizeSWTarget(swTarget string) string {
@@ -332,3 +332,47 @@ func TestVulnerabilityMatchingCPE(t *testing.T) {
 		require.Equal(t, tcase.CVEList, cveList, "CPE: %s", tcase.CPE)
 	}
 }
+
+func TestSortAdvisories(t *testing.T) {
+	score := func(s float64) *float64 { return &s }
+	advisories := []NVDCVEAdvisory{
+		{CVEID: "CVE-2019-2", PublishedAt: 300, CVSS3BaseScore: score(5.3), CVSS2BaseScore: score(9.3)},
+		{CVEID: "CVE-2019-1", PublishedAt: 100, CVSS4BaseScore: score(9.1)},
+		{CVEID: "CVE-2019-3", PublishedAt: 200, CVSS3BaseScore: score(7.5), CVSS2BaseScore: score(5.0)},
+		{CVEID: "CVE-2019-4", PublishedAt: 200},
+		{CVEID: "CVE-2019-5", PublishedAt: 50, CVSS2BaseScore: score(6.8)},
+		{CVEID: "CVE-2019-6", PublishedAt: 400, CVSS4BaseScore: score(8.7), CVSS3BaseScore: score(4.3)},
+	}
+	cveIDs := func() []string {
+		var ids []string
+		for _, advisory := range advisories {
+			ids = append(ids, advisory.CVEID)
+		}
+		return ids
+	}
+
+	sortAdvisories(advisories, SortByBaseScore)
+	// CVSS4 (v4 only, or preferred over CVSS3), then CVSS3, then CVSS2.
+	require.Equal(t, []string{"CVE-2019-1", "CVE-2019-6", "CVE-2019-3", "CVE-2019-5", "CVE-2019-2", "CVE-2019-4"}, cveIDs())
+	sortAdvisories(advisories, SortByCVSS2)
+	require.Equal(t, []string{"CVE-2019-2", "CVE-2019-5", "CVE-2019-3", "CVE-2019-1", "CVE-2019-4", "CVE-2019-6"}, cveIDs())
+	sortAdvisories(advisories, SortByPublished)
+	require.Equal(t, []string{"CVE-2019-6", "CVE-2019-2", "CVE-2019-3", "CVE-2019-4", "CVE-2019-1", "CVE-2019-5"}, cveIDs())
+	sortAdvisories(advisories, SortByCVEID)
+	require.Equal(t, []string{"CVE-2019-1", "CVE-2019-2", "CVE-2019-3", "CVE-2019-4", "CVE-2019-5", "CVE-2019-6"}, cveIDs())
+	// Disclosed before published by NVD.
+	publicAt := int64(10)
+	advisories[5].PublicAt = &publicAt
+	sortAdvisories(advisories, SortByDisclosed)
+	require.Equal(t, []string{"CVE-2019-6", "CVE-2019-5", "CVE-2019-1", "CVE-2019-3", "CVE-2019-4", "CVE-2019-2"}, cveIDs())
+
+	// Filtered by the same base score as sorted.
+	require.Len(t, filterBySeverity(advisories, 0), 6)
+	require.Len(t, filterBySeverity(advisories, SeverityTypeMedium), 5)
+	require.Len(t, filterBySeverity(advisories, SeverityTypeHigh), 3)
+	require.Len(t, filterBySeverity(advisories, SeverityTypeCritical), 1)
+	rescored := []NVDCVEAdvisory{{CVEID: "CVE-2019-7", CVSS4BaseScore: score(5.0), CVSS3BaseScore: score(9.8)}}
+	require.Equal(t, 5.0, rescored[0].BaseScore())
+	require.Len(t, filterBySeverity(rescored, SeverityTypeCritical), 0)
+	require.Len(t, filterBySeverity(rescored, SeverityTypeMedium), 1)
+}
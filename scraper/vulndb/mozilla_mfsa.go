@@ -0,0 +1,296 @@
+package vulndb
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// mfsaAdvisory represents a Mozilla Foundation Security Advisory (MFSA) loaded from a local checkout of
+// the foundation-security-advisories repository.
+type mfsaAdvisory struct {
+	ID          string // e.g. mfsa2023-01
+	Title       string
+	Impact      string
+	URL         string
+	AnnouncedAt int64
+	CVEIDs      []string
+	Fixes       []mfsaFix
+}
+
+// mfsaFix represents a fixed-in version of a Mozilla product.
+type mfsaFix struct {
+	Product string // CPE product name, e.g. firefox or firefox_esr.
+	Version string // e.g. 109 or 102.7
+}
+
+// mfsaYAML represents an MFSA YAML file (announce/YYYY/mfsaYYYY-NN.yml) or the YAML front matter of the
+// older markdown files (announce/YYYY/mfsaYYYY-NN.md).
+type mfsaYAML struct {
+	Announced  string   `yaml:"announced"`
+	Impact     string   `yaml:"impact"`
+	FixedIn    []string `yaml:"fixed_in"`
+	Title      string   `yaml:"title"`
+	Advisories map[string]struct {
+		Title  string `yaml:"title"`
+		Impact string `yaml:"impact"`
+	} `yaml:"advisories"`
+}
+
+// mfsaProducts maps Mozilla product names as used in fixed_in to CPE product names.
+var mfsaProducts = map[string]string{
+	"firefox":     "firefox",
+	"firefox esr": "firefox_esr",
+	"thunderbird": "thunderbird",
+}
+
+var (
+	reMFSAID    = regexp.MustCompile(`^mfsa\d{4}-\d+$`)
+	reMFSACVEID = regexp.MustCompile(`CVE-\d{4}-\d{4,}`)
+	// Fixed in statement, e.g. "Firefox 109", "Firefox ESR 102.7" or "Thunderbird 102.7.1".
+	reMFSAFixedIn = regexp.MustCompile(`(?i)^(Firefox ESR|Firefox|Thunderbird)\s+(\d+(?:\.\d+)*)$`)
+)
+
+// parseMFSA parses the MFSA `data` of advisory `id`. Markdown files (`markdown` true) consist of YAML front
+// matter followed by the advisory text where the CVE IDs are referenced.
+func parseMFSA(id string, data []byte, markdown bool) (*mfsaAdvisory, error) {
+	var body []byte
+	if markdown {
+		parts := bytes.SplitN(data, []byte("---"), 3)
+		if len(parts) != 3 || len(bytes.TrimSpace(parts[0])) > 0 {
+			return nil, fmt.Errorf("missing front matter")
+		}
+		data, body = parts[1], parts[2]
+	}
+
+	var doc mfsaYAML
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	adv := &mfsaAdvisory{
+		ID:     id,
+		Title:  doc.Title,
+		Impact: strings.ToLower(doc.Impact),
+		URL:    fmt.Sprintf("https://www.mozilla.org/en-US/security/advisories/%s/", id),
+	}
+	if t, err := time.Parse("January 2, 2006", strings.TrimSpace(doc.Announced)); err == nil {
+		adv.AnnouncedAt = t.Unix()
+	}
+
+	for cveID := range doc.Advisories {
+		if reMFSACVEID.MatchString(cveID) {
+			adv.CVEIDs = append(adv.CVEIDs, cveID)
+		}
+	}
+	adv.CVEIDs = append(adv.CVEIDs, reMFSACVEID.FindAllString(string(body), -1)...)
+	adv.CVEIDs = uniqueStrings(adv.CVEIDs)
+
+	for _, fixedIn := range doc.FixedIn {
+		m := reMFSAFixedIn.FindStringSubmatch(strings.TrimSpace(fixedIn))
+		if m == nil {
+			continue
+		}
+		adv.Fixes = append(adv.Fixes, mfsaFix{
+			Product: mfsaProducts[strings.ToLower(m[1])],
+			Version: m[2],
+		})
+	}
+
+	return adv, nil
+}
+
+// loadMozillaMFSAs loads all MFSA advisories (mfsa*.yml, mfsa*.md) found under `mfsaDir`, which is typically
+// the announce directory of the foundation-security-advisories repository.
+func loadMozillaMFSAs(mfsaDir string) ([]*mfsaAdvisory, error) {
+	var advisories []*mfsaAdvisory
+	err := filepath.Walk(mfsaDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".yml" && ext != ".yaml" && ext != ".md" {
+			return nil
+		}
+		id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if !reMFSAID.MatchString(id) {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		adv, err := parseMFSA(id, data, ext == ".md")
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if len(adv.CVEIDs) == 0 || len(adv.Fixes) == 0 {
+			log.Debugf("MFSA file %s without CVEs or fixed versions of supported products - skipping", path)
+			return nil
+		}
+		advisories = append(advisories, adv)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return advisories, nil
+}
+
+// mfsaVersionMajor returns the major version of the `version`, e.g. 102 for 102.7.1.
+func mfsaVersionMajor(version string) string {
+	return strings.SplitN(version, ".", 2)[0]
+}
+
+// mfsaFixedRanges returns the affected version ranges of a product given its `fixed` versions for a CVE.
+// Only the first fixed version within each major version is kept. The lowest one affects all prior versions
+// while the others only affect their major version line, e.g. ESR 91.2 and 78.15 give <78.15 and
+// >=91,<91.2.
+func mfsaFixedRanges(fixed []string) []versionRange {
+	firstFixes := map[string]string{}
+	for _, version := range fixed {
+		major := mfsaVersionMajor(version)
+		if cur, has := firstFixes[major]; !has || VersionCompare(cur, version) == -1 {
+			firstFixes[major] = version
+		}
+	}
+
+	var versions []string
+	for _, version := range firstFixes {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return VersionCompare(versions[j], versions[i]) == -1
+	})
+
+	var ranges []versionRange
+	for i, version := range versions {
+		r := versionRange{VersionEndExcluding: version}
+		if i > 0 {
+			r.VersionStartIncluding = mfsaVersionMajor(version)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// processMozillaMFSA loads the MFSA advisories from `mfsaDir` and inserts the fixed-in ranges of the Mozilla
+// products into vulndb. The MFSA data is authoritative, thus NVD derived ranges for the same CVE and product
+// are replaced.
+func processMozillaMFSA(sessionw *VulnDBSession, mfsaDir string) error {
+	if len(mfsaDir) == 0 {
+		return nil
+	}
+
+	advisories, err := loadMozillaMFSAs(mfsaDir)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Loaded %d Mozilla MFSA advisories", len(advisories))
+
+	vendor, err := getOrCreateVendor(sessionw, "mozilla")
+	if err != nil {
+		return err
+	}
+
+	// The fixed versions of a CVE are spread over multiple advisories (one per product and ESR line), collect
+	// them first.
+	fixes := map[string]map[string][]string{} // CVE ID -> product -> fixed versions.
+	var cveIDs []string
+	for _, adv := range advisories {
+		for _, cveID := range adv.CVEIDs {
+			var advisory NVDCVEAdvisory
+			has, err := sessionw.Where("cve_id = ?", cveID).Get(&advisory)
+			if err != nil {
+				return err
+			}
+			if !has {
+				advisory.CVEID = cveID
+				advisory.Summary = adv.Title
+				if err = sessionw.Insert(&advisory); err != nil {
+					return err
+				}
+			}
+
+			mfsa := mozillaMFSAAdvisory{
+				MFSAID:      adv.ID,
+				CVEID:       cveID,
+				Title:       adv.Title,
+				Impact:      adv.Impact,
+				URL:         adv.URL,
+				AnnouncedAt: adv.AnnouncedAt,
+			}
+			if err = sessionw.Insert(&mfsa); err != nil {
+				return err
+			}
+
+			if _, has := fixes[cveID]; !has {
+				fixes[cveID] = map[string][]string{}
+				cveIDs = append(cveIDs, cveID)
+			}
+			for _, fix := range adv.Fixes {
+				fixes[cveID][fix.Product] = append(fixes[cveID][fix.Product], fix.Version)
+			}
+		}
+	}
+
+	for _, cveID := range cveIDs {
+		var advisory NVDCVEAdvisory
+		_, err := sessionw.Where("cve_id = ?", cveID).Get(&advisory)
+		if err != nil {
+			return err
+		}
+
+		var products []string
+		for product := range fixes[cveID] {
+			products = append(products, product)
+		}
+		sort.Strings(products)
+		for _, product := range products {
+			prod, err := getOrCreateProduct(sessionw, vendor.ID, product)
+			if err != nil {
+				return err
+			}
+
+			// Remove the NVD derived items for the product.
+			err = sessionw.Exec(`
+DELETE FROM vulndb_vulnerabilities
+WHERE advisory_id = ?
+AND product_item_id IN (SELECT id FROM vulndb_product_items WHERE product_id = ?)`, advisory.Id, prod.ID)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range mfsaFixedRanges(fixes[cveID][product]) {
+				prodItem, err := getOrCreateProductItem(sessionw, prod.ID, "a", r)
+				if err != nil {
+					return err
+				}
+				vuln := vulndbVulnerability{
+					ProductItemID: prodItem.ID,
+					AdvisoryID:    advisory.Id,
+				}
+				if err = sessionw.Insert(&vuln); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMozillaMFSAs(t *testing.T) {
+	advisories, err := loadMozillaMFSAs("testdata/mfsa")
+	require.NoError(t, err)
+	require.Len(t, advisories, 4)
+
+	// Markdown with YAML front matter.
+	adv := advisories[0]
+	require.Equal(t, "mfsa2019-01", adv.ID)
+	require.Equal(t, "critical", adv.Impact)
+	require.Equal(t, "https://www.mozilla.org/en-US/security/advisories/mfsa2019-01/", adv.URL)
+	require.Equal(t, int64(1548720000), adv.AnnouncedAt)
+	require.Equal(t, []string{"CVE-2018-18500", "CVE-2018-18501"}, adv.CVEIDs)
+	require.Equal(t, []mfsaFix{{Product: "firefox", Version: "65"}}, adv.Fixes)
+
+	adv = advisories[1]
+	require.Equal(t, "mfsa2023-01", adv.ID)
+	require.Equal(t, "Security Vulnerabilities fixed in Firefox 109", adv.Title)
+	require.Equal(t, []string{"CVE-2023-23597", "CVE-2023-23598"}, adv.CVEIDs)
+	require.Equal(t, []mfsaFix{{Product: "firefox", Version: "109"}}, adv.Fixes)
+
+	adv = advisories[2]
+	require.Equal(t, "mfsa2023-02", adv.ID)
+	require.Equal(t, []string{"CVE-2023-23598"}, adv.CVEIDs)
+	require.Equal(t, []mfsaFix{{Product: "firefox_esr", Version: "102.7"}}, adv.Fixes)
+
+	adv = advisories[3]
+	require.Equal(t, "mfsa2023-03", adv.ID)
+	require.Equal(t, []mfsaFix{{Product: "thunderbird", Version: "102.7"}}, adv.Fixes)
+}
+
+func TestMFSAFixedRanges(t *testing.T) {
+	testcases := []struct {
+		Fixed    []string
+		Expected []versionRange
+	}{
+		{[]string{"109"}, []versionRange{{VersionEndExcluding: "109"}}},
+		{[]string{"91.2", "78.15"}, []versionRange{
+			{VersionEndExcluding: "78.15"},
+			{VersionStartIncluding: "91", VersionEndExcluding: "91.2"},
+		}},
+		{[]string{"60.5.1", "60.5", "52.9"}, []versionRange{
+			{VersionEndExcluding: "52.9"},
+			{VersionStartIncluding: "60", VersionEndExcluding: "60.5"},
+		}},
+	}
+
+	for _, tcase := range testcases {
+		require.Equal(t, tcase.Expected, mfsaFixedRanges(tcase.Fixed), "Fixed: %v", tcase.Fixed)
+	}
+}
@@ -1,5 +1,7 @@
 package vulndb
 
+import "nanscraper/vulndb/nvdjson"
+
 // CVEDirectory represents NVD CVE entries from an NVD CVE xml file.
 type CVEDirectory struct {
 	// Map is a mapping of
@@ -13,22 +15,44 @@ type CVEDirectory struct {
 type CVEAdvisory struct {
 	CVEID             string
 	Summary           string
+	Status            string // CVEStatusPublished, CVEStatusRejected or CVEStatusDisputed.
 	PublishedAtInt    int64
 	LastModifiedAtInt int64
 	CVSS2             *CVECVSS2
 	CVSS3             *CVECVSS3
+	CVSS4             *CVECVSS4
 	VendorRefURL      string
+	References        []CVEAdvisoryReference
+	Configurations    []nvdjson.ConfigurationNode
 	HasPatch          *bool
 	ReportConfirmed   *bool
 }
 
+// CVEAdvisoryReference represents a reference of a CVE advisory.
+type CVEAdvisoryReference struct {
+	URL    string
+	Name   string
+	Source string
+	Tags   []string
+}
+
 // CVECVSS2 represents common CVSS2 impact scores for CVE advisories.
 type CVECVSS2 struct {
-	BaseScore             float64
-	AccessVector          *int
-	AccessComplexity      *int
-	Authentication        *int
-	ConfidentialityImpact *int
+	BaseScore               float64
+	AccessVector            *int
+	AccessComplexity        *int
+	Authentication          *int
+	ConfidentialityImpact   *int
+	IntegrityImpact         *int
+	AvailabilityImpact      *int
+	VectorString            string
+	Severity                int
+	ExploitabilityScore     *float64
+	ImpactScore             *float64
+	ObtainAllPrivilege      *int
+	ObtainUserPrivilege     *int
+	ObtainOtherPrivilege    *int
+	UserInteractionRequired *int
 }
 
 // CVECVSS3 represents common CVSS3 impact scores for CVE advisories.
@@ -47,6 +71,13 @@ type CVECVSS3 struct {
 	ExploitabilityScore   *int
 }
 
+// CVECVSS4 represents CVSS4 scores for CVE advisories.
+type CVECVSS4 struct {
+	VectorString string
+	BaseScore    float64
+	BaseSeverity int
+}
+
 type cveDirEntry struct {
 	CVEID                 string
 	Version               *string
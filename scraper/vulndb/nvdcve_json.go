@@ -43,6 +43,7 @@ func loadNVDCVEJSON(inputPath string, minCVSSScore float64) (*CVEDirectory, erro
 		advisory := CVEAdvisory{}
 		advisory.CVEID = item.CVE.Meta.ID
 		advisory.Summary = item.CVE.GetDescription()
+		advisory.Status = parseCVEStatus(advisory.Summary, item.CVE.VulnStatus)
 
 		timeLayout := "2006-01-02T15:04Z"
 		pubDate, err := time.Parse(timeLayout, item.PublishedDate)
@@ -60,6 +61,12 @@ func loadNVDCVEJSON(inputPath string, minCVSSScore float64) (*CVEDirectory, erro
 		advisory.LastModifiedAtInt = mDate.Unix()
 
 		for _, ref := range item.References() {
+			advisory.References = append(advisory.References, CVEAdvisoryReference{
+				URL:    ref.URL,
+				Name:   ref.Name,
+				Source: ref.RefSource,
+				Tags:   ref.Tags,
+			})
 			if ref.IsVendor() {
 				if len(ref.URL) > 0 {
 					advisory.VendorRefURL = ref.URL
@@ -75,205 +82,43 @@ func loadNVDCVEJSON(inputPath string, minCVSSScore float64) (*CVEDirectory, erro
 			}
 		}
 
-		// TODO: Parse from CVSS "vectorString".
 		// CVSS2.
 		if item.Impact.BaseMetricV2 != nil {
-			cvss2 := item.Impact.BaseMetricV2.CVSSV2
-			advisory.CVSS2 = &CVECVSS2{}
-
-			advisory.CVSS2.BaseScore = cvss2.BaseScore
-
-			switch cvss2.AccessVector {
-			case "LOCAL":
-				val := CVSSAccessVectorLocal
-				advisory.CVSS2.AccessVector = &val
-			case "NETWORK":
-				val := CVSSAccessVectorNetwork
-				advisory.CVSS2.AccessVector = &val
-			case "ADJACENT_NETWORK":
-				val := CVSSAccessVectorAdjacentNetwork
-				advisory.CVSS2.AccessVector = &val
-			default:
-				log.Debugf("ERROR - unsupported access vector: '%s' - ignoring", cvss2.AccessVector)
-			}
-
-			switch cvss2.AccessComplexity {
-			case "LOW":
-				val := CVSSAccessComplexityLow
-				advisory.CVSS2.AccessComplexity = &val
-			case "MEDIUM":
-				val := CVSSAccessComplexityMedium
-				advisory.CVSS2.AccessComplexity = &val
-			case "HIGH":
-				val := CVSSAccessComplexityHigh
-				advisory.CVSS2.AccessComplexity = &val
-			default:
-				log.Debugf("ERROR: Unsupported access complexity: '%s' - ignoring", cvss2.AccessComplexity)
-			}
-			switch cvss2.Authentication {
-			case "NONE":
-				val := CVSSAuthenticationNone
-				advisory.CVSS2.Authentication = &val
-			case "SINGLE_INSTANCE", "SINGLE":
-				val := CVSSAuthenticationSingleInstance
-				advisory.CVSS2.Authentication = &val
-			case "MULTIPLE_INSTANCES", "MULTIPLE":
-				val := CVSSAuthenticationMultipleInstances
-				advisory.CVSS2.Authentication = &val
-			default:
-				log.Debugf("ERROR: Unsupported authentication: '%s' - ignoring", cvss2.Authentication)
-			}
-
-			switch cvss2.ConfidentialityImpact {
-			case "NONE":
-				val := CVSSConfidentialityImpactNone
-				advisory.CVSS2.ConfidentialityImpact = &val
-			case "PARTIAL":
-				val := CVSSConfidentialityImpactPartial
-				advisory.CVSS2.ConfidentialityImpact = &val
-			case "COMPLETE":
-				val := CVSSConfidentialityImpactComplete
-				advisory.CVSS2.ConfidentialityImpact = &val
-			default:
-				log.Debugf("ERROR: Unsupported confidentiality impact: '%s' - ignoring", cvss2.ConfidentialityImpact)
-			}
+			advisory.CVSS2 = newCVECVSS2(*item.Impact.BaseMetricV2)
 		}
 
+		// CVSS3.
 		if item.Impact.BaseMetricV3 != nil {
-			cvss3 := item.Impact.BaseMetricV3.CVSSV3
-			advisory.CVSS3 = &CVECVSS3{}
-
-			switch cvss3.AttackComplexity {
-			case "HIGH":
-				val := AttackComplexityTypeHigh
-				advisory.CVSS3.AttackComplexity = &val
-			case "LOW":
-				val := AttackComplexityTypeLow
-				advisory.CVSS3.AttackComplexity = &val
-			default:
-				log.Debugf("ERROR: Unsupported CVSS3 attack complexity: '%s' - ignoring", cvss3.AttackComplexity)
-			}
-
-			switch cvss3.AttackVector {
-			case "NETWORK":
-				val := AttackVectorTypeNetwork
-				advisory.CVSS3.AttackVector = &val
-			case "ADJACENT_NETWORK":
-				val := AttackVectorTypeAdjacentNetwork
-				advisory.CVSS3.AttackVector = &val
-			case "LOCAL":
-				val := AttackVectorTypeLocal
-				advisory.CVSS3.AttackVector = &val
-			case "PHYSICAL":
-				val := AttackVectorTypePhysical
-				advisory.CVSS3.AttackVector = &val
-			default:
-				log.Debugf("ERROR: Unsupported CVSS3 attack vector: '%s' - ignoring", cvss3.AttackVector)
-			}
+			advisory.CVSS3 = newCVECVSS3(item.Impact.BaseMetricV3.CVSSV3)
+		}
 
-			switch cvss3.AvailabilityImpact {
-			case "NONE":
-				val := CiaTypeNone
-				advisory.CVSS3.AvailabilityImpact = &val
-			case "LOW":
-				val := CiaTypeLow
-				advisory.CVSS3.AvailabilityImpact = &val
-			case "HIGH":
-				val := CiaTypeHigh
-				advisory.CVSS3.AvailabilityImpact = &val
-			default:
-				log.Debugf("ERROR: Unsupported CVSS3 availability impact: '%s' - ignoring", cvss3.AttackVector)
+		// CVSS4.
+		cvss4, err := item.CVSS4()
+		if err != nil {
+			log.Debugf("ERROR: Invalid CVSS4 vector for %s: %v - ignoring", advisory.CVEID, err)
+		} else if cvss4 != nil {
+			advisory.CVSS4 = &CVECVSS4{
+				VectorString: cvss4.VectorString,
+				BaseScore:    cvss4.BaseScore,
 			}
-
-			advisory.CVSS3.BaseScore = cvss3.BaseScore
-
-			switch cvss3.BaseSeverity {
+			switch cvss4.BaseSeverity {
 			case "NONE":
-				val := SeverityTypeNone
-				advisory.CVSS3.BaseSeverity = val
+				advisory.CVSS4.BaseSeverity = SeverityTypeNone
 			case "LOW":
-				val := SeverityTypeLow
-				advisory.CVSS3.BaseSeverity = val
+				advisory.CVSS4.BaseSeverity = SeverityTypeLow
 			case "MEDIUM":
-				val := SeverityTypeMedium
-				advisory.CVSS3.BaseSeverity = val
+				advisory.CVSS4.BaseSeverity = SeverityTypeMedium
 			case "HIGH":
-				val := SeverityTypeHigh
-				advisory.CVSS3.BaseSeverity = val
+				advisory.CVSS4.BaseSeverity = SeverityTypeHigh
 			case "CRITICAL":
-				val := SeverityTypeCritical
-				advisory.CVSS3.BaseSeverity = val
-			default:
-				log.Debugf("ERROR: Unsupported CVSS3 base severity: '%s' - ignoring", cvss3.BaseSeverity)
-			}
-
-			switch cvss3.ConfidentialityImpact {
-			case "NONE":
-				val := CiaTypeNone
-				advisory.CVSS3.ConfidentialityImpact = &val
-			case "LOW":
-				val := CiaTypeLow
-				advisory.CVSS3.ConfidentialityImpact = &val
-			case "HIGH":
-				val := CiaTypeHigh
-				advisory.CVSS3.ConfidentialityImpact = &val
-			default:
-				log.Debugf("ERROR: Unsupported CVSS3 confidentiality impact: '%s' - ignoring", cvss3.ConfidentialityImpact)
-			}
-
-			switch cvss3.IntegrityImpact {
-			case "NONE":
-				val := CiaTypeNone
-				advisory.CVSS3.IntegrityImpact = &val
-			case "LOW":
-				val := CiaTypeLow
-				advisory.CVSS3.IntegrityImpact = &val
-			case "HIGH":
-				val := CiaTypeHigh
-				advisory.CVSS3.IntegrityImpact = &val
+				advisory.CVSS4.BaseSeverity = SeverityTypeCritical
 			default:
-				log.Debugf("ERROR: Unsupported CVSS3 integrity impact: '%s' - ignoring", cvss3.IntegrityImpact)
+				log.Debugf("ERROR: Unsupported CVSS4 base severity: '%s' - ignoring", cvss4.BaseSeverity)
 			}
-
-			switch cvss3.PrivilegesRequired {
-			case "HIGH":
-				val := PrivilegesRequiredTypeHigh
-				advisory.CVSS3.PrivilegesRequired = &val
-			case "LOW":
-				val := PrivilegesRequiredTypeLow
-				advisory.CVSS3.PrivilegesRequired = &val
-			case "NONE":
-				val := PrivilegesRequiredTypeNone
-				advisory.CVSS3.PrivilegesRequired = &val
-			default:
-				log.Debugf("ERROR: Unsupported CVSS3 privileges required: '%s' - ignoring", cvss3.PrivilegesRequired)
-			}
-
-			switch cvss3.Scope {
-			case "UNCHANGED":
-				val := ScopeTypeUnchanged
-				advisory.CVSS3.Scope = &val
-			case "CHANGED":
-				val := ScopeTypeChanged
-				advisory.CVSS3.Scope = &val
-			default:
-				log.Debugf("ERROR: Unsupported CVSS3 scope: '%s' - ignoring", cvss3.Scope)
-			}
-
-			switch cvss3.UserInteraction {
-			case "NONE":
-				val := UserInteractionTypeNone
-				advisory.CVSS3.UserInteraction = &val
-			case "REQUIRED":
-				val := UserInteractionTypeRequired
-				advisory.CVSS3.UserInteraction = &val
-			default:
-				log.Debugf("ERROR: Unsupported CVSS3 user interaction: '%s' - ignoring", cvss3.UserInteraction)
-			}
-
-			advisory.CVSS3.VectorString = cvss3.VectorString
 		}
 
+		advisory.Configurations = item.Configurations.Nodes
+
 		cveDir.Advisories = append(cveDir.Advisories, advisory)
 
 		vulnItems, err := item.VulnerableCPEs()
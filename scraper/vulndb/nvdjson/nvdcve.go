@@ -1,6 +1,8 @@
 // Package nvdjson decodes NVD JSON data feed.
 package nvdjson
 
+import "nanscraper/cvss"
+
 // NVD represents the National Vulnerability Database.
 type NVD struct {
 	CVEItems []CVEItem `json:"CVE_Items"`
@@ -14,26 +16,43 @@ type CVEItem struct {
 		Nodes          []ConfigurationNode `json:"nodes"`
 	} `json:"configurations"`
 	Impact struct {
+		BaseMetricV4 *struct {
+			CVSSV4 CVSSV4 `json:"cvssV4"`
+		} `json:"baseMetricV4,omitempty"`
 		BaseMetricV3 *struct {
 			CVSSV3              CVSSV3  `json:"cvssV3"`
 			ExploitabilityScore float64 `json:"exploitabilityScore"`
 			ImpactScore         float64 `json:"impactScore"`
 		} `json:"baseMetricV3"`
-		BaseMetricV2 *struct {
-			CVSSV2                  CVSSV2  `json:"cvssV2"`
-			Severity                string  `json:"severity"`
-			ExploitabilityScore     float64 `json:"exploitabilityScore"`
-			ImpactScore             float64 `json:"impactScore"`
-			ObtainAllPrivilege      bool    `json:"obtainAllPrivilege"`
-			ObtainUserPrivilege     bool    `json:"obtainUserPrivilege"`
-			ObtainOtherPrivilege    bool    `json:"obtainOtherPrivilege"`
-			UserInteractionRequired bool    `json:"userInteractionRequired"`
-		} `json:"baseMetricV2"`
+		BaseMetricV2 *BaseMetricV2 `json:"baseMetricV2"`
 	} `json:"impact"`
 	PublishedDate    string `json:"publishedDate"`
 	LastModifiedDate string `json:"lastModifiedDate"`
 }
 
+// CVSS4 returns the CVSS4 metrics of CVE item `i` or nil if not present. The base score and severity are
+// calculated from the vector string when not provided, as done by some CNAs.
+func (i CVEItem) CVSS4() (*CVSSV4, error) {
+	if i.Impact.BaseMetricV4 == nil {
+		return nil, nil
+	}
+
+	metrics := i.Impact.BaseMetricV4.CVSSV4
+	v, err := cvss.ParseCVSS4(metrics.VectorString)
+	if err != nil {
+		return nil, err
+	}
+	metrics.Version = v.Version()
+	metrics.VectorString = v.VectorString()
+	if metrics.BaseScore == 0 {
+		metrics.BaseScore = v.BaseScore()
+	}
+	if len(metrics.BaseSeverity) == 0 {
+		metrics.BaseSeverity = cvss.Severity(metrics.BaseScore)
+	}
+	return &metrics, nil
+}
+
 type VulnerableItem struct {
 	CPE23                 string
 	VersionStartIncluding *string
@@ -93,8 +112,11 @@ func (i CVEItem) References() []ReferenceItem {
 	return items
 }
 
+// ConfigurationNode represents a node of the vulnerable configurations of a CVE. The CPE matches and children
+// are combined by the operator (AND or OR), the result is inverted if negate is set.
 type ConfigurationNode struct {
 	Operator   string              `json:"operator"`
+	Negate     bool                `json:"negate,omitempty"`
 	Children   []ConfigurationNode `json:"children,omitempty"`
 	CPEMatches []CPEMatch          `json:"cpe_match,omitempty"`
 }
@@ -138,6 +160,7 @@ type CVE struct {
 	DataType    string `json:"data_type"`
 	DataFormat  string `json:"data_format"`
 	DataVersion string `json:"data_version"`
+	VulnStatus  string `json:"vulnStatus,omitempty"` // Newer formats only, e.g. Analyzed or Rejected.
 
 	Meta struct {
 		ID       string `json:"ID"`
@@ -180,6 +203,18 @@ func (c CVE) GetDescription() string {
 	return ""
 }
 
+// BaseMetricV2 represents the CVSSV2 impact of a given advisory.
+type BaseMetricV2 struct {
+	CVSSV2                  CVSSV2  `json:"cvssV2"`
+	Severity                string  `json:"severity"`
+	ExploitabilityScore     float64 `json:"exploitabilityScore"`
+	ImpactScore             float64 `json:"impactScore"`
+	ObtainAllPrivilege      bool    `json:"obtainAllPrivilege"`
+	ObtainUserPrivilege     bool    `json:"obtainUserPrivilege"`
+	ObtainOtherPrivilege    bool    `json:"obtainOtherPrivilege"`
+	UserInteractionRequired bool    `json:"userInteractionRequired"`
+}
+
 // CVSSV2 represents CVSSV2 scores for a given advisory.
 type CVSSV2 struct {
 	Version               string  `json:"version"`
@@ -209,6 +244,14 @@ type CVSSV3 struct {
 	BaseSeverity          string  `json:"baseSeverity"`
 }
 
+// CVSSV4 represents CVSSV4 scores for a given advisory.
+type CVSSV4 struct {
+	Version      string  `json:"version"`
+	VectorString string  `json:"vectorString"`
+	BaseScore    float64 `json:"baseScore"`
+	BaseSeverity string  `json:"baseSeverity"`
+}
+
 // CPEMatch represents a CPE match for a given advisory.
 type CPEMatch struct {
 	Vulnerable            bool    `json:"vulnerable"`
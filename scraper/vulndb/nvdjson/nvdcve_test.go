@@ -132,3 +132,34 @@ func TestNVDCVEJSONParse(t *testing.T) {
 func makeStringPtr(v string) *string {
 	return &v
 }
+
+func TestCVEItemCVSS4(t *testing.T) {
+	data := `{
+		"cve": {"CVE_data_meta": {"ID": "CVE-2024-0001"}},
+		"impact": {
+			"baseMetricV4": {
+				"cvssV4": {"vectorString": "CVSS:4.0/AV:N/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"}
+			}
+		}
+	}`
+	var item CVEItem
+	require.NoError(t, json.Unmarshal([]byte(data), &item))
+
+	metrics, err := item.CVSS4()
+	require.NoError(t, err)
+	require.Equal(t, &CVSSV4{
+		Version:      "4.0",
+		VectorString: "CVSS:4.0/AV:N/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+		BaseScore:    8.7,
+		BaseSeverity: "HIGH",
+	}, metrics)
+
+	item.Impact.BaseMetricV4.CVSSV4.VectorString = "CVSS:4.0/AV:N"
+	_, err = item.CVSS4()
+	require.Error(t, err)
+
+	item.Impact.BaseMetricV4 = nil
+	metrics, err = item.CVSS4()
+	require.NoError(t, err)
+	require.Nil(t, metrics)
+}
@@ -0,0 +1,240 @@
+package vulndb
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pkgVersion is a parsed distribution package version [epoch:]version[-release].
+type pkgVersion struct {
+	Epoch   int
+	Version string
+	Release string // RPM release or Debian revision, empty if none.
+}
+
+// parsePkgVersion parses the distribution package version `ver` as [epoch:]version[-release]. The release is
+// separated at the last hyphen.
+func parsePkgVersion(ver string) pkgVersion {
+	var v pkgVersion
+	ver = strings.TrimSpace(ver)
+	if idx := strings.Index(ver, ":"); idx >= 0 {
+		if epoch, err := strconv.Atoi(ver[:idx]); err == nil {
+			v.Epoch = epoch
+			ver = ver[idx+1:]
+		}
+	}
+	if idx := strings.LastIndex(ver, "-"); idx >= 0 {
+		v.Release = ver[idx+1:]
+		ver = ver[:idx]
+	}
+	v.Version = ver
+	return v
+}
+
+// compareEpochs compares the epochs `a` and `b` and returns -1, 0, or 1 if `a` is smaller, equal or larger.
+func compareEpochs(a, b int) int {
+	if a > b {
+		return 1
+	} else if a < b {
+		return -1
+	}
+	return 0
+}
+
+// VersionCompareRPM compares `targetVer` against `templateVer` as RPM versions epoch:version-release and returns
+// -1, 0, or 1 if the target version is smaller, equal or larger. The release is only compared if both versions
+// have one, e.g. 1.0 matches 1.0-1.el7.
+func VersionCompareRPM(templateVer, targetVer string) int {
+	verTpl := parsePkgVersion(templateVer)
+	verTgt := parsePkgVersion(targetVer)
+	if cmpVal := compareEpochs(verTgt.Epoch, verTpl.Epoch); cmpVal != 0 {
+		return cmpVal
+	}
+	if cmpVal := rpmVerCmp(verTgt.Version, verTpl.Version); cmpVal != 0 {
+		return cmpVal
+	}
+	if len(verTpl.Release) == 0 || len(verTgt.Release) == 0 {
+		return 0
+	}
+	return rpmVerCmp(verTgt.Release, verTpl.Release)
+}
+
+// rpmVerCmp compares the RPM version strings `a` and `b` per rpmvercmp: alphabetic and numeric segments are
+// compared in turn, numeric segments numerically and higher than alphabetic segments, ~ sorts before anything
+// (1.0~rc1 < 1.0) and ^ after the end of the version (1.0 < 1.0^git1 < 1.0.1).
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	isSeparator := func(r rune) bool {
+		return r > 0x7f || (!isASCIIDigit(byte(r)) && !isASCIILetter(byte(r)) && r != '~' && r != '^')
+	}
+	for len(a) > 0 || len(b) > 0 {
+		a = strings.TrimLeftFunc(a, isSeparator)
+		b = strings.TrimLeftFunc(b, isSeparator)
+
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if len(a) == 0 {
+				return -1
+			}
+			if len(b) == 0 {
+				return 1
+			}
+			if !strings.HasPrefix(a, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if len(a) == 0 || len(b) == 0 {
+			break
+		}
+
+		isNum := isASCIIDigit(a[0])
+		segA, segB := leadingSegment(a, isNum), leadingSegment(b, isNum)
+		a, b = a[len(segA):], b[len(segB):]
+		if len(segB) == 0 {
+			// Segments of different types: numeric is newer than alphabetic.
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+		if isNum {
+			if cmpVal := compareNumericStrings(segA, segB); cmpVal != 0 {
+				return cmpVal
+			}
+		} else if cmpVal := strings.Compare(segA, segB); cmpVal != 0 {
+			return cmpVal
+		}
+	}
+
+	// The version with characters left is newer.
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return -1
+	}
+	return 1
+}
+
+// leadingSegment returns the leading digits of `s` if `digits`, otherwise the leading letters.
+func leadingSegment(s string, digits bool) string {
+	i := 0
+	for i < len(s) && ((digits && isASCIIDigit(s[i])) || (!digits && isASCIILetter(s[i]))) {
+		i++
+	}
+	return s[:i]
+}
+
+// compareNumericStrings compares the digit strings `a` and `b` numerically, regardless of their length.
+func compareNumericStrings(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) > len(b) {
+		return 1
+	} else if len(a) < len(b) {
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// VersionCompareDpkg compares `targetVer` against `templateVer` as Debian package versions
+// epoch:upstream-revision and returns -1, 0, or 1 if the target version is smaller, equal or larger. The revision
+// is only compared if the template has one, e.g. 1.2 matches 1.2-1.
+func VersionCompareDpkg(templateVer, targetVer string) int {
+	verTpl := parsePkgVersion(templateVer)
+	verTgt := parsePkgVersion(targetVer)
+	if cmpVal := compareEpochs(verTgt.Epoch, verTpl.Epoch); cmpVal != 0 {
+		return cmpVal
+	}
+	if cmpVal := dpkgVerCmp(verTgt.Version, verTpl.Version); cmpVal != 0 {
+		return cmpVal
+	}
+	if len(verTpl.Release) == 0 {
+		return 0
+	}
+	return dpkgVerCmp(verTgt.Release, verTpl.Release)
+}
+
+// dpkgVerCmp compares the Debian version strings `a` and `b` per dpkg verrevcmp: non-digit parts are compared
+// with letters before non-letters and ~ before anything including the end (1.0~beta < 1.0), digit parts
+// numerically.
+func dpkgVerCmp(a, b string) int {
+	order := func(s string, i int) int {
+		switch {
+		case i >= len(s), isASCIIDigit(s[i]):
+			return 0
+		case isASCIILetter(s[i]):
+			return int(s[i])
+		case s[i] == '~':
+			return -1
+		}
+		return int(s[i]) + 256
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isASCIIDigit(a[i])) || (j < len(b) && !isASCIIDigit(b[j])) {
+			ac, bc := order(a, i), order(b, j)
+			if ac != bc {
+				if ac > bc {
+					return 1
+				}
+				return -1
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && isASCIIDigit(a[i]) && j < len(b) && isASCIIDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isASCIIDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isASCIIDigit(b[j]) {
+			return -1
+		}
+		if firstDiff > 0 {
+			return 1
+		} else if firstDiff < 0 {
+			return -1
+		}
+	}
+	return 0
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,50 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionCompareRPM(t *testing.T) {
+	// Ascending pairs: the second version is higher than the first.
+	for _, pair := range [][2]string{
+		{"1.0", "1.0.1"},
+		{"1.0~rc1", "1.0"},
+		{"1.0~rc1", "1.0~rc2"},
+		{"1.0", "1.0^git1"},
+		{"1.0^git1", "1.0.1"},
+		{"1.0a", "1.0.1"},
+		{"1.9", "1.10"},
+		{"2.0", "1:1.0"},
+		{"1.0.2k-19.el7", "1.0.2k-21.el7"},
+		{"1.0.2k-19.el7", "1.0.2l-1.el7"},
+	} {
+		require.Equal(t, 1, VersionCompareRPM(pair[0], pair[1]), "%s < %s", pair[0], pair[1])
+		require.Equal(t, -1, VersionCompareRPM(pair[1], pair[0]), "%s > %s", pair[1], pair[0])
+	}
+	require.Equal(t, 0, VersionCompareRPM("1.0", "1.0-1.el7"))
+	require.Equal(t, 0, VersionCompareRPM("1.01", "1.1"))
+	require.Equal(t, 0, VersionCompareRPM("0:1.0", "1.0"))
+}
+
+func TestVersionCompareDpkg(t *testing.T) {
+	for _, pair := range [][2]string{
+		{"1.0", "1.0.1"},
+		{"1.0~beta", "1.0"},
+		{"1.0~~", "1.0~"},
+		{"1.0", "1.0a"},
+		{"1.0a", "1.0+"},
+		{"1.9", "1.10"},
+		{"2.0", "1:1.0"},
+		{"1.1.1n-0+deb11u3", "1.1.1n-0+deb11u4"},
+		{"1.1.1n-0+deb11u3", "1.1.1n-1"},
+	} {
+		require.Equal(t, 1, VersionCompareDpkg(pair[0], pair[1]), "%s < %s", pair[0], pair[1])
+		require.Equal(t, -1, VersionCompareDpkg(pair[1], pair[0]), "%s > %s", pair[1], pair[0])
+	}
+	require.Equal(t, 0, VersionCompareDpkg("1.2", "1.2-1"))
+	require.Equal(t, -1, VersionCompareDpkg("1.2-1", "1.2"))
+	require.Equal(t, 0, VersionCompareDpkg("1.0-1", "1.00-1"))
+	require.Equal(t, 0, VersionCompareDpkg("0:1.0", "1.0"))
+}
@@ -0,0 +1,243 @@
+package vulndb
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"nanscraper/common"
+)
+
+// Platform is a supported platform of the platform vulnerabilities, e.g. CentOS Linux 7.
+type Platform struct {
+	ID          int64  `xorm:"id"`
+	DisplayName string `xorm:"display_name"`
+}
+
+// ListPlatforms returns the supported platforms ordered by ID.
+func ListPlatforms(session *VulnDBSession) ([]Platform, error) {
+	var ret []Platform
+	err := session.Sql(`SELECT id, display_name FROM platforms ORDER BY id`).Find(&ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// PlatformVulnerabilityFilters configures the results of ListPlatformVulnerabilities. The zero value returns all
+// vulnerabilities of the platform ordered by the latest CVSS base score (see SortByBaseScore).
+type PlatformVulnerabilityFilters struct {
+	Sources         []string     // Only vulnerabilities attributed by the sources (Source*), empty for any.
+	MinSeverity     int          // Minimum CVSS3 severity (SeverityType*) of the latest base score, 0 for any.
+	PublishedAfter  int64        // Only advisories disclosed (see NVDCVEAdvisory.DisclosedAt) at or after (unix time), 0 for any.
+	PublishedBefore int64        // Only advisories disclosed (see NVDCVEAdvisory.DisclosedAt) before (unix time), 0 for any.
+	SortBy          MatchSortKey // Order of the advisories, applied before Offset and Limit.
+	Limit           int          // Maximum number of advisories, 0 for unlimited.
+	Offset          int          // Number of advisories to skip, for pagination with Limit.
+}
+
+// PlatformVulnerability is a CVE advisory of a platform with the sources attributing it to the platform.
+type PlatformVulnerability struct {
+	Advisory NVDCVEAdvisory
+	Sources  []string // E.g. cpe, msrcAPI, redhat_oval, sorted.
+}
+
+// ListPlatformVulnerabilitiesResults is a page of the vulnerabilities of a platform.
+type ListPlatformVulnerabilitiesResults struct {
+	Total           int // Number of vulnerabilities matching the filters, before Offset and Limit.
+	Vulnerabilities []PlatformVulnerability
+}
+
+// ListPlatformVulnerabilities returns the CVE advisories of platform `platformID` (see ListPlatforms) from the
+// platform vulnerabilities according to `filters`, with the sources attributing each advisory to the platform.
+// Rejected CVEs are excluded unless included by the session (see SetIncludeRejected) and the CVSS scores follow
+// the CVSS policy of the session (see SetCVSSPolicy). The filters, order and page are applied by the query.
+func ListPlatformVulnerabilities(session *VulnDBSession, platformID int64, filters PlatformVulnerabilityFilters) (*ListPlatformVulnerabilitiesResults, error) {
+	whereSQL := "id IN (SELECT vulnerability_id FROM platform_vulnerabilities WHERE platform_id = ?"
+	params := []interface{}{platformID}
+	if len(filters.Sources) > 0 {
+		whereSQL += " AND " + common.MakeInSql("source", len(filters.Sources))
+		for _, source := range filters.Sources {
+			params = append(params, source)
+		}
+	}
+	whereSQL += ")" + session.rejectedFilterSQL()
+	if filters.PublishedAfter != 0 {
+		whereSQL += " AND " + disclosedAtSQL + " >= ?"
+		params = append(params, filters.PublishedAfter)
+	}
+	if filters.PublishedBefore != 0 {
+		whereSQL += " AND " + disclosedAtSQL + " < ?"
+		params = append(params, filters.PublishedBefore)
+	}
+	scoreSQL, scoreParams := session.baseScoreSQL("nvd_cve_advisories")
+	if filters.MinSeverity > SeverityTypeNone {
+		whereSQL += " AND " + severitySQL(scoreSQL, filters.MinSeverity)
+		params = append(params, scoreParams...)
+	}
+
+	var counts []int64
+	err := session.Sql("SELECT COUNT(*) FROM nvd_cve_advisories WHERE "+whereSQL, params...).Find(&counts)
+	if err != nil {
+		return nil, err
+	}
+	ret := ListPlatformVulnerabilitiesResults{}
+	if len(counts) > 0 {
+		ret.Total = int(counts[0])
+	}
+
+	var orderSQL string
+	switch filters.SortBy {
+	case SortByBaseScore:
+		orderSQL = scoreSQL + " DESC, "
+		params = append(params, scoreParams...)
+	case SortByCVSS2:
+		orderSQL = "COALESCE(cvss2_base_score, 0) DESC, "
+	case SortByPublished:
+		orderSQL = "published_at DESC, "
+	case SortByDisclosed:
+		orderSQL = disclosedAtSQL + ", "
+	}
+	limit := -1
+	if filters.Limit > 0 {
+		limit = filters.Limit
+	}
+	params = append(params, limit, filters.Offset)
+
+	var advisories []NVDCVEAdvisory
+	err = session.Sql("SELECT * FROM nvd_cve_advisories WHERE "+whereSQL+" ORDER BY "+orderSQL+"cve_id LIMIT ? OFFSET ?",
+		params...).Find(&advisories)
+	if err != nil {
+		return nil, err
+	}
+	err = applyCVSSPolicy(session, advisories)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sources of the advisories of the page.
+	advisoryIDs := make([]int64, len(advisories))
+	for i, advisory := range advisories {
+		advisoryIDs[i] = advisory.Id
+	}
+	sources := map[int64][]string{}
+	err = common.ProcessChunks(advisoryIDs, 900, func(start, end int) error {
+		whereSQL := "platform_id = ? AND " + common.MakeInSql("vulnerability_id", end-start)
+		params := []interface{}{platformID}
+		for _, id := range advisoryIDs[start:end] {
+			params = append(params, id)
+		}
+		if len(filters.Sources) > 0 {
+			whereSQL += " AND " + common.MakeInSql("source", len(filters.Sources))
+			for _, source := range filters.Sources {
+				params = append(params, source)
+			}
+		}
+		var platformVulns []platformVulnerabilities
+		err := session.Where(whereSQL, params...).Find(&platformVulns)
+		if err != nil {
+			return err
+		}
+		for _, pv := range platformVulns {
+			sources[pv.VulnerabilityId] = appendUnique(sources[pv.VulnerabilityId], pv.Source)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, advisory := range advisories {
+		ret.Vulnerabilities = append(ret.Vulnerabilities, PlatformVulnerability{
+			Advisory: advisory,
+			Sources:  sources[advisory.Id],
+		})
+	}
+	return &ret, nil
+}
+
+// severitySQL returns the SQL condition of the base score `scoreSQL` having at least severity `minSeverity`
+// (SeverityType*), as filterBySeverity.
+func severitySQL(scoreSQL string, minSeverity int) string {
+	switch {
+	case minSeverity > SeverityTypeCritical:
+		return "0"
+	case minSeverity > SeverityTypeHigh:
+		return scoreSQL + " >= 9.0"
+	case minSeverity > SeverityTypeMedium:
+		return scoreSQL + " >= 7.0"
+	case minSeverity > SeverityTypeLow:
+		return scoreSQL + " >= 4.0"
+	case minSeverity > SeverityTypeNone:
+		return scoreSQL + " > 0"
+	}
+	return "1"
+}
+
+// CVEPlatform is a platform affected by a CVE with the sources attributing the CVE to the platform.
+type CVEPlatform struct {
+	PlatformID  int64
+	DisplayName string
+	Sources     []string // E.g. cpe, msrcAPI, redhat_oval, sorted.
+}
+
+// cvePlatformRow is a platform vulnerability of a CVE by source.
+type cvePlatformRow struct {
+	PlatformID  int64  `xorm:"platform_id"`
+	DisplayName string `xorm:"display_name"`
+	Source      string `xorm:"source"`
+}
+
+// ListPlatformsForCVE returns the platforms affected by CVE `cve` (e.g. CVE-2021-44228) according to the platform
+// vulnerabilities, ordered by platform ID, with the sources attributing the CVE to each platform. Rejected CVEs
+// have no platforms unless included by the session (see SetIncludeRejected).
+func ListPlatformsForCVE(session *VulnDBSession, cve string) ([]CVEPlatform, error) {
+	cve = strings.ToUpper(strings.TrimSpace(cve))
+	if len(cve) == 0 {
+		return nil, errors.New("missing CVE ID")
+	}
+
+	sql := `
+SELECT
+p.id AS platform_id,
+p.display_name AS display_name,
+pv.source AS source
+FROM platform_vulnerabilities pv
+INNER JOIN platforms p
+ON p.id = pv.platform_id
+INNER JOIN nvd_cve_advisories nca
+ON nca.id = pv.vulnerability_id
+WHERE nca.cve_id = ?` + session.rejectedFilterSQL() + `
+ORDER BY p.id
+`
+	var rows []cvePlatformRow
+	err := session.Sql(sql, cve).Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []CVEPlatform
+	for _, row := range rows {
+		if len(ret) == 0 || ret[len(ret)-1].PlatformID != row.PlatformID {
+			ret = append(ret, CVEPlatform{PlatformID: row.PlatformID, DisplayName: row.DisplayName})
+		}
+		platform := &ret[len(ret)-1]
+		platform.Sources = appendUnique(platform.Sources, row.Source)
+	}
+	return ret, nil
+}
+
+// appendUnique returns the sorted `values` with `value` added if not empty or already present.
+func appendUnique(values []string, value string) []string {
+	if len(value) == 0 {
+		return values
+	}
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	values = append(values, value)
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,194 @@
+package vulndb
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// PlatformDefinitionsVersion is the supported format version of the platform definitions file.
+const PlatformDefinitionsVersion = 1
+
+// platformProductSources are the sources with product names mapped to platforms by the platform definitions.
+var platformProductSources = map[string]bool{
+	SourceMSRC:       true,
+	SourceCSAF:       true,
+	SourceRedhatOVAL: true,
+}
+
+// platformDefinitions represents the platform definitions from XML file, e.g.
+// <platforms version="1"><platform id="7" name="Microsoft Windows 10"><cpe>:o:microsoft:windows_10:</cpe>
+// <product source="msrcAPI">^Windows 10\b</product></platform></platforms>.
+type platformDefinitions struct {
+	Version   int                  `xml:"version,attr"`
+	Platforms []platformDefinition `xml:"platform"`
+}
+
+// platformDefinition is a platform with the regular expressions mapping the CPEs (CPE 2.3 or URI binding) and
+// the product names by source to the platform.
+type platformDefinition struct {
+	ID              int64                    `xml:"id,attr"`
+	DisplayName     string                   `xml:"name,attr"`
+	CPEPatterns     []string                 `xml:"cpe"`
+	ProductPatterns []platformProductPattern `xml:"product"`
+}
+
+type platformProductPattern struct {
+	Source  string `xml:"source,attr"`
+	Pattern string `xml:",chardata"`
+}
+
+// loadPlatformDefinitions loads the platform definitions from XML file and returns as platformDefinitions.
+func loadPlatformDefinitions(inputPath string) (*platformDefinitions, error) {
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var defs platformDefinitions
+
+	decoder := xml.NewDecoder(f)
+	err = decoder.Decode(&defs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &defs, err
+}
+
+// Validate returns an error if the platform definitions `defs` are not of the supported format version, or a
+// platform has no positive unique ID, no unique name, no patterns, an invalid regular expression or a product
+// pattern of an unknown source.
+func (defs platformDefinitions) Validate() error {
+	if defs.Version != PlatformDefinitionsVersion {
+		return fmt.Errorf("unsupported platform definitions version %d", defs.Version)
+	}
+	if len(defs.Platforms) == 0 {
+		return errors.New("no platforms defined")
+	}
+
+	ids := map[int64]bool{}
+	names := map[string]bool{}
+	for _, def := range defs.Platforms {
+		if def.ID <= 0 {
+			return fmt.Errorf("platform '%s': invalid id %d", def.DisplayName, def.ID)
+		}
+		if ids[def.ID] {
+			return fmt.Errorf("platform %d: duplicate id", def.ID)
+		}
+		ids[def.ID] = true
+
+		name := strings.TrimSpace(def.DisplayName)
+		if len(name) == 0 {
+			return fmt.Errorf("platform %d: missing name", def.ID)
+		}
+		if names[name] {
+			return fmt.Errorf("platform %d: duplicate name '%s'", def.ID, name)
+		}
+		names[name] = true
+
+		if len(def.CPEPatterns) == 0 && len(def.ProductPatterns) == 0 {
+			return fmt.Errorf("platform %d: no cpe or product patterns", def.ID)
+		}
+		for _, rule := range def.rules() {
+			if rule.Source != SourceCPE && !platformProductSources[rule.Source] {
+				return fmt.Errorf("platform %d: unknown product source '%s'", def.ID, rule.Source)
+			}
+			if len(rule.Pattern) == 0 {
+				return fmt.Errorf("platform %d: empty %s pattern", def.ID, rule.Source)
+			}
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("platform %d: invalid %s pattern: %v", def.ID, rule.Source, err)
+			}
+		}
+	}
+	return nil
+}
+
+// rules returns the platform rules of the CPE patterns (source cpe) and product patterns of platform `def`.
+func (def platformDefinition) rules() []platformRule {
+	var ret []platformRule
+	for _, pattern := range def.CPEPatterns {
+		ret = append(ret, platformRule{PlatformID: def.ID, Source: SourceCPE, Pattern: strings.TrimSpace(pattern)})
+	}
+	for _, product := range def.ProductPatterns {
+		ret = append(ret, platformRule{
+			PlatformID: def.ID,
+			Source:     strings.TrimSpace(product.Source),
+			Pattern:    strings.TrimSpace(product.Pattern),
+		})
+	}
+	return ret
+}
+
+// processPlatforms validates and inserts the platform definitions of the XML file `platformsPath` into vulndb,
+// e.g. data/platforms.xml shipped with nanscraper.
+func processPlatforms(sessionw *VulnDBSession, platformsPath string) error {
+	defs, err := loadPlatformDefinitions(platformsPath)
+	if err != nil {
+		return err
+	}
+	if err := defs.Validate(); err != nil {
+		return fmt.Errorf("invalid platform definitions: %v", err)
+	}
+
+	for _, def := range defs.Platforms {
+		platform := platforms{ID: def.ID, DisplayName: strings.TrimSpace(def.DisplayName)}
+		err := sessionw.Insert(&platform)
+		if err != nil {
+			return err
+		}
+		for _, rule := range def.rules() {
+			rule := rule
+			err = sessionw.Insert(&rule)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadPlatformMappingRules loads the platform rules of `source` (SourceCPE for the CPE patterns, otherwise the
+// product patterns of the source) as regular expressions by platform id.
+func loadPlatformMappingRules(sessionw *VulnDBSession, source string) (map[int64][]*regexp.Regexp, error) {
+	var rules []platformRule
+	if err := sessionw.Where("source = ?", source).Find(&rules); err != nil {
+		return nil, err
+	}
+	platformMappingRules := make(map[int64][]*regexp.Regexp)
+	for _, rule := range rules {
+		exp, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		platformMappingRules[rule.PlatformID] = append(platformMappingRules[rule.PlatformID], exp)
+	}
+	return platformMappingRules, nil
+}
+
+// isPlatformMatchRulePassed returns true if `value` matches any of the platform `rules`.
+func isPlatformMatchRulePassed(rules []*regexp.Regexp, value string) bool {
+	for _, rule := range rules {
+		if rule.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPlatform returns the lowest id of the platforms with rules of `platformMappingRules` matching `value`,
+// false if none.
+func findPlatform(platformMappingRules map[int64][]*regexp.Regexp, value string) (int64, bool) {
+	var ret int64
+	for platformID, rules := range platformMappingRules {
+		if (ret == 0 || platformID < ret) && isPlatformMatchRulePassed(rules, value) {
+			ret = platformID
+		}
+	}
+	return ret, ret != 0
+}
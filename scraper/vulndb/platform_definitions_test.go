@@ -0,0 +1,96 @@
+package vulndb
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPlatformDefinitions(t *testing.T) {
+	defs, err := loadPlatformDefinitions("testdata/platforms/platforms.xml")
+	require.NoError(t, err)
+	require.NoError(t, defs.Validate())
+	require.Len(t, defs.Platforms, 3)
+	require.Equal(t, []platformRule{
+		{PlatformID: 22, Source: SourceCPE, Pattern: `:o:redhat:enterprise_linux:9\.0:`},
+		{PlatformID: 22, Source: SourceRedhatOVAL, Pattern: `^Red Hat Enterprise Linux 9$`},
+		{PlatformID: 22, Source: SourceCSAF, Pattern: `^Red Hat Enterprise Linux (?:\w+ \(v\. )?9\b`},
+	}, defs.Platforms[2].rules())
+}
+
+func TestShippedPlatformDefinitions(t *testing.T) {
+	defs, err := loadPlatformDefinitions("../data/platforms.xml")
+	require.NoError(t, err)
+	require.NoError(t, defs.Validate())
+
+	_, err = loadPlatformDefinitions("testdata/platforms/missing.xml")
+	require.Error(t, err)
+}
+
+func TestValidatePlatformDefinitions(t *testing.T) {
+
+	valid := platformDefinition{ID: 1, DisplayName: "CentOS Linux 6", CPEPatterns: []string{`:o:centos:centos:6\.0:`}}
+	tests := []struct {
+		name string
+		defs platformDefinitions
+	}{
+		{"version", platformDefinitions{Version: 2, Platforms: []platformDefinition{valid}}},
+		{"no platforms", platformDefinitions{Version: PlatformDefinitionsVersion}},
+		{"duplicate id", platformDefinitions{Version: PlatformDefinitionsVersion, Platforms: []platformDefinition{
+			valid, {ID: 1, DisplayName: "CentOS Linux 7", CPEPatterns: []string{`:o:centos:centos:7\.0:`}},
+		}}},
+		{"missing name", platformDefinitions{Version: PlatformDefinitionsVersion, Platforms: []platformDefinition{
+			{ID: 2, CPEPatterns: []string{`:o:centos:centos:7\.0:`}},
+		}}},
+		{"no patterns", platformDefinitions{Version: PlatformDefinitionsVersion, Platforms: []platformDefinition{
+			{ID: 2, DisplayName: "CentOS Linux 7"},
+		}}},
+		{"invalid pattern", platformDefinitions{Version: PlatformDefinitionsVersion, Platforms: []platformDefinition{
+			{ID: 2, DisplayName: "CentOS Linux 7", CPEPatterns: []string{`:o:centos:centos:(7`}},
+		}}},
+		{"unknown source", platformDefinitions{Version: PlatformDefinitionsVersion, Platforms: []platformDefinition{
+			{ID: 2, DisplayName: "Windows 10", ProductPatterns: []platformProductPattern{{"msrc", `^Windows 10\b`}}},
+		}}},
+	}
+	for _, test := range tests {
+		require.Error(t, test.defs.Validate(), test.name)
+	}
+}
+
+func TestShippedPlatformRules(t *testing.T) {
+	defs, err := loadPlatformDefinitions("../data/platforms.xml")
+	require.NoError(t, err)
+	rulesBySource := map[string]map[int64][]*regexp.Regexp{}
+	for _, def := range defs.Platforms {
+		for _, rule := range def.rules() {
+			if rulesBySource[rule.Source] == nil {
+				rulesBySource[rule.Source] = map[int64][]*regexp.Regexp{}
+			}
+			rulesBySource[rule.Source][rule.PlatformID] = append(rulesBySource[rule.Source][rule.PlatformID],
+				regexp.MustCompile(rule.Pattern))
+		}
+	}
+
+	tests := []struct {
+		source   string
+		value    string
+		expected int64
+	}{
+		{SourceCPE, "cpe:2.3:o:centos:centos:6.0:*:*:*:*:*:*:*", 1},
+		{SourceCPE, "cpe:2.3:o:centos:centos:7.0:*:*:*:*:*:*:*", 2},
+		{SourceCPE, "cpe:2.3:o:centos:centos:8.0:*:*:*:*:*:*:*", 3},
+		{SourceCPE, "cpe:2.3:o:canonical:ubuntu_linux:18.04:*:*:*:lts:*:*:*", 19},
+		{SourceCPE, "cpe:2.3:o:canonical:ubuntu_linux:18.10:*:*:*:*:*:*:*", 0},
+		{SourceMSRC, "Windows Server 2012 (Server Core installation)", 9},
+		{SourceMSRC, "Windows Server 2012 R2 (Server Core installation)", 10},
+		{SourceMSRC, "Windows 10 Version 1809 for x64-based Systems", 7},
+		{SourceRedhatOVAL, "Red Hat Enterprise Linux 7", 16},
+		{SourceCSAF, "Red Hat Enterprise Linux BaseOS (v. 8)", 17},
+		{SourceCSAF, "cpe:2.3:o:centos:centos:7.0:*:*:*:*:*:*:*", 0},
+	}
+	for _, test := range tests {
+		platformID, _ := findPlatform(rulesBySource[test.source], test.value)
+		require.Equal(t, test.expected, platformID, test.value)
+	}
+}
@@ -0,0 +1,232 @@
+package vulndb
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// PackageURL represents the components of a package URL (purl) as specified by
+// https://github.com/package-url/purl-spec, e.g. pkg:rpm/redhat/openssl@1.0.2k-19.el7?arch=x86_64.
+type PackageURL struct {
+	Type       string            // Package type, e.g. rpm, deb, npm, maven, generic.
+	Namespace  string            // Type specific namespace, e.g. redhat, @angular, org.apache.logging.log4j.
+	Name       string            // Package name.
+	Version    string            // Package version as used by the type, e.g. 1:1.0.2k-19.el7.
+	Qualifiers map[string]string // Extra qualifying data, e.g. arch=x86_64, distro=rhel-7.
+	Subpath    string            // Path within the package.
+}
+
+// ParsePURL parses the package URL `purl`. The namespace and name are normalized as the spec requires for the
+// known types, e.g. lowercase npm and pypi names.
+func ParsePURL(purl string) (PackageURL, error) {
+	var p PackageURL
+
+	if len(purl) < 4 || !strings.EqualFold(purl[:4], "pkg:") {
+		return p, errors.New("invalid purl scheme")
+	}
+	remainder := strings.TrimLeft(purl[4:], "/")
+
+	if idx := strings.LastIndex(remainder, "#"); idx >= 0 {
+		subpath, err := url.PathUnescape(strings.Trim(remainder[idx+1:], "/"))
+		if err != nil {
+			return p, err
+		}
+		p.Subpath = subpath
+		remainder = remainder[:idx]
+	}
+
+	if idx := strings.LastIndex(remainder, "?"); idx >= 0 {
+		p.Qualifiers = map[string]string{}
+		for _, pair := range strings.Split(remainder[idx+1:], "&") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 || len(kv[1]) == 0 {
+				continue
+			}
+			value, err := url.PathUnescape(kv[1])
+			if err != nil {
+				return p, err
+			}
+			p.Qualifiers[strings.ToLower(kv[0])] = value
+		}
+		remainder = remainder[:idx]
+	}
+
+	if idx := strings.LastIndex(remainder, "@"); idx >= 0 {
+		version, err := url.PathUnescape(remainder[idx+1:])
+		if err != nil {
+			return p, err
+		}
+		p.Version = version
+		remainder = remainder[:idx]
+	}
+
+	parts := strings.Split(strings.Trim(remainder, "/"), "/")
+	if len(parts) < 2 || len(parts[0]) == 0 {
+		return p, errors.New("invalid purl: missing type or name")
+	}
+	p.Type = strings.ToLower(parts[0])
+
+	var segments []string
+	for _, part := range parts[1:] {
+		if len(part) == 0 {
+			continue
+		}
+		segment, err := url.PathUnescape(part)
+		if err != nil {
+			return p, err
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return p, errors.New("invalid purl: missing name")
+	}
+	p.Name = segments[len(segments)-1]
+	p.Namespace = strings.Join(segments[:len(segments)-1], "/")
+
+	switch p.Type {
+	case "bitbucket", "github", "npm":
+		p.Namespace = strings.ToLower(p.Namespace)
+		p.Name = strings.ToLower(p.Name)
+	case "pypi":
+		p.Name = strings.Replace(strings.ToLower(p.Name), "_", "-", -1)
+	case "apk", "deb", "rpm":
+		p.Namespace = strings.ToLower(p.Namespace)
+	}
+
+	return p, nil
+}
+
+// isDistroPackage returns true if the purl is an OS distribution package, where the namespace is the distribution
+// (e.g. redhat, debian) rather than the vendor.
+func (p PackageURL) isDistroPackage() bool {
+	switch p.Type {
+	case "alpm", "apk", "deb", "ebuild", "rpm":
+		return true
+	}
+	return false
+}
+
+// vendorProduct returns the vendor and product names guessed from the purl for looking up products in vulndb
+// when there is no purl alias.
+func (p PackageURL) vendorProduct() (vendor, product string) {
+	product = p.Name
+	switch {
+	case p.isDistroPackage() || len(p.Namespace) == 0:
+		vendor = p.Name
+	case p.Type == "maven":
+		// Group ids are reversed domain names, e.g. org.apache.logging.log4j.
+		labels := strings.Split(p.Namespace, ".")
+		vendor = labels[0]
+		if len(labels) > 1 {
+			vendor = labels[1]
+		}
+	default:
+		vendor = strings.TrimPrefix(p.Namespace, "@")
+		if idx := strings.LastIndex(vendor, "/"); idx >= 0 {
+			vendor = vendor[idx+1:]
+		}
+	}
+	return vendor, product
+}
+
+var (
+	reDebianRevision = regexp.MustCompile(`-[^-]*$`)
+	reAlpineRelease  = regexp.MustCompile(`-r\d+$`)
+)
+
+// upstreamVersion returns the upstream version of the purl for comparing against the NVD based version ranges
+// with the comparator of the purl (see versionScheme). Distribution packages have the epoch and the distribution
+// release stripped, e.g. rpm 1:1.0.2k-19.el7 -> 1.0.2k, and Go module versions the v prefix.
+func (p PackageURL) upstreamVersion() string {
+	version := p.Version
+	switch p.Type {
+	case "rpm", "deb", "alpm":
+		if idx := strings.Index(version, ":"); idx >= 0 {
+			version = version[idx+1:]
+		}
+		version = reDebianRevision.ReplaceAllString(version, "")
+	case "apk":
+		version = reAlpineRelease.ReplaceAllString(version, "")
+	case "golang":
+		version = strings.TrimPrefix(version, "v")
+	}
+	return version
+}
+
+// versionScheme returns the version scheme of the distribution package versions of the purl type, empty for the
+// version scheme assignment of the resolved product. The pacman and apk versions are ordered as rpm versions.
+func (p PackageURL) versionScheme() string {
+	switch p.Type {
+	case "rpm", "alpm", "apk":
+		return VersionSchemeRPM
+	case "deb":
+		return VersionSchemeDpkg
+	}
+	return ""
+}
+
+// SoftwareItemFromPURL returns the software item to match for the package URL `purl`. The product is resolved
+// via the purl aliases (see CreateDBParams.PURLAliasesPath), falling back to vendor and product names guessed
+// from the purl, and the version is normalized to the upstream version, compared by the comparator of the
+// distribution package versions if any.
+func SoftwareItemFromPURL(session *VulnDBSession, purl string) (SoftwareItem, error) {
+	p, err := ParsePURL(purl)
+	if err != nil {
+		return SoftwareItem{}, err
+	}
+
+	item := SoftwareItem{
+		Systype:       "a",
+		Version:       p.upstreamVersion(),
+		VersionScheme: p.versionScheme(),
+	}
+	item.Publisher, item.Title = p.vendorProduct()
+
+	// The purl aliases are only present in vulndbs built with them.
+	hasAliases, err := session.IsTableExist(purlAlias{})
+	if err != nil {
+		return SoftwareItem{}, err
+	}
+	if !hasAliases {
+		return item, nil
+	}
+	var alias purlAlias
+	has, err := session.Where("purl_type = ? AND (purl_namespace = '' OR purl_namespace = ?) AND ? GLOB purl_name",
+		p.Type, p.Namespace, p.Name).OrderBy("purl_namespace DESC").Get(&alias)
+	if err != nil {
+		return SoftwareItem{}, err
+	}
+	if has {
+		product, err := session.GetProductById(alias.ProductID)
+		if err != nil {
+			return SoftwareItem{}, err
+		}
+		if product != nil {
+			vendor, err := session.GetVendorById(product.VendorID)
+			if err != nil {
+				return SoftwareItem{}, err
+			}
+			if vendor != nil {
+				item.Publisher = vendor.Name
+				item.Title = product.ProductName
+			}
+		}
+	}
+
+	return item, nil
+}
+
+// MatchPURL matches CVEs for the package URL `purl` as MatchCVEs, e.g. for SBOM components.
+func MatchPURL(session *VulnDBSession, purl string) ([]CVEMatch, error) {
+	item, err := SoftwareItemFromPURL(session, purl)
+	if err != nil {
+		return nil, err
+	}
+	result := MatchInventory(session, []SoftwareItem{item})[0]
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Matches, nil
+}
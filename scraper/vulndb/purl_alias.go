@@ -0,0 +1,97 @@
+package vulndb
+
+import (
+	"encoding/xml"
+	"os"
+)
+
+// xmlPURLAliases represents package URL aliases of vulndb products, e.g.
+// <product vendor="openssl" product="openssl"><purl type="rpm" namespace="redhat" name="openssl*"/></product>.
+type xmlPURLAliases struct {
+	Products []xmlPURLAliasEntry `xml:"product"`
+}
+
+type xmlPURLAliasEntry struct {
+	Vendor  string         `xml:"vendor,attr"`
+	Product string         `xml:"product,attr"`
+	PURLs   []xmlPURLAlias `xml:"purl"`
+}
+
+type xmlPURLAlias struct {
+	Type      string `xml:"type,attr"`
+	Namespace string `xml:"namespace,attr"` // Empty for any namespace.
+	Name      string `xml:"name,attr"`      // Name glob.
+}
+
+// loadPURLAliases loads the purl aliases from XML file and returns as xmlPURLAliases.
+func loadPURLAliases(inputPath string) (*xmlPURLAliases, error) {
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var aliases xmlPURLAliases
+
+	decoder := xml.NewDecoder(f)
+	err = decoder.Decode(&aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aliases, err
+}
+
+// processPURLAliases loads purl aliases from XML and puts into vulndb. Aliases of products not present in vulndb
+// are skipped.
+func processPURLAliases(sessionw *VulnDBSession, purlAliasesPath string) error {
+	if len(purlAliasesPath) == 0 {
+		return nil
+	}
+
+	aliases, err := loadPURLAliases(purlAliasesPath)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range aliases.Products {
+		var vendor VulndbVendor
+		has, err := sessionw.Where("name = ?", product.Vendor).Get(&vendor)
+		if err != nil {
+			return err
+		}
+		if !has {
+			log.Debugf("purl aliases: vendor '%s' not present - skipping", product.Vendor)
+			continue
+		}
+
+		var vdbProduct vulndbProduct
+		has, err = sessionw.Where(`vendor_id = ? AND product_name = ?`, vendor.ID, product.Product).Get(&vdbProduct)
+		if err != nil {
+			return err
+		}
+		if !has {
+			log.Debugf("purl aliases: product '%s/%s' not present - skipping", product.Vendor, product.Product)
+			continue
+		}
+
+		for _, p := range product.PURLs {
+			if len(p.Type) == 0 || len(p.Name) == 0 {
+				log.Debugf("purl aliases: missing type or name for '%s/%s' - skipping", product.Vendor, product.Product)
+				continue
+			}
+			alias := purlAlias{
+				ProductID:     vdbProduct.ID,
+				PURLType:      p.Type,
+				PURLNamespace: p.Namespace,
+				PURLName:      p.Name,
+			}
+			err := sessionw.Insert(&alias)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
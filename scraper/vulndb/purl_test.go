@@ -0,0 +1,79 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePURL(t *testing.T) {
+	p, err := ParsePURL("pkg:rpm/RedHat/openssl@1:1.0.2k-19.el7?arch=x86_64&distro=rhel-7#docs")
+	require.NoError(t, err)
+	require.Equal(t, PackageURL{
+		Type:       "rpm",
+		Namespace:  "redhat",
+		Name:       "openssl",
+		Version:    "1:1.0.2k-19.el7",
+		Qualifiers: map[string]string{"arch": "x86_64", "distro": "rhel-7"},
+		Subpath:    "docs",
+	}, p)
+
+	p, err = ParsePURL("pkg:npm/%40Angular/Core@12.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "@angular", p.Namespace)
+	require.Equal(t, "core", p.Name)
+	require.Equal(t, "12.0.1", p.Version)
+
+	p, err = ParsePURL("pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1")
+	require.NoError(t, err)
+	require.Equal(t, "org.apache.logging.log4j", p.Namespace)
+	require.Equal(t, "log4j-core", p.Name)
+
+	p, err = ParsePURL("pkg:generic/openssl@1.1.1k")
+	require.NoError(t, err)
+	require.Equal(t, "", p.Namespace)
+	require.Equal(t, "openssl", p.Name)
+
+	for _, invalid := range []string{"", "openssl", "pkg:", "pkg:rpm", "pkg:rpm/", "pkg:rpm/%zz"} {
+		_, err = ParsePURL(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestPURLVendorProduct(t *testing.T) {
+	testcases := []struct {
+		purl    string
+		vendor  string
+		product string
+		version string
+		scheme  string
+	}{
+		{"pkg:rpm/redhat/openssl@1:1.0.2k-19.el7", "openssl", "openssl", "1.0.2k", VersionSchemeRPM},
+		{"pkg:deb/debian/openssl@1.1.1n-0+deb11u3", "openssl", "openssl", "1.1.1n", VersionSchemeDpkg},
+		{"pkg:apk/wolfi/git@2.39.0-r1", "git", "git", "2.39.0", VersionSchemeRPM},
+		{"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", "apache", "log4j-core", "2.14.1", ""},
+		{"pkg:npm/%40angular/core@12.0.1", "angular", "core", "12.0.1", ""},
+		{"pkg:golang/github.com/gin-gonic/gin@v1.7.0", "gin-gonic", "gin", "1.7.0", ""},
+		{"pkg:generic/openssl@1.1.1k", "openssl", "openssl", "1.1.1k", ""},
+	}
+
+	for _, tc := range testcases {
+		p, err := ParsePURL(tc.purl)
+		require.NoError(t, err)
+		vendor, product := p.vendorProduct()
+		require.Equal(t, tc.vendor, vendor, tc.purl)
+		require.Equal(t, tc.product, product, tc.purl)
+		require.Equal(t, tc.version, p.upstreamVersion(), tc.purl)
+		require.Equal(t, tc.scheme, p.versionScheme(), tc.purl)
+	}
+}
+
+func TestLoadPURLAliases(t *testing.T) {
+	aliases, err := loadPURLAliases("testdata/purl/purl-aliases.xml")
+	require.NoError(t, err)
+	require.Len(t, aliases.Products, 2)
+	require.Equal(t, "openssl", aliases.Products[0].Product)
+	require.Len(t, aliases.Products[0].PURLs, 4)
+	require.Equal(t, xmlPURLAlias{Type: "deb", Name: "libssl*"}, aliases.Products[0].PURLs[2])
+	require.Equal(t, "alpine", aliases.Products[0].PURLs[3].Namespace)
+}
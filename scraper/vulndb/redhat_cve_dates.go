@@ -0,0 +1,140 @@
+package vulndb
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redhatCVEDates represents a line of the Red Hat cve_dates.txt file, e.g.
+// CVE-1999-0710 impact=low,public=19990725,reported=20050426,source=squid
+type redhatCVEDates struct {
+	CVEID      string
+	Impact     string // low, moderate, important or critical.
+	PublicAt   int64  // Public disclosure date.
+	ReportedAt int64  // Date reported to Red Hat.
+	Source     string // Discovery source, e.g. vendorsec, oss-security or upstream.
+}
+
+var reRedhatCVEID = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// parseRedhatCVEDate parses a cve_dates date, either YYYYMMDD or YYYYMMDD:HHMM (UTC).
+func parseRedhatCVEDate(value string) (int64, error) {
+	layout := "20060102"
+	if strings.Contains(value, ":") {
+		layout = "20060102:1504"
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
+
+// parseRedhatCVEDates parses the cve_dates lines from `r`. Lines with malformed CVE IDs (e.g. CVE-200r0-0322)
+// or dates are logged and skipped. Per advisory overrides such as impact(RHSA-2005:567)=important and the
+// CVSS scores are ignored.
+func parseRedhatCVEDates(r io.Reader) ([]redhatCVEDates, error) {
+	var entries []redhatCVEDates
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if !reRedhatCVEID.MatchString(fields[0]) {
+			log.Debugf("cve_dates line %d: Malformed CVE ID '%s' - skipping", lineNo, fields[0])
+			continue
+		}
+		entry := redhatCVEDates{CVEID: fields[0]}
+		if len(fields) > 1 {
+			for _, kv := range strings.Split(fields[1], ",") {
+				parts := strings.SplitN(kv, "=", 2)
+				if len(parts) != 2 {
+					continue
+				}
+				key, value := parts[0], parts[1]
+
+				var err error
+				switch key {
+				case "impact":
+					entry.Impact = value
+				case "public":
+					entry.PublicAt, err = parseRedhatCVEDate(value)
+				case "reported":
+					entry.ReportedAt, err = parseRedhatCVEDate(value)
+				case "source":
+					entry.Source = value
+				}
+				if err != nil {
+					log.Debugf("cve_dates line %d: Invalid %s date '%s' - ignoring", lineNo, key, value)
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// processRedhatCVEDates loads the Red Hat cve_dates file at `cveDatesPath` into vulndb and sets the public and
+// reported dates, impact and discovery source on the corresponding advisories.
+func processRedhatCVEDates(sessionw *VulnDBSession, cveDatesPath string) error {
+	if len(cveDatesPath) == 0 {
+		return nil
+	}
+
+	f, err := os.Open(cveDatesPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entries, err := parseRedhatCVEDates(f)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Loaded %d Red Hat CVE dates", len(entries))
+
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if seen[entry.CVEID] {
+			continue
+		}
+		seen[entry.CVEID] = true
+
+		item := redhatCVEDate{
+			CVEID:  entry.CVEID,
+			Impact: entry.Impact,
+			Source: entry.Source,
+		}
+		if entry.PublicAt != 0 {
+			item.PublicAt = &entry.PublicAt
+		}
+		if entry.ReportedAt != 0 {
+			item.ReportedAt = &entry.ReportedAt
+		}
+		err = sessionw.Insert(&item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return sessionw.Exec(`
+UPDATE nvd_cve_advisories SET
+  public_at = (SELECT d.public_at FROM redhat_cve_dates d WHERE d.cve_id = nvd_cve_advisories.cve_id),
+  reported_at = (SELECT d.reported_at FROM redhat_cve_dates d WHERE d.cve_id = nvd_cve_advisories.cve_id),
+  vendor_impact = (SELECT NULLIF(d.impact, '') FROM redhat_cve_dates d WHERE d.cve_id = nvd_cve_advisories.cve_id),
+  disclosure_source = (SELECT NULLIF(d.source, '') FROM redhat_cve_dates d WHERE d.cve_id = nvd_cve_advisories.cve_id)
+WHERE cve_id IN (SELECT cve_id FROM redhat_cve_dates)`)
+}
@@ -0,0 +1,44 @@
+package vulndb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRedhatCVEDates(t *testing.T) {
+	// Sample of the Red Hat cve_dates.txt file.
+	content := `CVE-1999-0710 impact=low,public=19990725,reported=20050426,source=squid
+CVE-2000-0269 public=20000418
+CVE-200r0-0322 public=20000424
+CVE-2005-1689 impact=critical,public=20050712,reported=20050526,source=mit,impact(RHSA-2005:567)=important
+CVE-2005-2709 impact=moderate,public=20051108:1400,reported=20050919,source=redhat
+CVE-2011-2767 impact=important,public=20111003,reported=20180826,source=cve,cvss3=6.3/CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:U/C:L/I:L/A:L
+`
+	entries, err := parseRedhatCVEDates(strings.NewReader(content))
+	require.NoError(t, err)
+	require.Equal(t, []redhatCVEDates{
+		{CVEID: "CVE-1999-0710", Impact: "low", PublicAt: 932860800, ReportedAt: 1114473600, Source: "squid"},
+		{CVEID: "CVE-2000-0269", PublicAt: 956016000},
+		{CVEID: "CVE-2005-1689", Impact: "critical", PublicAt: 1121126400, ReportedAt: 1117065600, Source: "mit"},
+		{CVEID: "CVE-2005-2709", Impact: "moderate", PublicAt: 1131458400, ReportedAt: 1127088000, Source: "redhat"},
+		{CVEID: "CVE-2011-2767", Impact: "important", PublicAt: 1317600000, ReportedAt: 1535241600, Source: "cve"},
+	}, entries)
+}
+
+func TestAdvisoryDisclosure(t *testing.T) {
+	publicAt := int64(1121126400)
+	reportedAt := int64(1117065600)
+	advisory := NVDCVEAdvisory{PublishedAt: 1130000000}
+	require.Equal(t, int64(1130000000), advisory.DisclosedAt())
+	_, ok := advisory.ReactionTime()
+	require.False(t, ok)
+
+	advisory.PublicAt = &publicAt
+	advisory.ReportedAt = &reportedAt
+	require.Equal(t, publicAt, advisory.DisclosedAt())
+	reaction, ok := advisory.ReactionTime()
+	require.True(t, ok)
+	require.Equal(t, 47, int(reaction.Hours()/24))
+}
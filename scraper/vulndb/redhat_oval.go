@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"compress/bzip2"
 	"encoding/xml"
-	"errors"
 	"fmt"
 	"time"
 
@@ -22,15 +21,19 @@ var (
 
 func processRedhatOvalData(sessionw *VulnDBSession) error {
 	log.Debug("Fetching Red Hat OVAL data...")
+	crawledAt := time.Now().UTC().Unix()
+	cvssSeen := map[string]bool{}
 	for _, release := range releases {
-		if err := update(sessionw, release); err != nil {
+		if err := update(sessionw, release, crawledAt, cvssSeen); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func update(sessionw *VulnDBSession, release string) error {
+// update processes the OVAL data of Red Hat `release`. The cvss3 scores of the CVEs are stored as vendor CVSS
+// entries crawled at `crawledAt`, once per CVE as tracked by `cvssSeen`.
+func update(sessionw *VulnDBSession, release string, crawledAt int64, cvssSeen map[string]bool) error {
 	var advisories []NVDCVEAdvisory
 	if err := sessionw.Find(&advisories); err != nil {
 		return err
@@ -39,13 +42,13 @@ func update(sessionw *VulnDBSession, release string) error {
 	for _, advisory := range advisories {
 		advisoryIDs[advisory.CVEID] = advisory.Id
 	}
-	var platform platforms
-	ok, err := sessionw.Where(`display_name = ?`, "Redhat Linux "+release).Get(&platform)
+	platformMappingRules, err := loadPlatformMappingRules(sessionw, SourceRedhatOVAL)
 	if err != nil {
 		return err
 	}
+	platformID, ok := findPlatform(platformMappingRules, "Red Hat Enterprise Linux "+release)
 	if !ok {
-		return errors.New("invalid Redhat release")
+		return fmt.Errorf("no platform defined for Red Hat Enterprise Linux %s (source %s)", release, SourceRedhatOVAL)
 	}
 	var platformVuln []platformVulnerabilities
 	if err := sessionw.Find(&platformVuln); err != nil {
@@ -70,12 +73,20 @@ func update(sessionw *VulnDBSession, release string) error {
 	bar := pb.StartNew(len(ovalroot.Definitions.Definitions))
 	for _, def := range ovalroot.Definitions.Definitions {
 		for _, cve := range def.Advisory.Cves {
+			if score, vector, ok := parseScoredCVSS3(cve.Cvss3); ok && !cvssSeen[cve.CveID] {
+				err = UpsertVendorCVSSEntry(sessionw, cve.CveID, SourceRedhatOVAL, vector, &score, crawledAt)
+				if err != nil {
+					return err
+				}
+				cvssSeen[cve.CveID] = true
+			}
+
 			advisoryID, has := advisoryIDs[cve.CveID]
 			if has {
-				key := fmt.Sprintf("%v:%v", platform.ID, advisoryID)
+				key := fmt.Sprintf("%v:%v", platformID, advisoryID)
 				if _, ok := uniqueMapping[key]; !ok {
 					var platformVuln platformVulnerabilities
-					platformVuln.PlatformID = platform.ID
+					platformVuln.PlatformID = platformID
 					platformVuln.VulnerabilityId = advisoryID
 					platformVuln.Source = SourceRedhatOVAL
 					err = sessionw.Insert(&platformVuln)
@@ -111,14 +122,14 @@ func update(sessionw *VulnDBSession, release string) error {
 			}
 			advisoryIDs[cve.CveID] = advisory.Id
 			var platformVuln platformVulnerabilities
-			platformVuln.PlatformID = platform.ID
+			platformVuln.PlatformID = platformID
 			platformVuln.VulnerabilityId = advisory.Id
 			platformVuln.Source = SourceRedhatOVAL
 			err = sessionw.Insert(&platformVuln)
 			if err != nil {
 				return err
 			}
-			uniqueMapping[fmt.Sprintf("%v:%v", platform.ID, advisory.Id)] = true
+			uniqueMapping[fmt.Sprintf("%v:%v", platformID, advisory.Id)] = true
 		}
 		bar.Increment()
 	}
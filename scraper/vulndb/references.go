@@ -0,0 +1,83 @@
+package vulndb
+
+import "strings"
+
+// Reference tags as used by NVD.
+const (
+	ReferenceTagPatch              = "Patch"
+	ReferenceTagVendorAdvisory     = "Vendor Advisory"
+	ReferenceTagExploit            = "Exploit"
+	ReferenceTagMitigation         = "Mitigation"
+	ReferenceTagThirdPartyAdvisory = "Third Party Advisory"
+)
+
+// insertCVEReferences inserts the `references` of `cveID` into vulndb.
+func insertCVEReferences(sessionw *VulnDBSession, cveID string, references []CVEAdvisoryReference) error {
+	for _, ref := range references {
+		if len(ref.URL) == 0 {
+			continue
+		}
+		item := CVEReference{
+			CVEID:  cveID,
+			URL:    ref.URL,
+			Name:   ref.Name,
+			Source: ref.Source,
+			Tags:   strings.Join(ref.Tags, ","),
+		}
+		err := sessionw.Insert(&item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TagList returns the tags of the reference.
+func (ref CVEReference) TagList() []string {
+	if len(ref.Tags) == 0 {
+		return nil
+	}
+	return strings.Split(ref.Tags, ",")
+}
+
+// HasTag returns true if the reference is tagged with `tag`.
+func (ref CVEReference) HasTag(tag string) bool {
+	for _, t := range ref.TagList() {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ListCVEReferences returns all references of CVE `cveID`.
+func ListCVEReferences(session *VulnDBSession, cveID string) ([]CVEReference, error) {
+	var references []CVEReference
+	err := session.Where("LOWER(cve_id) = LOWER(?)", cveID).OrderBy("id").Find(&references)
+	if err != nil {
+		return nil, err
+	}
+	return references, nil
+}
+
+// ListCVEReferencesByTag returns the references of CVE `cveID` grouped by tag, e.g. ReferenceTagPatch or
+// ReferenceTagVendorAdvisory. References with multiple tags are listed under each of them, references without
+// tags under the empty tag.
+func ListCVEReferencesByTag(session *VulnDBSession, cveID string) (map[string][]CVEReference, error) {
+	references, err := ListCVEReferences(session, cveID)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := map[string][]CVEReference{}
+	for _, ref := range references {
+		tags := ref.TagList()
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		for _, tag := range tags {
+			grouped[tag] = append(grouped[tag], ref)
+		}
+	}
+	return grouped, nil
+}
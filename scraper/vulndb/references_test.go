@@ -0,0 +1,68 @@
+package vulndb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCVEReferenceTags(t *testing.T) {
+	ref := CVEReference{Tags: "Patch,Vendor Advisory"}
+	require.Equal(t, []string{ReferenceTagPatch, ReferenceTagVendorAdvisory}, ref.TagList())
+	require.True(t, ref.HasTag(ReferenceTagPatch))
+	require.True(t, ref.HasTag(ReferenceTagVendorAdvisory))
+	require.False(t, ref.HasTag(ReferenceTagExploit))
+
+	ref = CVEReference{}
+	require.Nil(t, ref.TagList())
+	require.False(t, ref.HasTag(""))
+}
+
+func TestListCVEReferencesByTag(t *testing.T) {
+	vdbPath := os.Getenv(`VULNDB_PATH`)
+	if len(vdbPath) == 0 {
+		t.Skipf("Skipped, VULNDB_PATH not set")
+		return
+	}
+
+	vdb, err := New(vdbPath)
+	require.NoError(t, err)
+	defer vdb.Close()
+
+	vdbSession, err := vdb.NewSession()
+	require.NoError(t, err)
+	defer vdbSession.Close()
+
+	references, err := ListCVEReferences(vdbSession, "CVE-2021-44228")
+	require.NoError(t, err)
+	require.NotEmpty(t, references)
+
+	grouped, err := ListCVEReferencesByTag(vdbSession, "cve-2021-44228")
+	require.NoError(t, err)
+	require.NotEmpty(t, grouped[ReferenceTagVendorAdvisory])
+	require.NotEmpty(t, grouped[ReferenceTagPatch])
+	for tag, refs := range grouped {
+		for _, ref := range refs {
+			if len(tag) == 0 {
+				require.Empty(t, ref.TagList(), ref.URL)
+			} else {
+				require.True(t, ref.HasTag(tag), "%s: %s", tag, ref.URL)
+			}
+		}
+	}
+	// Every reference is listed under each of its tags.
+	for _, ref := range references {
+		tags := ref.TagList()
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		for _, tag := range tags {
+			require.Contains(t, grouped[tag], ref, ref.URL)
+		}
+	}
+
+	grouped, err = ListCVEReferencesByTag(vdbSession, "CVE-1999-0000000")
+	require.NoError(t, err)
+	require.Empty(t, grouped)
+}
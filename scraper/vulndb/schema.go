@@ -1,5 +1,7 @@
 package vulndb
 
+import "time"
+
 // createVulnDBSchema defines the schema for the vulndb (sqlite).
 const createVulnDBSchema = `
 CREATE TABLE vulndb_vendors(
@@ -29,12 +31,27 @@ CREATE TABLE vulndb_product_aliases(
 );
 CREATE INDEX vulndb_product_aliases_alias_idx ON vulndb_product_aliases(vendor_alias, product_alias);
 
+CREATE TABLE purl_aliases(
+  product_id INTEGER NOT NULL,
+  purl_type TEXT NOT NULL,
+  purl_namespace TEXT NOT NULL,
+  purl_name TEXT NOT NULL
+);
+CREATE INDEX purl_aliases_purl_type_idx ON purl_aliases(purl_type);
+
 CREATE TABLE vulndb_ignore_list(
 	vendor_name TEXT NOT NULL,
 	product_name_glob TEXT NOT NULL
 );
 CREATE INDEX vulndb_ignore_list_vendor_product_idx ON vulndb_ignore_list(vendor_name);
 
+CREATE TABLE vulndb_version_schemes(
+	vendor_name TEXT NOT NULL,
+	product_name_glob TEXT NOT NULL,
+	scheme TEXT NOT NULL
+);
+CREATE INDEX vulndb_version_schemes_vendor_idx ON vulndb_version_schemes(vendor_name);
+
 CREATE TABLE vulndb_product_items(
   id INTEGER PRIMARY KEY,
   product_id INTEGER NOT NULL,
@@ -52,7 +69,8 @@ CREATE INDEX vulndb_product_items_product_id_systype_version_patch_idx ON vulndb
 
 CREATE TABLE vulndb_vulnerabilities(
   product_item_id INTEGER NOT NULL,
-  advisory_id INTEGER NOT NULL
+  advisory_id INTEGER NOT NULL,
+  cpe23 TEXT
 );
 CREATE INDEX vulndb_vulnerabilities_product_id_idx ON vulndb_vulnerabilities(product_item_id);
 
@@ -60,6 +78,7 @@ CREATE TABLE nvd_cve_advisories(
   id INTEGER PRIMARY KEY,
   cve_id TEXT NOT NULL,
   summary TEXT NOT NULL,
+  status TEXT,
   published_at INTEGER NOT NULL,
   last_modified_at INTEGER NOT NULL,
   cvss2_base_score DOUBLE,
@@ -67,6 +86,16 @@ CREATE TABLE nvd_cve_advisories(
   cvss2_access_complexity INTEGER,
   cvss2_authentication INTEGER,
   cvss2_confidentiality_impact INTEGER,
+  cvss2_integrity_impact INTEGER,
+  cvss2_availability_impact INTEGER,
+  cvss2_vector_string TEXT,
+  cvss2_severity INTEGER,
+  cvss2_exploitability_score DOUBLE,
+  cvss2_impact_score DOUBLE,
+  cvss2_obtain_all_privilege INTEGER,
+  cvss2_obtain_user_privilege INTEGER,
+  cvss2_obtain_other_privilege INTEGER,
+  cvss2_user_interaction_required INTEGER,
   cvss3_base_score DOUBLE,
   cvss3_attack_complexity INTEGER,
   cvss3_attack_vector INTEGER,
@@ -78,14 +107,70 @@ CREATE TABLE nvd_cve_advisories(
   cvss3_user_interaction INTEGER,
   cvss3_vector_string TEXT,
   cvss3_exploitability_score INTEGER,
+  cvss4_vector_string TEXT,
+  cvss4_base_score DOUBLE,
+  cvss4_base_severity INTEGER,
   vendor_ref_url TEXT,
   has_patch INTEGER,
-  report_confirmed INTEGER
+  report_confirmed INTEGER,
+  public_at INTEGER,
+  reported_at INTEGER,
+  vendor_impact TEXT,
+  disclosure_source TEXT
 );
 
 CREATE INDEX nvd_cve_advisories_cve_id_idx ON nvd_cve_advisories(cve_id);
 CREATE INDEX nvd_cve_advisories_cvss2_base_score_idx ON nvd_cve_advisories(cvss2_base_score);
 CREATE INDEX nvd_cve_advisories_cvss3_base_score_idx ON nvd_cve_advisories(cvss3_base_score);
+CREATE INDEX nvd_cve_advisories_cvss4_base_score_idx ON nvd_cve_advisories(cvss4_base_score);
+
+CREATE TABLE cve_references(
+  id INTEGER PRIMARY KEY,
+  cve_id TEXT NOT NULL,
+  url TEXT NOT NULL,
+  name TEXT,
+  source TEXT,
+  tags TEXT
+);
+CREATE INDEX cve_references_cve_id_idx ON cve_references(cve_id);
+
+CREATE TABLE cve_configuration_nodes(
+  id INTEGER PRIMARY KEY,
+  advisory_id INTEGER NOT NULL,
+  parent_id INTEGER,
+  operator TEXT NOT NULL,
+  negate INTEGER NOT NULL
+);
+CREATE INDEX cve_configuration_nodes_advisory_id_idx ON cve_configuration_nodes(advisory_id);
+
+CREATE TABLE cve_configuration_matches(
+  node_id INTEGER NOT NULL,
+  advisory_id INTEGER NOT NULL,
+  vulnerable INTEGER NOT NULL,
+  cpe23 TEXT NOT NULL,
+  systype TEXT NOT NULL,
+  vendor TEXT NOT NULL,
+  product TEXT NOT NULL,
+  version_start_excluding TEXT,
+  version_start_including TEXT,
+  version_end_excluding TEXT,
+  version_end_including TEXT
+);
+CREATE INDEX cve_configuration_matches_systype_vendor_product_idx ON cve_configuration_matches(systype, vendor, product);
+CREATE INDEX cve_configuration_matches_advisory_id_idx ON cve_configuration_matches(advisory_id);
+
+CREATE TABLE removed_cves(
+  cve_id TEXT NOT NULL,
+  removed_at INTEGER NOT NULL
+);
+CREATE INDEX removed_cves_cve_id_idx ON removed_cves(cve_id);
+
+CREATE TABLE cpe_dictionary_titles(
+  vendor TEXT NOT NULL,
+  product TEXT NOT NULL,
+  title TEXT NOT NULL
+);
+CREATE INDEX cpe_dictionary_titles_vendor_idx ON cpe_dictionary_titles(vendor);
 
 CREATE TABLE vendor_cvss_entries(
   id INTEGER PRIMARY KEY,
@@ -94,36 +179,22 @@ CREATE TABLE vendor_cvss_entries(
   last_crawled_at INTEGER NOT NULL,
   last_modified_at INTEGER NOT NULL,
   cvss3_vector_string TEXT NOT NULL,
+  cvss3_base_score DOUBLE,
   source TEXT NOT NULL
 );
 CREATE INDEX vendor_cvss_entries_cve_id_idx ON vendor_cvss_entries(cve_id);
 
 CREATE TABLE platforms(
   id INTEGER PRIMARY KEY,
-  rule TEXT NOT NULL,
   display_name TEXT NOT NULL
 );
-INSERT INTO platforms VALUES (1, ':o:centos:centos:6.0:', 'CentOS Linux 6');
-INSERT INTO platforms VALUES (2, ':o:centos:centos:6.0:', 'CentOS Linux 7');
-INSERT INTO platforms VALUES (3, ':o:centos:centos:6.0:', 'CentOS Linux 8');
-INSERT INTO platforms VALUES (4, ':o:cisco:ios:', 'Cisco IOS');
-INSERT INTO platforms VALUES (5, ':o:debian:debian_linux:10.0:', 'Debian Linux Buster 10');
-INSERT INTO platforms VALUES (6, ':o:debian:debian_linux:9.0:', 'Debian Linux Stretch 9');
-INSERT INTO platforms VALUES (7, ':o:microsoft:windows_10:,Microsoft Windows 10', 'Microsoft Windows 10');
-INSERT INTO platforms VALUES (8, ':o:microsoft:windows_server_2008:r2,Windows Server 2008 R2', 'Microsoft Windows Server 2008 R2');
-INSERT INTO platforms VALUES (9, ':o:microsoft:windows_server_2012:-:,Windows Server 2012', 'Microsoft Windows Server 2012');
-INSERT INTO platforms VALUES (10, ':o:microsoft:windows_server_2012:r2:,Windows Server 2012 R2', 'Microsoft Windows Server 2012 R2');
-INSERT INTO platforms VALUES (11, ':o:microsoft:windows_server_2016:,Windows Server 2016',  'Microsoft Windows Server 2016');
-INSERT INTO platforms VALUES (12, ':o:microsoft:windows_server_2019:,Windows Server 2019', 'Microsoft Windows Server 2019');
-INSERT INTO platforms VALUES (13, ':o:redhat:enterprise_linux:4.0:', 'Redhat Linux 4');
-INSERT INTO platforms VALUES (14, ':o:redhat:enterprise_linux:5.0:', 'Redhat Linux 5');
-INSERT INTO platforms VALUES (15, ':o:redhat:enterprise_linux:6.0:', 'Redhat Linux 6');
-INSERT INTO platforms VALUES (16, ':o:redhat:enterprise_linux:7.0:', 'Redhat Linux 7');
-INSERT INTO platforms VALUES (17, ':o:redhat:enterprise_linux:8.0:', 'Redhat Linux 8');
-INSERT INTO platforms VALUES (18, ':o:oracle:solaris:', 'Solaris');
-INSERT INTO platforms VALUES (19, ':o:canonical:ubuntu_linux:18.04:', 'Ubuntu Linux Bionic 1804');
-INSERT INTO platforms VALUES (20, ':o:canonical:ubuntu_linux:16.04:', 'Ubuntu Linux Xenial 1604');
-INSERT INTO platforms VALUES (21, ':o:canonical:ubuntu_linux:20.04:', 'Ubuntu Linux Focal 2004');
+
+CREATE TABLE platform_rules(
+  platform_id INTEGER NOT NULL,
+  source TEXT NOT NULL,
+  pattern TEXT NOT NULL
+);
+CREATE INDEX platform_rules_source_idx ON platform_rules(source);
 
 CREATE TABLE platform_vulnerabilities(
   platform_id INTEGER NOT NULL,
@@ -132,6 +203,58 @@ CREATE TABLE platform_vulnerabilities(
 );
 CREATE INDEX platform_vulnerabilities_vulnerability_id_idx ON platform_vulnerabilities(vulnerability_id);
 
+CREATE TABLE juniper_jsa_advisories(
+  id INTEGER PRIMARY KEY,
+  jsa_id TEXT NOT NULL,
+  cve_id TEXT NOT NULL,
+  title TEXT,
+  url TEXT
+);
+CREATE INDEX juniper_jsa_advisories_cve_id_idx ON juniper_jsa_advisories(cve_id);
+
+CREATE TABLE csaf_advisories(
+  id INTEGER PRIMARY KEY,
+  tracking_id TEXT NOT NULL,
+  vendor_id INTEGER NOT NULL,
+  cve_id TEXT NOT NULL,
+  title TEXT,
+  url TEXT,
+  published_at INTEGER,
+  last_modified_at INTEGER
+);
+CREATE INDEX csaf_advisories_cve_id_idx ON csaf_advisories(cve_id);
+
+CREATE TABLE csaf_product_statuses(
+  csaf_advisory_id INTEGER NOT NULL,
+  product_id INTEGER NOT NULL,
+  version TEXT,
+  status TEXT NOT NULL,
+  remediation_category TEXT,
+  remediation_details TEXT,
+  remediation_url TEXT
+);
+CREATE INDEX csaf_product_statuses_csaf_advisory_id_idx ON csaf_product_statuses(csaf_advisory_id);
+CREATE INDEX csaf_product_statuses_product_id_idx ON csaf_product_statuses(product_id);
+
+CREATE TABLE mozilla_mfsa_advisories(
+  id INTEGER PRIMARY KEY,
+  mfsa_id TEXT NOT NULL,
+  cve_id TEXT NOT NULL,
+  title TEXT,
+  impact TEXT,
+  url TEXT,
+  announced_at INTEGER
+);
+CREATE INDEX mozilla_mfsa_advisories_cve_id_idx ON mozilla_mfsa_advisories(cve_id);
+
+CREATE TABLE redhat_cve_dates(
+  cve_id TEXT PRIMARY KEY,
+  impact TEXT,
+  public_at INTEGER,
+  reported_at INTEGER,
+  source TEXT
+);
+
 CREATE TABLE windows10_versions(
    version TEXT PRIMARY KEY,
    os_build TEXT,
@@ -199,6 +322,18 @@ func (alias vulndbProductAlias) TableName() string {
 	return "vulndb_product_aliases"
 }
 
+// purlAlias maps package URLs to a product, e.g. pkg:rpm/redhat/openssl -> openssl/openssl.
+type purlAlias struct {
+	ProductID     int64  `xorm:"product_id"`
+	PURLType      string `xorm:"purl_type"`
+	PURLNamespace string `xorm:"purl_namespace"` // Empty for any namespace.
+	PURLName      string `xorm:"purl_name"`      // Name glob, e.g. openssl*.
+}
+
+func (alias purlAlias) TableName() string {
+	return "purl_aliases"
+}
+
 // vulndbIgnoreItem represents an item in the ignorelist.
 type vulndbIgnoreListItem struct {
 	VendorName      string `xorm:"vendor_name"`
@@ -209,10 +344,20 @@ func (ign vulndbIgnoreListItem) TableName() string {
 	return "vulndb_ignore_list"
 }
 
+// vulndbVersionScheme represents the version scheme assignment of products by vendor matching a product glob.
+type vulndbVersionScheme struct {
+	VendorName      string `xorm:"vendor_name"`
+	ProductNameGlob string `xorm:"product_name_glob"`
+	Scheme          string `xorm:"scheme"`
+}
+
+func (vs vulndbVersionScheme) TableName() string {
+	return "vulndb_version_schemes"
+}
+
 // platforms represents the supported platforms.
 type platforms struct {
-	ID          int64  `xorm:"pk autoincr 'id'"`
-	Rule        string `xorm:"rule"`
+	ID          int64  `xorm:"pk 'id'"`
 	DisplayName string `xorm:"display_name"`
 }
 
@@ -220,12 +365,26 @@ func (p platforms) TableName() string {
 	return "platforms"
 }
 
+// platformRule represents a regular expression mapping the CPEs (source cpe) or the product names of a source
+// (e.g. msrcAPI) to a platform.
+type platformRule struct {
+	PlatformID int64  `xorm:"platform_id"`
+	Source     string `xorm:"source"`
+	Pattern    string `xorm:"pattern"`
+}
+
+func (r platformRule) TableName() string {
+	return "platform_rules"
+}
+
 // Constants for use in sqlite vulndb.
 const (
 	SourceCPE        = "cpe"         // CPE source used to get mapping of platform and vulnerability
 	SourceMSRC       = "msrcAPI"     // MSRC API source used to get mapping of platform and vulnerability
 	SourceRedhatOVAL = "redhat_oval" // redhat_oval source used to get mapping of platform and vulnerability
 	SourceCisco      = "cisco"       // cisco source used to get mapping of platform and vulnerability
+	SourceCSAF       = "csaf"        // CSAF 2.0 advisories used to get mapping of platform and vulnerability
+	SourceApple      = "apple"       // Apple security advisories used to get vendor CVSS scores
 )
 
 type platformVulnerabilities struct {
@@ -238,6 +397,79 @@ func (pv platformVulnerabilities) TableName() string {
 	return "platform_vulnerabilities"
 }
 
+// juniperJSAAdvisory links a Juniper security advisory (JSA) to a CVE.
+type juniperJSAAdvisory struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	JSAID string `xorm:"jsa_id"`
+	CVEID string `xorm:"cve_id"`
+	Title string `xorm:"title"`
+	URL   string `xorm:"url"`
+}
+
+func (jsa juniperJSAAdvisory) TableName() string {
+	return "juniper_jsa_advisories"
+}
+
+// csafAdvisoryItem links a CSAF document to a CVE and the publishing vendor.
+type csafAdvisoryItem struct {
+	ID             int64  `xorm:"pk autoincr 'id'"`
+	TrackingID     string `xorm:"tracking_id"`
+	VendorID       int64  `xorm:"vendor_id"`
+	CVEID          string `xorm:"cve_id"`
+	Title          string `xorm:"title"`
+	URL            string `xorm:"url"`
+	PublishedAt    int64  `xorm:"published_at"`
+	LastModifiedAt int64  `xorm:"last_modified_at"`
+}
+
+func (adv csafAdvisoryItem) TableName() string {
+	return "csaf_advisories"
+}
+
+// csafProductStatusItem represents the status of a product for a CSAF advisory (CVE), e.g. fixed or
+// known_not_affected, along with the remediation.
+type csafProductStatusItem struct {
+	CSAFAdvisoryID      int64  `xorm:"csaf_advisory_id"`
+	ProductID           int64  `xorm:"product_id"`
+	Version             string `xorm:"version"`
+	Status              string `xorm:"status"`
+	RemediationCategory string `xorm:"remediation_category"`
+	RemediationDetails  string `xorm:"remediation_details"`
+	RemediationURL      string `xorm:"remediation_url"`
+}
+
+func (st csafProductStatusItem) TableName() string {
+	return "csaf_product_statuses"
+}
+
+// mozillaMFSAAdvisory links a Mozilla Foundation Security Advisory (MFSA) to a CVE.
+type mozillaMFSAAdvisory struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	MFSAID      string `xorm:"mfsa_id"`
+	CVEID       string `xorm:"cve_id"`
+	Title       string `xorm:"title"`
+	Impact      string `xorm:"impact"`
+	URL         string `xorm:"url"`
+	AnnouncedAt int64  `xorm:"announced_at"`
+}
+
+func (mfsa mozillaMFSAAdvisory) TableName() string {
+	return "mozilla_mfsa_advisories"
+}
+
+// redhatCVEDate represents the Red Hat disclosure timeline of a CVE.
+type redhatCVEDate struct {
+	CVEID      string `xorm:"pk 'cve_id'"`
+	Impact     string `xorm:"impact"`
+	PublicAt   *int64 `xorm:"public_at"`
+	ReportedAt *int64 `xorm:"reported_at"`
+	Source     string `xorm:"source"`
+}
+
+func (d redhatCVEDate) TableName() string {
+	return "redhat_cve_dates"
+}
+
 type windows10_versions struct {
 	Version          string `xorm:"pk 'version'"`
 	OsBuild          string `xorm:"os_build"`
@@ -273,6 +505,18 @@ const (
 	CVSSConfidentialityImpactComplete int = 300 // COMPLETE
 )
 
+const (
+	CVSSIntegrityImpactNone     int = 100 // NONE
+	CVSSIntegrityImpactPartial  int = 200 // PARTIAL
+	CVSSIntegrityImpactComplete int = 300 // COMPLETE
+)
+
+const (
+	CVSSAvailabilityImpactNone     int = 100 // NONE
+	CVSSAvailabilityImpactPartial  int = 200 // PARTIAL
+	CVSSAvailabilityImpactComplete int = 300 // COMPLETE
+)
+
 /// CVSS3.
 const (
 	AttackVectorTypeNetwork         int = 100 // "NETWORK"
@@ -389,17 +633,28 @@ const (
 
 // NVDCVEAdvisory represents NVD CVE advisories.
 type NVDCVEAdvisory struct {
-	Id             int64  `xorm:"pk autoincr 'id'"`
-	CVEID          string `xorm:"cve_id"`
-	Summary        string `xorm:"summary"`
-	PublishedAt    int64  `xorm:"published_at"`
-	LastModifiedAt int64  `xorm:"last_modified_at"`
+	Id             int64   `xorm:"pk autoincr 'id'"`
+	CVEID          string  `xorm:"cve_id"`
+	Summary        string  `xorm:"summary"`
+	Status         *string `xorm:"status"` // CVEStatusPublished, CVEStatusRejected or CVEStatusDisputed, nil if unknown.
+	PublishedAt    int64   `xorm:"published_at"`
+	LastModifiedAt int64   `xorm:"last_modified_at"`
 	// CVSS2.
-	CVSS2BaseScore             *float64 `xorm:"cvss2_base_score"`
-	CVSS2AccessVector          *int     `xorm:"cvss2_access_vector"`
-	CVSS2AccessComplexity      *int     `xorm:"cvss2_access_complexity"`
-	CVSS2Authentication        *int     `xorm:"cvss2_authentication"`
-	CVSS2ConfidentialityImpact *int     `xorm:"cvss2_confidentiality_impact"`
+	CVSS2BaseScore               *float64 `xorm:"cvss2_base_score"`
+	CVSS2AccessVector            *int     `xorm:"cvss2_access_vector"`
+	CVSS2AccessComplexity        *int     `xorm:"cvss2_access_complexity"`
+	CVSS2Authentication          *int     `xorm:"cvss2_authentication"`
+	CVSS2ConfidentialityImpact   *int     `xorm:"cvss2_confidentiality_impact"`
+	CVSS2IntegrityImpact         *int     `xorm:"cvss2_integrity_impact"`
+	CVSS2AvailabilityImpact      *int     `xorm:"cvss2_availability_impact"`
+	CVSS2VectorString            *string  `xorm:"cvss2_vector_string"`
+	CVSS2Severity                *int     `xorm:"cvss2_severity"`
+	CVSS2ExploitabilityScore     *float64 `xorm:"cvss2_exploitability_score"`
+	CVSS2ImpactScore             *float64 `xorm:"cvss2_impact_score"`
+	CVSS2ObtainAllPrivilege      *int     `xorm:"cvss2_obtain_all_privilege"`
+	CVSS2ObtainUserPrivilege     *int     `xorm:"cvss2_obtain_user_privilege"`
+	CVSS2ObtainOtherPrivilege    *int     `xorm:"cvss2_obtain_other_privilege"`
+	CVSS2UserInteractionRequired *int     `xorm:"cvss2_user_interaction_required"`
 	// CVSS3.
 	CVSS3BaseScore             *float64 `xorm:"cvss3_base_score"`
 	CVSS3AttackComplexity      *int     `xorm:"cvss3_attack_complexity"`
@@ -412,34 +667,154 @@ type NVDCVEAdvisory struct {
 	CVSS3UserInteraction       *int     `xorm:"cvss3_user_interaction"`
 	CVSS3VectorString          *string  `xorm:"cvss3_vector_string"`
 	CVSS3ExploitabilityScore   *int     `xorm:"cvss3_exploitability_score"`
+	// CVSS4.
+	CVSS4VectorString *string  `xorm:"cvss4_vector_string"`
+	CVSS4BaseScore    *float64 `xorm:"cvss4_base_score"`
+	CVSS4BaseSeverity *int     `xorm:"cvss4_base_severity"`
 
 	VendorRefUrl    *string `json:"vendor_ref_url"`
 	HasPatch        *int    `json:"has_patch"`
 	ReportConfirmed *int    `json:"report_confirmed"`
+
+	// Red Hat CVE dates.
+	PublicAt         *int64  `xorm:"public_at"`         // Public disclosure date.
+	ReportedAt       *int64  `xorm:"reported_at"`       // Date reported to the vendor.
+	VendorImpact     *string `xorm:"vendor_impact"`     // Vendor impact rating, e.g. moderate.
+	DisclosureSource *string `xorm:"disclosure_source"` // Discovery source, e.g. oss-security.
+
+	// Vendor CVSS entry according to the session CVSS policy (see CVSSPolicy).
+	VendorCVSS *VendorCVSSEntry `xorm:"-"`
 }
 
 func (cve NVDCVEAdvisory) TableName() string {
 	return "nvd_cve_advisories"
 }
 
+// BaseScore returns the base score of the latest CVSS version the CVE is scored with: CVSS4, CVSS3 or CVSS2.
+// Returns 0 if not scored. The SQL equivalent is VulnDBSession.baseScoreSQL.
+func (cve NVDCVEAdvisory) BaseScore() float64 {
+	switch {
+	case cve.CVSS4BaseScore != nil:
+		return *cve.CVSS4BaseScore
+	case cve.CVSS3BaseScore != nil:
+		return *cve.CVSS3BaseScore
+	case cve.CVSS2BaseScore != nil:
+		return *cve.CVSS2BaseScore
+	}
+	return 0
+}
+
+// DisclosedAt returns the public disclosure date of the CVE, falling back to the NVD published date if unknown.
+func (cve NVDCVEAdvisory) DisclosedAt() int64 {
+	if cve.PublicAt != nil && *cve.PublicAt != 0 {
+		return *cve.PublicAt
+	}
+	return cve.PublishedAt
+}
+
+// disclosedAtSQL is the SQL expression of the public disclosure date of nvd_cve_advisories, as
+// NVDCVEAdvisory.DisclosedAt.
+const disclosedAtSQL = "COALESCE(NULLIF(public_at, 0), published_at)"
+
+// ReactionTime returns the time from the CVE being reported to the vendor until its public disclosure.
+// Returns false if either date is unknown.
+func (cve NVDCVEAdvisory) ReactionTime() (time.Duration, bool) {
+	if cve.PublicAt == nil || cve.ReportedAt == nil {
+		return 0, false
+	}
+	return time.Duration(*cve.PublicAt-*cve.ReportedAt) * time.Second, true
+}
+
 // vulndbVulnerability connects vulnerable products with known CVEs.
 type vulndbVulnerability struct {
-	AdvisoryID    int64 `xorm:"advisory_id"`
-	ProductItemID int64 `xorm:"product_item_id"`
+	AdvisoryID    int64   `xorm:"advisory_id"`
+	ProductItemID int64   `xorm:"product_item_id"`
+	CPE23         *string `xorm:"cpe23"` // NVD CPE name the product item is vulnerable by, nil if not from NVD.
 }
 
 func (vuln vulndbVulnerability) TableName() string {
 	return "vulndb_vulnerabilities"
 }
 
+// CVEReference represents a reference of a CVE, e.g. a vendor advisory or patch.
+type CVEReference struct {
+	Id     int64  `xorm:"pk autoincr 'id'"`
+	CVEID  string `xorm:"cve_id"`
+	URL    string `xorm:"url"`
+	Name   string `xorm:"name"`
+	Source string `xorm:"source"` // Reference source, e.g. CONFIRM or MISC.
+	Tags   string `xorm:"tags"`   // Comma separated tags, e.g. Patch,Vendor Advisory.
+}
+
+func (ref CVEReference) TableName() string {
+	return "cve_references"
+}
+
+// cveConfigurationNode represents a node of the NVD configuration tree of a CVE. Root nodes have no parent.
+type cveConfigurationNode struct {
+	ID         int64  `xorm:"pk autoincr 'id'"`
+	AdvisoryID int64  `xorm:"advisory_id"`
+	ParentID   *int64 `xorm:"parent_id"`
+	Operator   string `xorm:"operator"` // AND or OR.
+	Negate     int    `xorm:"negate"`
+}
+
+func (node cveConfigurationNode) TableName() string {
+	return "cve_configuration_nodes"
+}
+
+// cveConfigurationMatch represents a CPE match of a configuration node. Non vulnerable matches describe the
+// platform the vulnerable product must be running on.
+type cveConfigurationMatch struct {
+	NodeID                int64   `xorm:"node_id"`
+	AdvisoryID            int64   `xorm:"advisory_id"`
+	Vulnerable            int     `xorm:"vulnerable"`
+	CPE23                 string  `xorm:"cpe23"`
+	Systype               string  `xorm:"systype"`
+	Vendor                string  `xorm:"vendor"`
+	Product               string  `xorm:"product"`
+	VersionStartExcluding *string `xorm:"version_start_excluding"`
+	VersionStartIncluding *string `xorm:"version_start_including"`
+	VersionEndExcluding   *string `xorm:"version_end_excluding"`
+	VersionEndIncluding   *string `xorm:"version_end_including"`
+	VersionScheme         string  `xorm:"-"` // Version scheme of the product, set when matching.
+}
+
+func (match cveConfigurationMatch) TableName() string {
+	return "cve_configuration_matches"
+}
+
+// RemovedCVE represents a CVE that was present in a previous build of vulndb, but no longer is.
+type RemovedCVE struct {
+	CVEID     string `xorm:"cve_id"`
+	RemovedAt int64  `xorm:"removed_at"` // Time of the first build without the CVE.
+}
+
+func (cve RemovedCVE) TableName() string {
+	return "removed_cves"
+}
+
+// cpeDictionaryTitle represents a product title of the official CPE dictionary, with the version stripped.
+type cpeDictionaryTitle struct {
+	Vendor  string `xorm:"vendor"`
+	Product string `xorm:"product"`
+	Title   string `xorm:"title"` // e.g. "Adobe Acrobat Reader DC".
+}
+
+func (t cpeDictionaryTitle) TableName() string {
+	return "cpe_dictionary_titles"
+}
+
+// VendorCVSSEntry represents a CVSS3 score of a CVE assigned by a vendor (source), e.g. Red Hat or Microsoft.
 type VendorCVSSEntry struct {
-	Id                int64  `xorm:"pk autoincr 'id'"`
-	CVEID             string `xorm:"cve_id"`
-	CreatedAt         int64  `xorm:"created_at"`
-	LastModifiedAt    int64  `xorm:"last_modified_at"`
-	LastCrawleddAt    int64  `xorm:"last_crawled_at"`
-	CVSS3VectorString string `xorm:"cvss3_vector_string"`
-	Source            string `xorm:"source"`
+	Id                int64    `xorm:"pk autoincr 'id'"`
+	CVEID             string   `xorm:"cve_id"`
+	CreatedAt         int64    `xorm:"created_at"`
+	LastModifiedAt    int64    `xorm:"last_modified_at"` // Last time the vector or score changed.
+	LastCrawleddAt    int64    `xorm:"last_crawled_at"`  // Last time the entry was seen at the source.
+	CVSS3VectorString string   `xorm:"cvss3_vector_string"`
+	CVSS3BaseScore    *float64 `xorm:"cvss3_base_score"`
+	Source            string   `xorm:"source"`
 }
 
 func (VendorCVSSEntry) TableName() string {
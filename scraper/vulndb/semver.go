@@ -0,0 +1,169 @@
+package vulndb
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semVersion is a parsed semantic version.
+type semVersion struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	PreRelease []string // Pre-release identifiers, e.g. [rc 1] for 1.2.0-rc.1, nil for releases.
+	Build      string   // Build metadata, ignored in comparisons.
+}
+
+var (
+	// Semantic version per https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string.
+	reSemVer = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+	// Loose semantic version: optional v prefix, minor and patch, leading zeros and pre-release separated by
+	// "-", "." or nothing, e.g. v1.2, 1.20rc1, 5.2.0.RELEASE, 2.0-beta9, 10.0.0-M1.
+	reSemVerLoose = regexp.MustCompile(`^[vV]?(\d+)(?:\.(\d+))?(?:\.(\d+))?` +
+		`(?:[-.]?([a-zA-Z][0-9a-zA-Z.-]*|\d+[a-zA-Z][0-9a-zA-Z.-]*))?(?:\+([0-9a-zA-Z.-]+))?$`)
+
+	// Alphabetic and numeric runs of loose pre-release identifiers, e.g. beta9 -> beta, 9.
+	reSemVerLooseIdentifier = regexp.MustCompile(`[a-zA-Z]+|\d+`)
+)
+
+// parseSemVer parses the semantic version `ver`, returns false if not a valid semantic version.
+func parseSemVer(ver string) (semVersion, bool) {
+	var v semVersion
+	parts := reSemVer.FindStringSubmatch(strings.TrimSpace(ver))
+	if parts == nil {
+		return v, false
+	}
+	v.Major, _ = strconv.ParseUint(parts[1], 10, 64)
+	v.Minor, _ = strconv.ParseUint(parts[2], 10, 64)
+	v.Patch, _ = strconv.ParseUint(parts[3], 10, 64)
+	if len(parts[4]) > 0 {
+		v.PreRelease = strings.Split(parts[4], ".")
+	}
+	v.Build = parts[5]
+	return v, true
+}
+
+// parseSemVerLoose parses the loose semantic version `ver`, returns false if not a valid loose semantic version.
+// Pre-release identifiers are lowercased and split into alphabetic and numeric runs, e.g. RC1 -> rc, 1. The
+// release qualifiers RELEASE, Final and GA (e.g. 5.2.0.RELEASE) denote releases.
+func parseSemVerLoose(ver string) (semVersion, bool) {
+	var v semVersion
+	parts := reSemVerLoose.FindStringSubmatch(strings.TrimSpace(ver))
+	if parts == nil {
+		return v, false
+	}
+	var err error
+	if v.Major, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return v, false
+	}
+	if len(parts[2]) > 0 {
+		if v.Minor, err = strconv.ParseUint(parts[2], 10, 64); err != nil {
+			return v, false
+		}
+	}
+	if len(parts[3]) > 0 {
+		if v.Patch, err = strconv.ParseUint(parts[3], 10, 64); err != nil {
+			return v, false
+		}
+	}
+
+	preRelease := strings.ToLower(parts[4])
+	switch preRelease {
+	case "", "release", "final", "ga":
+	default:
+		v.PreRelease = reSemVerLooseIdentifier.FindAllString(preRelease, -1)
+	}
+	v.Build = parts[5]
+	return v, true
+}
+
+// Compare compares semantic version `v` against `another` by precedence and returns
+// 1 if `v` > `another`, 0 if equal, -1 if `v` < `another`. Build metadata is ignored.
+func (v semVersion) Compare(another semVersion) int {
+	for _, pair := range [][2]uint64{{v.Major, another.Major}, {v.Minor, another.Minor}, {v.Patch, another.Patch}} {
+		if pair[0] > pair[1] {
+			return 1
+		} else if pair[0] < pair[1] {
+			return -1
+		}
+	}
+
+	// A pre-release has lower precedence than the release.
+	switch {
+	case len(v.PreRelease) == 0 && len(another.PreRelease) == 0:
+		return 0
+	case len(v.PreRelease) == 0:
+		return 1
+	case len(another.PreRelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.PreRelease) && i < len(another.PreRelease); i++ {
+		if cmpVal := comparePreReleaseIdentifiers(v.PreRelease[i], another.PreRelease[i]); cmpVal != 0 {
+			return cmpVal
+		}
+	}
+	if len(v.PreRelease) > len(another.PreRelease) {
+		return 1
+	} else if len(v.PreRelease) < len(another.PreRelease) {
+		return -1
+	}
+	return 0
+}
+
+// comparePreReleaseIdentifiers compares the pre-release identifiers `a` and `b`: numeric identifiers numerically,
+// alphanumeric identifiers lexically and numeric identifiers lower than alphanumeric identifiers.
+func comparePreReleaseIdentifiers(a, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if aNum > bNum {
+			return 1
+		} else if aNum < bNum {
+			return -1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// VersionCompareSemVer compares `targetVer` against `templateVer` as semantic versions (SemVer 2.0.0) and returns
+// -1, 0, or 1 if the target version is smaller, equal or larger. Falls back to VersionCompare if either version is
+// not a semantic version.
+func VersionCompareSemVer(templateVer, targetVer string) int {
+	verTpl, okTpl := parseSemVer(templateVer)
+	verTgt, okTgt := parseSemVer(targetVer)
+	if !okTpl || !okTgt {
+		return VersionCompare(templateVer, targetVer)
+	}
+	return verTgt.Compare(verTpl)
+}
+
+// VersionCompareSemVerLoose compares `targetVer` against `templateVer` as loose semantic versions, allowing common
+// variants such as v prefixes, missing minor and patch versions, .RELEASE suffixes and pre-releases like -beta.2,
+// .RC1 or rc1, see parseSemVerLoose. Falls back to VersionCompare if either version is not a loose semantic version.
+func VersionCompareSemVerLoose(templateVer, targetVer string) int {
+	verTpl, okTpl := parseSemVerLoose(templateVer)
+	verTgt, okTgt := parseSemVerLoose(targetVer)
+	if !okTpl || !okTgt {
+		return VersionCompare(templateVer, targetVer)
+	}
+	return verTgt.Compare(verTpl)
+}
+
+// semVerWithPatch returns `ver` with the CPE update `patch` (e.g. rc1) appended as pre-release.
+func semVerWithPatch(ver, patch string) string {
+	if len(patch) == 0 || patch == "*" || patch == "-" {
+		return ver
+	}
+	return ver + "-" + patch
+}
@@ -0,0 +1,84 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionCompareSemVer(t *testing.T) {
+	testcases := []struct {
+		TemplateVer string
+		TargetVer   string
+		Expected    int
+	}{
+		// Precedence examples of SemVer 2.0.0 section 11.
+		{"1.0.0", "2.0.0", 1},
+		{"2.0.0", "2.1.0", 1},
+		{"2.1.0", "2.1.1", 1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", 1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", 1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", 1},
+		{"1.0.0-beta", "1.0.0-beta.2", 1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", 1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", 1},
+		{"1.0.0-rc.1", "1.0.0", 1},
+		{"1.0.0", "1.0.0-rc.1", -1},
+		// Build metadata is ignored.
+		{"1.0.0+20130313144700", "1.0.0+exp.sha.5114f85", 0},
+		{"1.0.0-beta+exp.sha.5114f85", "1.0.0-beta", 0},
+		// Generic comparison for non semantic versions.
+		{"1.2", "1.2.1", 1},
+	}
+
+	for _, tcase := range testcases {
+		require.Equal(t, tcase.Expected, VersionCompareSemVer(tcase.TemplateVer, tcase.TargetVer),
+			"%s vs %s", tcase.TemplateVer, tcase.TargetVer)
+	}
+}
+
+func TestVersionCompareSemVerLoose(t *testing.T) {
+	testcases := []struct {
+		TemplateVer string
+		TargetVer   string
+		Expected    int
+	}{
+		{"v1.20.0", "1.20.0", 0},
+		{"1.20", "1.20.0", 0},
+		{"1.20", "1.20rc1", -1},
+		{"1.20rc2", "1.20rc10", 1},
+		{"1.20beta1", "1.20rc1", 1},
+		{"5.2.0.RELEASE", "5.2.0", 0},
+		{"5.2.0.RELEASE", "5.2.0.RC1", -1},
+		{"5.2.0.M2", "5.2.0.RC1", 1},
+		{"4.1.42.Final", "4.1.43.Final", 1},
+		{"2.0-beta9", "2.0-rc1", 1},
+		{"2.0-beta9", "2.0-beta.10", 1},
+		{"2.0", "2.0-beta9", -1},
+		{"3.10.0b1", "3.10.0a7", -1},
+		{"10.0.0-M1", "10.0.0-m1", 0},
+		{"v1.20.0-rc.0", "v1.20.0-rc.1", 1},
+		// Generic comparison for versions that are not loose semantic versions.
+		{"1.2.3.4", "1.2.3.5", 1},
+	}
+
+	for _, tcase := range testcases {
+		require.Equal(t, tcase.Expected, VersionCompareSemVerLoose(tcase.TemplateVer, tcase.TargetVer),
+			"%s vs %s", tcase.TemplateVer, tcase.TargetVer)
+	}
+}
+
+func TestVersionCompareProductSemVer(t *testing.T) {
+	schemes := shippedVersionSchemes(t)
+	compare := func(vendor, product, templateVer, targetVer, templatePatch string) int {
+		return VersionCompareScheme(lookupVersionScheme(schemes, vendor, product), product, templateVer, targetVer, templatePatch, "")
+	}
+	// The generic comparison orders the release candidate after the release.
+	require.Equal(t, 1, compare("acme", "lib", "2.0", "2.0-rc1", ""))
+	require.Equal(t, -1, compare("apache", "log4j", "2.0", "2.0-rc1", ""))
+	// The CPE update is compared as pre-release.
+	require.Equal(t, 0, compare("apache", "log4j", "2.0", "2.0-rc1", "rc1"))
+	require.Equal(t, 1, compare("apache", "log4j", "2.0", "2.0-rc2", "rc1"))
+	require.Equal(t, 1, compare("nodejs", "node.js", "14.0.0", "14.0.0", "rc.1"))
+	require.Equal(t, VersionSchemeSemVerLoose, lookupVersionScheme(schemes, "apache", "log4j"))
+}
@@ -25,9 +25,22 @@ type VulnDBSession struct {
 	// Cached CVE results.
 	cached map[string][]CVEMatch
 
+	// Vendor CVSS policy for reported advisories.
+	cvssPolicy CVSSPolicy
+
+	// Report rejected CVEs in MatchCVEs.
+	includeRejected bool
+
+	// Fuzzy product resolution acceptance threshold, 0 if disabled, and the low confidence resolutions by title.
+	fuzzyThreshold float64
+	fuzzyReport    map[string]FuzzyResolution
+
 	// Product and vendor cache by id.
 	productCache map[int64]*vulndbProduct
 	vendorCache  map[int64]*VulndbVendor
+
+	// Version scheme assignments, most specific product glob first, loaded on first use.
+	versionSchemes []vulndbVersionScheme
 }
 
 // NewSessionWrapper returns an initializes VulnDBSession for `orm`.
@@ -47,6 +60,7 @@ func NewSessionWrapper(orm *xorm.Engine) *VulnDBSession {
 	sw.insertStatsThreshold = 100000
 
 	sw.cached = map[string][]CVEMatch{}
+	sw.fuzzyReport = map[string]FuzzyResolution{}
 	sw.productCache = map[int64]*vulndbProduct{}
 	sw.vendorCache = map[int64]*VulndbVendor{}
 
@@ -197,6 +211,16 @@ func (sw *VulnDBSession) Find(rowsSlicePtr interface{}, condiBean ...interface{}
 	return session.Find(rowsSlicePtr, condiBean...)
 }
 
+// IsTableExist returns true if the table of `beanOrTableName` exists, including tables created in the current
+// session.
+func (sw *VulnDBSession) IsTableExist(beanOrTableName interface{}) (bool, error) {
+	session, err := sw.session()
+	if err != nil {
+		return false, err
+	}
+	return session.IsTableExist(beanOrTableName)
+}
+
 func (sw *VulnDBSession) Commit() error {
 	if sw.curSession != nil {
 		err := sw.curSession.Commit()
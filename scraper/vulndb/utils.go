@@ -6,12 +6,13 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/parnurzeal/gorequest"
 
-	"net/url"
-	"strings"
+	"nanscraper/cpe"
 )
 
 type CPEParts struct {
@@ -30,126 +31,139 @@ type CPEParts struct {
 	Other     string
 }
 
-// ParseCPE parses CPE strings of format 2.2 and 2.3 into CPEParts.
+// ParseCPE parses CPE strings of format 2.2 and 2.3 into CPEParts with plain (unquoted) attribute values.
+// Attributes with the logical value ANY are "*" for CPE 2.3 and empty for CPE 2.2, NA attributes are "-".
+// Escaped parentheses of CPE 2.2 (%5c%28, %5c%29) are plain parentheses, and wildcards embedded in CPE 2.3
+// values, as in NVD names (e.g. 1.*.2), are kept as in the value.
 // Format 2.2: "cpe:/a:vendor:product:version:update:edition:language"
 // Format 2.3: "cpe:2.3:part:vendor:product:version:update:edition:language:sw_edition:target_sw:target_hw:other"
-func ParseCPE(cpe string) (CPEParts, error) {
+func ParseCPE(name string) (CPEParts, error) {
 	var cpeParts CPEParts
 
-	parts, err := tokenizeString(cpe, ':', '\\')
-	if err != nil {
-		return cpeParts, err
-	}
-	if len(parts) < 4 {
-		return cpeParts, errors.New("invalid length")
-	}
-
-	if parts[1] == "2.3" {
-		return parseCPE23(cpe)
-	}
-
-	cpeParts.CPEVersion = 22 // CPE 2.2.
-	switch parts[1] {
-	case "/a":
-		cpeParts.Systype = "a"
-	case "/o":
-		cpeParts.Systype = "o"
-	case "/h":
-		cpeParts.Systype = "h"
-	default:
-		return cpeParts, errors.New("invalid type")
-	}
-	cpeParts.Vendor = cpeDecode(parts[2])
-	cpeParts.Product = cpeDecode(parts[3])
-
-	if len(parts) > 4 {
-		cpeParts.Version = cpeDecode(parts[4])
-	}
-	if len(parts) > 5 {
-		cpeParts.Patch = cpeDecode(parts[5])
+	if !strings.HasPrefix(name, "cpe:2.3:") {
+		name = reEscapedParenthesis.ReplaceAllString(name, "$1")
 	}
-	if len(parts) > 6 {
-		cpeParts.Edition = cpeDecode(parts[6])
+	wfn, err := cpe.Unbind(name)
+	if err == cpe.ErrInvalidWildcard {
+		wfn, err = cpe.Unbind(quoteEmbeddedWildcards(name))
 	}
-	if len(parts) > 7 {
-		cpeParts.Language = cpeDecode(parts[7])
-	}
-
-	return cpeParts, nil
-}
-
-// parseCPE23 parses CPE 2.3 string.
-func parseCPE23(cpe23str string) (CPEParts, error) {
-	var cpeParts CPEParts
-
-	parts, err := tokenizeString(cpe23str, ':', '\\')
 	if err != nil {
 		return cpeParts, err
 	}
-	if len(parts) != 13 {
-		log.Debugf("Broken CPE23 - skipping (got %d)", len(parts))
-		log.Debugf("Broken CPE23: '%s'", cpe23str)
-		return cpeParts, errors.New("invalid cpe23")
+
+	anyValue := "*"
+	cpeParts.CPEVersion = 23 // CPE 2.3.
+	if !strings.HasPrefix(name, "cpe:2.3:") {
+		anyValue = ""
+		cpeParts.CPEVersion = 22 // CPE 2.2.
+		if wfn.Part == cpe.Any {
+			return cpeParts, errors.New("invalid type")
+		}
 	}
-	if parts[0] != "cpe" && parts[1] != "2.3" {
-		log.Debugf("Invalid CPE2.3: '%s'", cpe23str)
-		return cpeParts, errors.New("invalid cpe23")
+	value := func(v string) string {
+		switch v {
+		case cpe.Any:
+			return anyValue
+		case cpe.NA:
+			return v
+		}
+		return cpe.Unquote(v)
 	}
 
-	cpeParts.CPEVersion = 23 // CPE 2.3.
-	cpeParts.Systype = parts[2]
-	cpeParts.Vendor = cpeDecode(parts[3])
-	cpeParts.Product = cpeDecode(parts[4])
-	cpeParts.Version = cpeDecode(parts[5])
-	cpeParts.Patch = cpeDecode(parts[6])
-	cpeParts.Edition = cpeDecode(parts[7])
-	cpeParts.Language = cpeDecode(parts[8])
-	cpeParts.SWEdition = cpeDecode(parts[9])
-	cpeParts.TargetSW = cpeDecode(parts[10])
-	cpeParts.TargetHW = cpeDecode(parts[11])
-	cpeParts.Other = cpeDecode(parts[12])
+	cpeParts.Systype = value(wfn.Part)
+	cpeParts.Vendor = value(wfn.Vendor)
+	cpeParts.Product = value(wfn.Product)
+	cpeParts.Version = value(wfn.Version)
+	cpeParts.Patch = value(wfn.Update)
+	cpeParts.Edition = value(wfn.Edition)
+	cpeParts.Language = value(wfn.Language)
+	cpeParts.SWEdition = value(wfn.SWEdition)
+	cpeParts.TargetSW = value(wfn.TargetSW)
+	cpeParts.TargetHW = value(wfn.TargetHW)
+	cpeParts.Other = value(wfn.Other)
 
 	return cpeParts, nil
 }
 
-// cpeDecode decodes percentage encoded values.
-func cpeDecode(cpe string) string {
-	if len(cpe) == 0 {
-		return ""
+// reEscapedParenthesis matches the escaped parentheses of CPE 2.2 URIs, e.g. bar%5c%28x%5c%29.
+var reEscapedParenthesis = regexp.MustCompile(`(?i)%5c(%28|%29)`)
+
+// quoteEmbeddedWildcards returns the CPE 2.3 formatted string `fs` with the wildcards "*" and "?" quoted, other
+// than the values of only "*" (ANY).
+func quoteEmbeddedWildcards(fs string) string {
+	var sb strings.Builder
+	var value []rune
+	flush := func() {
+		if string(value) == "*" {
+			sb.WriteString("*")
+		} else {
+			for i := 0; i < len(value); i++ {
+				switch value[i] {
+				case '\\':
+					sb.WriteRune(value[i])
+					if i+1 < len(value) {
+						i++
+						sb.WriteRune(value[i])
+					}
+					continue
+				case '*', '?':
+					sb.WriteRune('\\')
+				}
+				sb.WriteRune(value[i])
+			}
+		}
+		value = value[:0]
 	}
-
-	// Special cases for handling escaped symbols "\(" and "\)" removing the slash.
-	cpe = strings.Replace(cpe, `%5c%28`, `%28`, -1)
-	cpe = strings.Replace(cpe, `%5c%29`, `%29`, -1)
-	cpe = strings.Replace(cpe, `\(`, `(`, -1)
-	cpe = strings.Replace(cpe, `\)`, `)`, -1)
-	s, _ := url.PathUnescape(cpe)
-	return s
-}
-
-// tokenizeString splits a string at each non-escaped occurrence of a separator character.
-func tokenizeString(s string, sep, escape rune) (tokens []string, err error) {
-	var runes []rune
-	inEscape := false
-	for _, r := range s {
+	escaped := false
+	for _, r := range fs {
 		switch {
-		case inEscape:
-			inEscape = false
-			fallthrough
-		default:
-			runes = append(runes, r)
-		case r == escape:
-			inEscape = true
-		case r == sep:
-			tokens = append(tokens, string(runes))
-			runes = runes[:0]
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			flush()
+			sb.WriteRune(r)
+			continue
 		}
+		value = append(value, r)
 	}
-	tokens = append(tokens, string(runes))
-	if inEscape {
-		err = errors.New("invalid terminal escape")
+	flush()
+	return sb.String()
+}
+
+// WFN returns the CPE name as a CPE 2.3 Well-Formed Name, e.g. for binding to a formatted string. Unquoted
+// wildcards "*" and "?" at the beginning and end of the values are kept as wildcards.
+func (p CPEParts) WFN() cpe.WFN {
+	value := func(v string) string {
+		switch v {
+		case "", "*":
+			return cpe.Any
+		case "-":
+			return cpe.NA
+		}
+		// Quote the value except for the leading and trailing wildcards.
+		body := strings.TrimRight(strings.TrimLeft(v, "*?"), "*?")
+		if len(body) == 0 {
+			return cpe.Quote(v)
+		}
+		start := strings.Index(v, body)
+		return v[:start] + cpe.Quote(body) + v[start+len(body):]
+	}
+
+	return cpe.WFN{
+		Part:      value(p.Systype),
+		Vendor:    value(p.Vendor),
+		Product:   value(p.Product),
+		Version:   value(p.Version),
+		Update:    value(p.Patch),
+		Edition:   value(p.Edition),
+		Language:  value(p.Language),
+		SWEdition: value(p.SWEdition),
+		TargetSW:  value(p.TargetSW),
+		TargetHW:  value(p.TargetHW),
+		Other:     value(p.Other),
 	}
-	return tokens, err
 }
 
 // FetchURL returns HTTP response body with retry
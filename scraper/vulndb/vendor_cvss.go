@@ -0,0 +1,237 @@
+package vulndb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"nanscraper/common"
+	"nanscraper/cvss"
+)
+
+// CVSSPolicy determines if and how vendor CVSS scores (vendor_cvss_entries) are reported on advisories by
+// MatchCVEs and GetAdvisory. The zero value reports NVD scores only.
+type CVSSPolicy struct {
+	// Sources lists the vendor sources in order of precedence, e.g. SourceRedhatOVAL, SourceMSRC, SourceApple.
+	Sources []string
+	// PreferVendor replaces the NVD CVSS3 score, vector and metrics by the vendor ones when available, instead of
+	// only reporting them next to the NVD score in NVDCVEAdvisory.VendorCVSS.
+	PreferVendor bool
+}
+
+// SetCVSSPolicy sets the vendor CVSS `policy` of the session, clearing cached results.
+func (sw *VulnDBSession) SetCVSSPolicy(policy CVSSPolicy) {
+	sw.cvssPolicy = policy
+	sw.cached = map[string][]CVEMatch{}
+}
+
+// UpsertVendorCVSSEntry records the CVSS3 `vector` (and `baseScore` if known) of `cveID` from vendor `source`,
+// as crawled at `crawledAt`. An existing entry of the source is updated, where last_modified_at only changes
+// when the vector or score has changed.
+func UpsertVendorCVSSEntry(session *VulnDBSession, cveID, source, vector string, baseScore *float64, crawledAt int64) error {
+	var entry VendorCVSSEntry
+	has, err := session.Where("cve_id = ? AND source = ?", cveID, source).Get(&entry)
+	if err != nil {
+		return err
+	}
+	if !has {
+		entry = VendorCVSSEntry{
+			CVEID:             cveID,
+			CreatedAt:         crawledAt,
+			LastModifiedAt:    crawledAt,
+			LastCrawleddAt:    crawledAt,
+			CVSS3VectorString: vector,
+			CVSS3BaseScore:    baseScore,
+			Source:            source,
+		}
+		return session.Insert(&entry)
+	}
+
+	lastModifiedAt := entry.LastModifiedAt
+	scoreChanged := (entry.CVSS3BaseScore == nil) != (baseScore == nil) ||
+		(baseScore != nil && *entry.CVSS3BaseScore != *baseScore)
+	if entry.CVSS3VectorString != vector || scoreChanged {
+		lastModifiedAt = crawledAt
+	}
+	return session.Exec(`
+UPDATE vendor_cvss_entries SET last_crawled_at = ?, last_modified_at = ?, cvss3_vector_string = ?, cvss3_base_score = ?
+WHERE id = ?`, crawledAt, lastModifiedAt, vector, baseScore, entry.Id)
+}
+
+// parseScoredCVSS3 parses a CVSS3 score prefixed vector as used by Red Hat, e.g.
+// 7.5/CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N. Returns false if `value` is not in that form.
+func parseScoredCVSS3(value string) (float64, string, bool) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[1], "CVSS:3.") {
+		return 0, "", false
+	}
+	score, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return score, parts[1], true
+}
+
+// msrcCVSSResult represents the CVSS scores of the MSRC data. Decoded separately from msrcapi.Result as only
+// the per product scores are needed.
+type msrcCVSSResult struct {
+	Vulnerabilities map[string][]struct {
+		Product      string
+		BaseScore    float64
+		VectorString string
+		Vector       string
+	}
+}
+
+// processMSRCCVSS stores the highest scoring product CVSS3 vector of each CVE in the MSRC data `content` as
+// a vendor CVSS entry.
+func processMSRCCVSS(sessionw *VulnDBSession, content []byte, crawledAt int64) error {
+	var data msrcCVSSResult
+	if err := json.Unmarshal(content, &data); err != nil {
+		return err
+	}
+
+	for cveID, products := range data.Vulnerabilities {
+		var vector string
+		var score float64
+		for _, p := range products {
+			v := p.VectorString
+			if len(v) == 0 {
+				v = p.Vector
+			}
+			if len(v) == 0 {
+				continue
+			}
+			if len(vector) == 0 || p.BaseScore > score {
+				vector, score = v, p.BaseScore
+			}
+		}
+		if len(vector) == 0 {
+			continue
+		}
+
+		var baseScore *float64
+		if score > 0 {
+			baseScore = &score
+		}
+		err := UpsertVendorCVSSEntry(sessionw, cveID, SourceMSRC, vector, baseScore, crawledAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cvss3ScoreSQL returns the SQL expression of the CVSS3 base score of the nvd_cve_advisories `table` (name or
+// alias) according to the session CVSS policy, as set by applyCVSSPolicy, with its parameters.
+func (sw *VulnDBSession) cvss3ScoreSQL(table string) (string, []interface{}) {
+	policy := sw.cvssPolicy
+	if !policy.PreferVendor || len(policy.Sources) == 0 {
+		return table + ".cvss3_base_score", nil
+	}
+
+	var params []interface{}
+	precedenceSQL := "CASE vce.source"
+	for i, source := range policy.Sources {
+		precedenceSQL += fmt.Sprintf(" WHEN ? THEN %d", i)
+		params = append(params, source)
+	}
+	precedenceSQL += " END"
+	for _, source := range policy.Sources {
+		params = append(params, source)
+	}
+	// The score of the vendor entry of highest precedence, the NVD score if the entry has no score.
+	return "COALESCE((SELECT vce.cvss3_base_score FROM vendor_cvss_entries vce WHERE vce.cve_id = " + table +
+		".cve_id AND " + common.MakeInSql("vce.source", len(policy.Sources)) + " ORDER BY " + precedenceSQL +
+		" LIMIT 1), " + table + ".cvss3_base_score)", params
+}
+
+// baseScoreSQL returns the SQL expression of the base score of the latest CVSS version of the nvd_cve_advisories
+// `table` (name or alias), as NVDCVEAdvisory.BaseScore with the CVSS3 score of cvss3ScoreSQL, with its parameters.
+func (sw *VulnDBSession) baseScoreSQL(table string) (string, []interface{}) {
+	cvss3SQL, params := sw.cvss3ScoreSQL(table)
+	return "COALESCE(" + table + ".cvss4_base_score, " + cvss3SQL + ", " + table + ".cvss2_base_score, 0)", params
+}
+
+// applyCVSSPolicy sets the vendor CVSS entries on `advisories` according to the session CVSS policy.
+func applyCVSSPolicy(session *VulnDBSession, advisories []NVDCVEAdvisory) error {
+	policy := session.cvssPolicy
+	if len(policy.Sources) == 0 || len(advisories) == 0 {
+		return nil
+	}
+
+	precedence := map[string]int{}
+	for i, source := range policy.Sources {
+		precedence[source] = i
+	}
+
+	advisoryIDs := make([]int64, len(advisories))
+	for i, advisory := range advisories {
+		advisoryIDs[i] = advisory.Id
+	}
+	best := map[string]*VendorCVSSEntry{}
+	err := common.ProcessChunks(advisoryIDs, 900, func(start, end int) error {
+		whereSQL := common.MakeInSql("cve_id", end-start) + " AND " + common.MakeInSql("source", len(policy.Sources))
+		params := []interface{}{}
+		for _, advisory := range advisories[start:end] {
+			params = append(params, advisory.CVEID)
+		}
+		for _, source := range policy.Sources {
+			params = append(params, source)
+		}
+
+		var entries []VendorCVSSEntry
+		err := session.Where(whereSQL, params...).Find(&entries)
+		if err != nil {
+			return err
+		}
+		for i, entry := range entries {
+			cur, has := best[entry.CVEID]
+			if !has || precedence[entry.Source] < precedence[cur.Source] {
+				best[entry.CVEID] = &entries[i]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range advisories {
+		entry, has := best[advisories[i].CVEID]
+		if !has {
+			continue
+		}
+		advisories[i].VendorCVSS = entry
+		if policy.PreferVendor && entry.CVSS3BaseScore != nil {
+			advisories[i].setVendorCVSS3(*entry)
+		}
+	}
+	return nil
+}
+
+// setVendorCVSS3 replaces the NVD CVSS3 score, vector and metrics of the advisory by those of the vendor `entry`.
+// The metrics are derived from the vendor vector, unknown if the vector is invalid. The NVD exploitability
+// score is cleared.
+func (cve *NVDCVEAdvisory) setVendorCVSS3(entry VendorCVSSEntry) {
+	score := *entry.CVSS3BaseScore
+	vector := entry.CVSS3VectorString
+	cve.CVSS3BaseScore = &score
+	cve.CVSS3VectorString = &vector
+	cve.CVSS3ExploitabilityScore = nil
+
+	v, err := cve.CVSS3Vector()
+	if err != nil {
+		log.Debugf("%s: Invalid %s CVSS3 vector: %v", cve.CVEID, entry.Source, err)
+		v = &cvss.CVSS3Vector{}
+	}
+	cve.CVSS3AttackVector = cvss3AttackVector.valueOf(v.AttackVector)
+	cve.CVSS3AttackComplexity = cvss3AttackComplexity.valueOf(v.AttackComplexity)
+	cve.CVSS3PrivilegesRequired = cvss3PrivilegesRequired.valueOf(v.PrivilegesRequired)
+	cve.CVSS3UserInteraction = cvss3UserInteraction.valueOf(v.UserInteraction)
+	cve.CVSS3Scope = cvss3Scope.valueOf(v.Scope)
+	cve.CVSS3ConfidentialityImpact = cvss3ConfidentialityImpact.valueOf(v.Confidentiality)
+	cve.CVSS3IntegrityImpact = cvss3IntegrityImpact.valueOf(v.Integrity)
+	cve.CVSS3AvailabilityImpact = cvss3AvailabilityImpact.valueOf(v.Availability)
+}
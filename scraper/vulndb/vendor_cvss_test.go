@@ -0,0 +1,79 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScoredCVSS3(t *testing.T) {
+	testcases := []struct {
+		Value  string
+		Score  float64
+		Vector string
+		OK     bool
+	}{
+		{"7.5/CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", 7.5, "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", true},
+		{"6.3/CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:L/I:L/A:L", 6.3, "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:L/I:L/A:L", true},
+		{"5.1/AV:N/AC:H/Au:N/C:P/I:P/A:P", 0, "", false}, // CVSS2.
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", 0, "", false},
+		{"", 0, "", false},
+	}
+
+	for _, tcase := range testcases {
+		score, vector, ok := parseScoredCVSS3(tcase.Value)
+		require.Equal(t, tcase.OK, ok, "Value: %s", tcase.Value)
+		require.Equal(t, tcase.Score, score, "Value: %s", tcase.Value)
+		require.Equal(t, tcase.Vector, vector, "Value: %s", tcase.Value)
+	}
+}
+
+func TestSetVendorCVSS3(t *testing.T) {
+	nvdScore := 9.8
+	nvdVector := "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+	network, low, none, high, exploitability := AttackVectorTypeNetwork, AttackComplexityTypeLow, PrivilegesRequiredTypeNone, CiaTypeHigh, 3
+	advisory := NVDCVEAdvisory{
+		CVEID:                      "CVE-2021-1",
+		CVSS3BaseScore:             &nvdScore,
+		CVSS3VectorString:          &nvdVector,
+		CVSS3AttackVector:          &network,
+		CVSS3AttackComplexity:      &low,
+		CVSS3PrivilegesRequired:    &none,
+		CVSS3ConfidentialityImpact: &high,
+		CVSS3IntegrityImpact:       &high,
+		CVSS3AvailabilityImpact:    &high,
+		CVSS3ExploitabilityScore:   &exploitability,
+	}
+
+	vendorScore := 5.3
+	preferred := advisory
+	preferred.setVendorCVSS3(VendorCVSSEntry{Source: SourceRedhatOVAL, CVSS3BaseScore: &vendorScore,
+		CVSS3VectorString: "CVSS:3.1/AV:L/AC:H/PR:L/UI:R/S:C/C:L/I:N/A:N"})
+	require.Equal(t, 5.3, *preferred.CVSS3BaseScore)
+	require.Equal(t, "CVSS:3.1/AV:L/AC:H/PR:L/UI:R/S:C/C:L/I:N/A:N", *preferred.CVSS3VectorString)
+	require.Equal(t, AttackVectorTypeLocal, *preferred.CVSS3AttackVector)
+	require.Equal(t, AttackComplexityTypeHigh, *preferred.CVSS3AttackComplexity)
+	require.Equal(t, PrivilegesRequiredTypeLow, *preferred.CVSS3PrivilegesRequired)
+	require.Equal(t, UserInteractionTypeRequired, *preferred.CVSS3UserInteraction)
+	require.Equal(t, ScopeTypeChanged, *preferred.CVSS3Scope)
+	require.Equal(t, CiaTypeLow, *preferred.CVSS3ConfidentialityImpact)
+	require.Equal(t, CiaTypeNone, *preferred.CVSS3IntegrityImpact)
+	require.Equal(t, CiaTypeNone, *preferred.CVSS3AvailabilityImpact)
+	require.Nil(t, preferred.CVSS3ExploitabilityScore)
+	// The NVD advisory is left as is.
+	require.Equal(t, AttackVectorTypeNetwork, *advisory.CVSS3AttackVector)
+
+	// Red Hat score prefixed vectors.
+	preferred = advisory
+	preferred.setVendorCVSS3(VendorCVSSEntry{Source: SourceRedhatOVAL, CVSS3BaseScore: &vendorScore,
+		CVSS3VectorString: "5.3/CVSS:3.0/AV:A/AC:L/PR:N/UI:N/S:U/C:N/I:L/A:N"})
+	require.Equal(t, AttackVectorTypeAdjacentNetwork, *preferred.CVSS3AttackVector)
+	require.Equal(t, CiaTypeLow, *preferred.CVSS3IntegrityImpact)
+
+	// Metrics unknown for invalid vendor vectors.
+	preferred = advisory
+	preferred.setVendorCVSS3(VendorCVSSEntry{Source: SourceMSRC, CVSS3BaseScore: &vendorScore, CVSS3VectorString: "CVSS:3.1/AV:N"})
+	require.Equal(t, 5.3, *preferred.CVSS3BaseScore)
+	require.Nil(t, preferred.CVSS3AttackVector)
+	require.Nil(t, preferred.CVSS3AvailabilityImpact)
+}
@@ -109,6 +109,8 @@ func PatchCompare(templatePatch string, targetPatch string) int {
 
 // VersionCompareProduct compares versions for a specific `product` from `vendor`.
 // 2 is returned if the versions are not compatible/i.e. should not be matched.
+// Matching compares with the version scheme assigned to the product by the vulndb instead (see
+// CreateDBParams.VersionSchemesPath and VersionCompareScheme).
 func VersionCompareProduct(vendor, product, templateVer, targetVer string, templatePatch string, targetPatch string) int {
 	var cmpVal int
 	switch vendor {
@@ -430,6 +432,9 @@ type junosVersion struct {
 	Spin               string // e.g. for 12.2R6.1 is '1', for 12.1X44-D10.4 is '4'
 }
 
+// reJunosTrainBase matches the base of the Junos releases of a major and minor, e.g. 18.4.
+var reJunosTrainBase = regexp.MustCompile(`^\d+\.\d$`)
+
 var reJunosVersion = regexp.MustCompile(`([\d]+)\.([\d+])([a-zA-Z]+)([\d]+)\-?([a-zA-Z][\d]+)?\.?([\d+])?`)
 
 func ParseJunosVersion(raw string) (ver junosVersion, found bool) {
@@ -454,12 +459,19 @@ func ParseJunosVersion(raw string) (ver junosVersion, found bool) {
 // 1 if `v` > `another`, 0 if equal, -1 if `v` < `another`.
 // 2 if the versions are incompatible, i.e. Major, Minor and Train are not identical.
 func (v junosVersion) Compare(another junosVersion) int {
+	return v.compare(another, false)
+}
+
+// compare compares as Compare. If `sequential` is set, the regular releases (R) of the same major and minor are
+// compatible, as they are sequential: 18.4R1 < 18.4R2-S1 < 18.4R3.
+func (v junosVersion) compare(another junosVersion, sequential bool) int {
 	// Requires major, minor, type and build to be equal, otherwise incompatiable.
 	// e.g. 15.1X48-D160 and 15.1X49-D20 are incompatible, whereas
 	// 15.1X49-D10 < 15.1X49-D160.
 	// Too strict? Otherwise can lead to false positives.  Typically for Juniper Junos there should
 	// be one entry for each build specifying the lowest patch that is vulnerable.
-	if v.Major != another.Major || v.Minor != another.Minor || v.Type != another.Type || v.Build != another.Build {
+	if v.Major != another.Major || v.Minor != another.Minor || v.Type != another.Type ||
+		(v.Build != another.Build && !(sequential && v.Type == "R")) {
 		return 2 // incompatible versions for automatic comparison
 	}
 
@@ -527,8 +539,20 @@ func (v junosVersion) Compare(another junosVersion) int {
 	return 0
 }
 
-// VersionCompareJuniperJunos compares versions for Juniper Junos products.
+// VersionCompareJuniperJunos compares versions for Juniper Junos products. A template of only the major and minor
+// (train base, e.g. 18.4) is before all the releases of the major and minor, as used for range lower bounds.
 func VersionCompareJuniperJunos(templateVer, targetVer, templatePatch, targetPatch string) int {
+	return versionCompareJunos(templateVer, targetVer, templatePatch, targetPatch, false)
+}
+
+// versionCompareJunosRelease compares as VersionCompareJuniperJunos, but with the regular releases (R) of the same
+// major and minor being sequential (see junosVersion.compare). Only used for the upper bounds of the ranges starting
+// at a train base (see jsaAdvisory.rangeStart), e.g. 18.4R1-S3 is before 18.4R2-S7 in the range 18.4 to 18.4R2-S7.
+func versionCompareJunosRelease(templateVer, targetVer, templatePatch, targetPatch string) int {
+	return versionCompareJunos(templateVer, targetVer, templatePatch, targetPatch, true)
+}
+
+func versionCompareJunos(templateVer, targetVer, templatePatch, targetPatch string, sequential bool) int {
 	templateVer = strings.ToUpper(templateVer)
 	templatePatch = strings.ToUpper(templatePatch)
 	targetVer = strings.ToUpper(targetVer)
@@ -543,6 +567,14 @@ func VersionCompareJuniperJunos(templateVer, targetVer, templatePatch, targetPat
 		tgtVer.MaintenanceRelease = targetPatch
 	}
 
+	if !tplMatch && tgtMatch && reJunosTrainBase.MatchString(templateVer) {
+		// Train base, e.g. 18.4: all releases of the major and minor are after it.
+		if templateVer == tgtVer.Major+"."+tgtVer.Minor {
+			return 1
+		}
+		return 2
+	}
+
 	compatible := false
 	if tplMatch && tgtMatch {
 		compatible = true
@@ -552,5 +584,227 @@ func VersionCompareJuniperJunos(templateVer, targetVer, templatePatch, targetPat
 		return 2 // Not compatible, cannot compare.
 	}
 
-	return tgtVer.Compare(tplVer)
+	return tgtVer.compare(tplVer, sequential)
+}
+
+// compareVersionNumbers compares the numeric version components `v` against `another` and returns
+// 1 if `v` > `another`, 0 if equal, -1 if `v` < `another`. Missing components are 0.
+func compareVersionNumbers(v, another []int) int {
+	for i := 0; i < len(v) || i < len(another); i++ {
+		vVal, anotherVal := 0, 0
+		if i < len(v) {
+			vVal = v[i]
+		}
+		if i < len(another) {
+			anotherVal = another[i]
+		}
+		if vVal > anotherVal {
+			return 1
+		} else if vVal < anotherVal {
+			return -1
+		}
+	}
+	return 0
+}
+
+// atoiParts returns the decimal strings `parts` as integers, empty parts as 0.
+func atoiParts(parts ...string) []int {
+	var vals []int
+	for _, part := range parts {
+		val, _ := strconv.Atoi(part)
+		vals = append(vals, val)
+	}
+	return vals
+}
+
+// fortiOSVersion represents the version format of Fortinet FortiOS firmware, e.g. 7.0.12 build0523.
+type fortiOSVersion struct {
+	Numbers []int // e.g. for 7.0.12 build0523 is [7 0 12]
+	Build   int   // e.g. for 7.0.12 build0523 is 523, -1 if not specified
+}
+
+var (
+	// FortiOS version: e.g. 7.0.12, v7.0.12 build0523 or v7.0.12,build0523.
+	reFortiOSVersion = regexp.MustCompile(`(?i)^v?(\d+)\.(\d+)(?:\.(\d+))?(?:[\s,_-]*b(?:uild)?\s*(\d+))?$`)
+	// FortiOS build as CPE update: e.g. build0523 or b0523.
+	reFortiOSBuild = regexp.MustCompile(`(?i)^b(?:uild)?\s*(\d+)$`)
+)
+
+// parseFortiOSVersion parses the FortiOS version `verstr`, the build may be given by the CPE update `patch`.
+func parseFortiOSVersion(verstr, patch string) (fortiOSVersion, bool) {
+	parts := reFortiOSVersion.FindStringSubmatch(strings.TrimSpace(verstr))
+	if parts == nil {
+		return fortiOSVersion{}, false
+	}
+	ver := fortiOSVersion{Numbers: atoiParts(parts[1:4]...), Build: -1}
+	build := parts[4]
+	if len(build) == 0 {
+		if patchParts := reFortiOSBuild.FindStringSubmatch(strings.TrimSpace(patch)); patchParts != nil {
+			build = patchParts[1]
+		}
+	}
+	if len(build) > 0 {
+		ver.Build, _ = strconv.Atoi(build)
+	}
+	return ver, true
+}
+
+// Compare compares FortiOS version `v` against `another` and returns 1 if `v` > `another`, 0 if equal, -1 if
+// `v` < `another`. Builds are only compared if specified for both.
+func (v fortiOSVersion) Compare(another fortiOSVersion) int {
+	if cmpVal := compareVersionNumbers(v.Numbers, another.Numbers); cmpVal != 0 {
+		return cmpVal
+	}
+	if v.Build < 0 || another.Build < 0 {
+		return 0
+	}
+	return compareVersionNumbers([]int{v.Build}, []int{another.Build})
+}
+
+// VersionCompareFortiOS compares versions for Fortinet FortiOS products, e.g. 7.0.12 build0523.
+// Falls back to generic version handling if either version is not a FortiOS version.
+func VersionCompareFortiOS(templateVer, targetVer, templatePatch, targetPatch string) int {
+	verTpl, okTpl := parseFortiOSVersion(templateVer, templatePatch)
+	verTgt, okTgt := parseFortiOSVersion(targetVer, targetPatch)
+	if !okTpl || !okTgt {
+		return VersionCompare(templateVer, targetVer)
+	}
+	return verTgt.Compare(verTpl)
+}
+
+// PAN-OS version: e.g. 10.1.6, 10.1.6-h6 or 10.1.6h6. Hotfix as CPE update: h6.
+var (
+	rePANOSVersion = regexp.MustCompile(`(?i)^(\d+)\.(\d+)(?:\.(\d+))?(?:-?h(\d+))?$`)
+	rePANOSHotfix  = regexp.MustCompile(`(?i)^h(?:otfix)?[_-]?(\d+)$`)
+)
+
+// parsePANOSVersion parses the PAN-OS version `verstr` into the version numbers and hotfix (0 for the base
+// release), the hotfix may be given by the CPE update `patch`.
+func parsePANOSVersion(verstr, patch string) ([]int, bool) {
+	parts := rePANOSVersion.FindStringSubmatch(strings.TrimSpace(verstr))
+	if parts == nil {
+		return nil, false
+	}
+	hotfix := parts[4]
+	if len(hotfix) == 0 {
+		if patchParts := rePANOSHotfix.FindStringSubmatch(strings.TrimSpace(patch)); patchParts != nil {
+			hotfix = patchParts[1]
+		}
+	}
+	return atoiParts(parts[1], parts[2], parts[3], hotfix), true
+}
+
+// VersionComparePANOS compares versions for Palo Alto Networks PAN-OS products. Hotfix releases (10.1.6-h6) are
+// ordered after their base release and before the next maintenance release.
+// Falls back to generic version handling if either version is not a PAN-OS version.
+func VersionComparePANOS(templateVer, targetVer, templatePatch, targetPatch string) int {
+	verTpl, okTpl := parsePANOSVersion(templateVer, templatePatch)
+	verTgt, okTgt := parsePANOSVersion(targetVer, targetPatch)
+	if !okTpl || !okTgt {
+		return VersionCompare(templateVer, targetVer)
+	}
+	return compareVersionNumbers(verTgt, verTpl)
+}
+
+// F5 BIG-IP version: e.g. 16.1.3.4, with engineering hotfix: 16.1.3.4 EHF2, 16.1.3.4-eng-hf2.
+var (
+	reBIGIPVersion = regexp.MustCompile(`(?i)^(\d+)\.(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:[\s_-]*(?:e(?:ng(?:ineering)?)?[\s_-]*)?(?:hf|hotfix)[\s_-]*(\d+))?$`)
+	reBIGIPHotfix  = regexp.MustCompile(`(?i)^(?:e(?:ng(?:ineering)?)?[\s_-]*)?(?:hf|hotfix)[\s_-]*(\d+)$`)
+)
+
+// parseBIGIPVersion parses the BIG-IP version `verstr` into the version numbers and engineering hotfix (0 if
+// none), the hotfix may be given by the CPE update `patch`.
+func parseBIGIPVersion(verstr, patch string) ([]int, bool) {
+	parts := reBIGIPVersion.FindStringSubmatch(strings.TrimSpace(verstr))
+	if parts == nil {
+		return nil, false
+	}
+	hotfix := parts[5]
+	if len(hotfix) == 0 {
+		if patchParts := reBIGIPHotfix.FindStringSubmatch(strings.TrimSpace(patch)); patchParts != nil {
+			hotfix = patchParts[1]
+		}
+	}
+	return atoiParts(parts[1], parts[2], parts[3], parts[4], hotfix), true
+}
+
+// VersionCompareBIGIP compares versions for F5 BIG-IP products, e.g. 16.1.3.4. Missing components are 0
+// (16.1.3 equals 16.1.3.0) and engineering hotfixes are ordered after their base release.
+// Falls back to generic version handling if either version is not a BIG-IP version.
+func VersionCompareBIGIP(templateVer, targetVer, templatePatch, targetPatch string) int {
+	verTpl, okTpl := parseBIGIPVersion(templateVer, templatePatch)
+	verTgt, okTgt := parseBIGIPVersion(targetVer, targetPatch)
+	if !okTpl || !okTgt {
+		return VersionCompare(templateVer, targetVer)
+	}
+	return compareVersionNumbers(verTgt, verTpl)
+}
+
+// esxiVersion represents the version format of VMware ESXi, e.g. 7.0 U3c 19193900.
+type esxiVersion struct {
+	Major       int
+	Minor       int
+	Update      int    // e.g. for 7.0 U3c is 3, for 7.0.3 is 3
+	UpdateLevel string // e.g. for 7.0 U3c is 'c'
+	Build       int    // e.g. for 7.0 U3 20328353 is 20328353, -1 if not specified
+}
+
+var (
+	// ESXi version: e.g. 7.0, 7.0.3, 7.0 U3c, 7.0u3 build-20328353, or 6.7 update 3.
+	reESXiVersion = regexp.MustCompile(`(?i)^(?:esxi[\s-]*)?(\d+)\.(\d+)(?:\.(\d+))?(?:[\s_-]*(?:u|update)[\s_-]*0*(\d+)([a-z])?)?(?:[\s_-]*(?:build)?[\s_-]*(\d{5,}))?$`)
+	// ESXi update as CPE update: e.g. update_3, update03 or u3c.
+	reESXiUpdate = regexp.MustCompile(`(?i)^(?:u|update)[\s_-]*0*(\d+)([a-z])?$`)
+)
+
+// parseESXiVersion parses the ESXi version `verstr`, the update may be given by the CPE update `patch`.
+func parseESXiVersion(verstr, patch string) (esxiVersion, bool) {
+	parts := reESXiVersion.FindStringSubmatch(strings.TrimSpace(verstr))
+	if parts == nil {
+		return esxiVersion{}, false
+	}
+	ver := esxiVersion{UpdateLevel: strings.ToLower(parts[5]), Build: -1}
+	nums := atoiParts(parts[1], parts[2], parts[3], parts[4])
+	ver.Major, ver.Minor = nums[0], nums[1]
+	switch {
+	case len(parts[4]) > 0:
+		ver.Update = nums[3]
+	case len(parts[3]) > 0:
+		// 7.0.3 is 7.0 Update 3.
+		ver.Update = nums[2]
+	default:
+		if updateParts := reESXiUpdate.FindStringSubmatch(strings.TrimSpace(patch)); updateParts != nil {
+			ver.Update = atoiParts(updateParts[1])[0]
+			ver.UpdateLevel = strings.ToLower(updateParts[2])
+		}
+	}
+	if len(parts[6]) > 0 {
+		ver.Build = atoiParts(parts[6])[0]
+	}
+	return ver, true
+}
+
+// Compare compares ESXi version `v` against `another` and returns 1 if `v` > `another`, 0 if equal, -1 if
+// `v` < `another`. Builds are only compared if specified for both.
+func (v esxiVersion) Compare(another esxiVersion) int {
+	if cmpVal := compareVersionNumbers([]int{v.Major, v.Minor, v.Update}, []int{another.Major, another.Minor, another.Update}); cmpVal != 0 {
+		return cmpVal
+	}
+	if cmpVal := strings.Compare(v.UpdateLevel, another.UpdateLevel); cmpVal != 0 {
+		return cmpVal
+	}
+	if v.Build < 0 || another.Build < 0 {
+		return 0
+	}
+	return compareVersionNumbers([]int{v.Build}, []int{another.Build})
+}
+
+// VersionCompareESXi compares versions for VMware ESXi, e.g. 7.0 U3 20328353 (version, update and build number).
+// Falls back to generic version handling if either version is not an ESXi version.
+func VersionCompareESXi(templateVer, targetVer, templatePatch, targetPatch string) int {
+	verTpl, okTpl := parseESXiVersion(templateVer, templatePatch)
+	verTgt, okTgt := parseESXiVersion(targetVer, targetPatch)
+	if !okTpl || !okTgt {
+		return VersionCompare(templateVer, targetVer)
+	}
+	return verTgt.Compare(verTpl)
 }
@@ -269,6 +269,8 @@ func TestVersionCompareJunosWithName(t *testing.T) {
 		{"15.1X49", "D20", "15.1X49", "D20", 0},
 		{"15.1X48", "D160", "15.1X49", "D20", 2},
 		{"15.1x53", "d34", "15.1X49", "D20", 2}, // Incompatible (vulnerability).
+		{"18.4R2", "S1", "18.4R1", "S3", 2},     // Other build.
+		{"18.4", "", "18.4R1", "S3", 1},         // Train base.
 	}
 
 	for _, tcase := range testcases {
@@ -1511,3 +1513,43 @@ var iosVersions = []string{
 	"12.3(1a)",
 	"12.3(1)",
 }
+
+func TestVersionCompareNetworkOS(t *testing.T) {
+	testcases := []struct {
+		Vendor        string
+		Product       string
+		NVDTemplate   string
+		TemplatePatch string
+		Version       string
+		Expected      int
+	}{
+		// FortiOS.
+		{Vendor: "fortinet", Product: "fortios", NVDTemplate: "7.0.12", Version: "7.0.12 build0523", Expected: 0},
+		{Vendor: "fortinet", Product: "fortios", NVDTemplate: "7.0.13", Version: "v7.0.12,build0523", Expected: -1},
+		{Vendor: "fortinet", Product: "fortios", NVDTemplate: "7.0.9", Version: "7.0.12 build0523", Expected: 1},
+		{Vendor: "fortinet", Product: "fortios", NVDTemplate: "7.0.12", TemplatePatch: "build0520", Version: "7.0.12 build0523", Expected: 1},
+		// PAN-OS.
+		{Vendor: "paloaltonetworks", Product: "pan-os", NVDTemplate: "10.1.6", Version: "10.1.6-h6", Expected: 1},
+		{Vendor: "paloaltonetworks", Product: "pan-os", NVDTemplate: "10.1.6-h10", Version: "10.1.6-h6", Expected: -1},
+		{Vendor: "paloaltonetworks", Product: "pan-os", NVDTemplate: "10.1.6", TemplatePatch: "h6", Version: "10.1.6-h6", Expected: 0},
+		{Vendor: "paloaltonetworks", Product: "pan-os", NVDTemplate: "10.1.7", Version: "10.1.6-h6", Expected: -1},
+		// F5 BIG-IP.
+		{Vendor: "f5", Product: "big-ip_local_traffic_manager", NVDTemplate: "16.1.3", Version: "16.1.3.0", Expected: 0},
+		{Vendor: "f5", Product: "big-ip_local_traffic_manager", NVDTemplate: "16.1.3.4", Version: "16.1.3.4 EHF2", Expected: 1},
+		{Vendor: "f5", Product: "big-ip_access_policy_manager", NVDTemplate: "16.1.3.10", Version: "16.1.3.4-eng-hf2", Expected: -1},
+		{Vendor: "f5", Product: "big-ip_access_policy_manager", NVDTemplate: "16.1.4", Version: "16.1.3.4", Expected: -1},
+		// VMware ESXi.
+		{Vendor: "vmware", Product: "esxi", NVDTemplate: "7.0", TemplatePatch: "update_3", Version: "7.0 U3 20328353", Expected: 0},
+		{Vendor: "vmware", Product: "esxi", NVDTemplate: "7.0", TemplatePatch: "update_2", Version: "7.0 U3 20328353", Expected: 1},
+		{Vendor: "vmware", Product: "esxi", NVDTemplate: "7.0.3", Version: "7.0 U3c", Expected: 1},
+		{Vendor: "vmware", Product: "esxi", NVDTemplate: "7.0 U3 20842708", Version: "7.0 U3 20328353", Expected: -1},
+		{Vendor: "vmware", Product: "esxi", NVDTemplate: "8.0", Version: "7.0 U3 20328353", Expected: -1},
+	}
+
+	schemes := shippedVersionSchemes(t)
+	for _, tcase := range testcases {
+		scheme := lookupVersionScheme(schemes, tcase.Vendor, tcase.Product)
+		cmpVal := VersionCompareScheme(scheme, tcase.Product, tcase.NVDTemplate, tcase.Version, tcase.TemplatePatch, "")
+		require.Equal(t, tcase.Expected, cmpVal, "%s template='%s' (%s), version='%s'", tcase.Product, tcase.NVDTemplate, tcase.TemplatePatch, tcase.Version)
+	}
+}
@@ -0,0 +1,319 @@
+package vulndb
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Version schemes, naming the comparators of the version comparator registry (see RegisterVersionComparator).
+const (
+	VersionSchemeGeneric     = "generic"      // VersionCompare, with PatchCompare for equal versions.
+	VersionSchemeSemVer      = "semver"       // Semantic Versioning 2.0.0, see VersionCompareSemVer.
+	VersionSchemeSemVerLoose = "semver-loose" // Common semver variants, see VersionCompareSemVerLoose.
+	VersionSchemeRPM         = "rpm"          // RPM epoch:version-release, see VersionCompareRPM.
+	VersionSchemeDpkg        = "dpkg"         // Debian epoch:upstream-revision, see VersionCompareDpkg.
+	VersionSchemeCisco       = "cisco"        // Cisco by product, see VersionCompareCisco.
+	VersionSchemeCiscoIOS    = "cisco-ios"    // Cisco IOS trains, see VersionCompareCiscoIOS.
+	VersionSchemeCiscoASA    = "cisco-asa"    // Cisco ASA, see VersionCompareCiscoASA.
+	VersionSchemeJunos       = "junos"        // Juniper Junos, see VersionCompareJuniperJunos.
+	VersionSchemeAdobeYear   = "adobe-year"   // Adobe Acrobat year based versions, see VersionCompareAdobe.
+	VersionSchemeFortiOS     = "fortios"      // Fortinet FortiOS with builds, see VersionCompareFortiOS.
+	VersionSchemePANOS       = "pan-os"       // Palo Alto Networks PAN-OS with hotfixes, see VersionComparePANOS.
+	VersionSchemeBIGIP       = "big-ip"       // F5 BIG-IP with engineering hotfixes, see VersionCompareBIGIP.
+	VersionSchemeESXi        = "esxi"         // VMware ESXi updates and builds, see VersionCompareESXi.
+	VersionSchemeJava        = "java"         // Oracle/OpenJDK Java, see VersionCompareJava.
+)
+
+// VersionComparator compares `targetVer` against `templateVer` of `product` and returns -1, 0, or 1 if the target
+// version is smaller, equal or larger, or 2 if the versions are not compatible/i.e. should not be matched.
+// `templatePatch` and `targetPatch` are the CPE updates of the versions.
+type VersionComparator func(product, templateVer, targetVer, templatePatch, targetPatch string) int
+
+// versionComparators is the registry of version comparators by version scheme.
+var versionComparators = map[string]VersionComparator{
+	VersionSchemeGeneric: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return withPatchCompare(VersionCompare(templateVer, targetVer), templatePatch, targetPatch)
+	},
+	VersionSchemeSemVer: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareSemVer(semVerWithPatch(templateVer, templatePatch), semVerWithPatch(targetVer, targetPatch))
+	},
+	VersionSchemeSemVerLoose: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareSemVerLoose(semVerWithPatch(templateVer, templatePatch), semVerWithPatch(targetVer, targetPatch))
+	},
+	VersionSchemeRPM: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return withPatchCompare(VersionCompareRPM(templateVer, targetVer), templatePatch, targetPatch)
+	},
+	VersionSchemeDpkg: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return withPatchCompare(VersionCompareDpkg(templateVer, targetVer), templatePatch, targetPatch)
+	},
+	VersionSchemeCisco: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareCisco(product, templateVer, targetVer)
+	},
+	VersionSchemeCiscoIOS: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareCiscoIOS(templateVer, targetVer)
+	},
+	VersionSchemeCiscoASA: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareCiscoASA(templateVer, targetVer)
+	},
+	VersionSchemeJunos: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareJuniperJunos(templateVer, targetVer, templatePatch, targetPatch)
+	},
+	VersionSchemeAdobeYear: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareAdobe(product, templateVer, targetVer)
+	},
+	VersionSchemeFortiOS: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareFortiOS(templateVer, targetVer, templatePatch, targetPatch)
+	},
+	VersionSchemePANOS: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionComparePANOS(templateVer, targetVer, templatePatch, targetPatch)
+	},
+	VersionSchemeBIGIP: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareBIGIP(templateVer, targetVer, templatePatch, targetPatch)
+	},
+	VersionSchemeESXi: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareESXi(templateVer, targetVer, templatePatch, targetPatch)
+	},
+	VersionSchemeJava: func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return VersionCompareJava(templateVer, targetVer, templatePatch, targetPatch)
+	},
+}
+
+// RegisterVersionComparator registers the version comparator `cmp` for version scheme `scheme`, replacing any
+// comparator of the scheme. Not safe for concurrent use with matching, register comparators at initialization.
+func RegisterVersionComparator(scheme string, cmp VersionComparator) {
+	versionComparators[scheme] = cmp
+}
+
+// HasVersionComparator returns true if a version comparator is registered for version scheme `scheme`.
+func HasVersionComparator(scheme string) bool {
+	_, has := versionComparators[scheme]
+	return has
+}
+
+// VersionCompareScheme compares versions of `product` with the comparator of version scheme `scheme`, the
+// generic comparator if `scheme` is not registered. See VersionComparator.
+func VersionCompareScheme(scheme, product, templateVer, targetVer, templatePatch, targetPatch string) int {
+	cmp, has := versionComparators[scheme]
+	if !has {
+		cmp = versionComparators[VersionSchemeGeneric]
+	}
+	return cmp(product, templateVer, targetVer, templatePatch, targetPatch)
+}
+
+// withPatchCompare returns the version comparison `cmpVal`, or the comparison of the patches if the versions
+// are equal.
+func withPatchCompare(cmpVal int, templatePatch, targetPatch string) int {
+	if cmpVal == 0 {
+		return PatchCompare(templatePatch, targetPatch)
+	}
+	return cmpVal
+}
+
+// sortVersionSchemes returns the version scheme assignments `schemes` ordered by vendor, exact product names first
+// and then the product globs by specificity (see globSpecificity), most specific first.
+func sortVersionSchemes(schemes []vulndbVersionScheme) []vulndbVersionScheme {
+	sorted := append([]vulndbVersionScheme(nil), schemes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].VendorName != sorted[j].VendorName {
+			return sorted[i].VendorName < sorted[j].VendorName
+		}
+		literalsI, wildcardI := globSpecificity(sorted[i].ProductNameGlob)
+		literalsJ, wildcardJ := globSpecificity(sorted[j].ProductNameGlob)
+		if wildcardI != wildcardJ {
+			return !wildcardI
+		}
+		return literalsI > literalsJ
+	})
+	return sorted
+}
+
+// globSpecificity returns the number of literal characters of the GLOB `pattern`, not counting * and ? and
+// the character classes [...], and true if the pattern has any of those.
+func globSpecificity(pattern string) (literals int, wildcard bool) {
+	inClass := false
+	for i, c := range pattern {
+		switch {
+		case inClass:
+			// ] right after [ or [^ is a member of the class.
+			if c == ']' && pattern[i-1] != '[' && !(pattern[i-1] == '^' && i > 1 && pattern[i-2] == '[') {
+				inClass = false
+			}
+		case c == '[':
+			inClass, wildcard = true, true
+		case c == '*' || c == '?':
+			wildcard = true
+		default:
+			literals++
+		}
+	}
+	return literals, wildcard
+}
+
+// lookupVersionScheme returns the scheme of the first assignment of `schemes` matching `product` by `vendor`,
+// VersionSchemeGeneric if none.
+func lookupVersionScheme(schemes []vulndbVersionScheme, vendor, product string) string {
+	for _, scheme := range schemes {
+		if scheme.VendorName == vendor && globMatch(scheme.ProductNameGlob, product) {
+			return scheme.Scheme
+		}
+	}
+	return VersionSchemeGeneric
+}
+
+// versionScheme returns the version scheme of `product` by `vendor` by the version scheme assignments of the
+// vulndb, VersionSchemeGeneric for vulndbs built without them.
+func (sw *VulnDBSession) versionScheme(vendor, product string) (string, error) {
+	if sw.versionSchemes == nil {
+		schemes := []vulndbVersionScheme{}
+		hasSchemes, err := sw.IsTableExist(vulndbVersionScheme{})
+		if err != nil {
+			return "", err
+		}
+		if hasSchemes {
+			err = sw.Find(&schemes)
+			if err != nil {
+				return "", err
+			}
+		}
+		sw.versionSchemes = sortVersionSchemes(schemes)
+	}
+	return lookupVersionScheme(sw.versionSchemes, vendor, product), nil
+}
+
+// VersionSchemesVersion is the supported format version of the version scheme assignments file.
+const VersionSchemesVersion = 1
+
+// xmlVersionSchemes represents version scheme assignments of vendor/product globs from XML file, e.g.
+// <version-schemes version="1"><assign vendor="apache" product="log4j*" scheme="semver-loose"/></version-schemes>.
+type xmlVersionSchemes struct {
+	Version int                    `xml:"version,attr"`
+	Items   []xmlVersionSchemeItem `xml:"assign"`
+}
+
+type xmlVersionSchemeItem struct {
+	VendorName  string `xml:"vendor,attr"`
+	ProductGlob string `xml:"product,attr"`
+	Scheme      string `xml:"scheme,attr"`
+}
+
+// loadVersionSchemes loads the version scheme assignments from XML file and returns as xmlVersionSchemes.
+func loadVersionSchemes(inputPath string) (*xmlVersionSchemes, error) {
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var schemes xmlVersionSchemes
+
+	decoder := xml.NewDecoder(f)
+	err = decoder.Decode(&schemes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemes, err
+}
+
+// processVersionSchemes inserts the version scheme assignments of the XML file `versionSchemesPath` into vulndb,
+// e.g. data/version_schemes.xml shipped with nanscraper. Assignments of unregistered schemes are skipped.
+func processVersionSchemes(sessionw *VulnDBSession, versionSchemesPath string) error {
+	xmlSchemes, err := loadVersionSchemes(versionSchemesPath)
+	if err != nil {
+		return err
+	}
+	if xmlSchemes.Version != VersionSchemesVersion {
+		return fmt.Errorf("unsupported version scheme assignments version %d, expected %d", xmlSchemes.Version, VersionSchemesVersion)
+	}
+
+	for _, item := range xmlSchemes.Items {
+		if !HasVersionComparator(item.Scheme) {
+			log.Debugf("Unknown version scheme '%s' for '%s/%s' - skipping", item.Scheme, item.VendorName, item.ProductGlob)
+			continue
+		}
+		scheme := vulndbVersionScheme{
+			VendorName:      item.VendorName,
+			ProductNameGlob: item.ProductGlob,
+			Scheme:          item.Scheme,
+		}
+		err = sessionw.Insert(&scheme)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// globMatch returns true if `value` matches the SQLite GLOB `pattern`: * matches any sequence, ? any character
+// and [...] a character class (negated by ^), case sensitive. Used for the version schemes held by the session.
+func globMatch(pattern, value string) bool {
+	p := []rune(pattern)
+	v := []rune(value)
+	for len(p) > 0 {
+		switch p[0] {
+		case '*':
+			for len(p) > 0 && p[0] == '*' {
+				p = p[1:]
+			}
+			if len(p) == 0 {
+				return true
+			}
+			for i := 0; i <= len(v); i++ {
+				if globMatch(string(p), string(v[i:])) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(v) == 0 {
+				return false
+			}
+		case '[':
+			if len(v) == 0 {
+				return false
+			}
+			end := 1
+			if end < len(p) && p[end] == '^' {
+				end++
+			}
+			if end < len(p) && p[end] == ']' {
+				end++
+			}
+			for end < len(p) && p[end] != ']' {
+				end++
+			}
+			if end >= len(p) {
+				return false // Unterminated class.
+			}
+			class := p[1:end]
+			negate := len(class) > 0 && class[0] == '^'
+			if negate {
+				class = class[1:]
+			}
+			matched := false
+			for i := 0; i < len(class); i++ {
+				if i+2 < len(class) && class[i+1] == '-' {
+					if class[i] <= v[0] && v[0] <= class[i+2] {
+						matched = true
+					}
+					i += 2
+				} else if class[i] == v[0] {
+					matched = true
+				}
+			}
+			if matched == negate {
+				return false
+			}
+			p = p[end:]
+		default:
+			if len(v) == 0 || p[0] != v[0] {
+				return false
+			}
+		}
+		p = p[1:]
+		v = v[1:]
+	}
+	return len(v) == 0
+}
@@ -0,0 +1,100 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionCompareScheme(t *testing.T) {
+	require.Equal(t, 1, VersionCompareScheme(VersionSchemeGeneric, "openssl", "1.0", "1.0-rc1", "", ""))
+	require.Equal(t, -1, VersionCompareScheme(VersionSchemeRPM, "openssl", "1.0", "1.0~rc1", "", ""))
+	require.Equal(t, -1, VersionCompareScheme(VersionSchemeDpkg, "openssl", "1.0", "1.0~beta", "", ""))
+	// Unknown schemes compare generically, equal versions by patch.
+	require.Equal(t, 1, VersionCompareScheme("unknown", "openssl", "1.0", "1.0", "sp1", "sp2"))
+
+	RegisterVersionComparator("test-reverse", func(product, templateVer, targetVer, templatePatch, targetPatch string) int {
+		return -VersionCompare(templateVer, targetVer)
+	})
+	defer delete(versionComparators, "test-reverse")
+	require.True(t, HasVersionComparator("test-reverse"))
+	require.Equal(t, -1, VersionCompareScheme("test-reverse", "widget", "1.0", "2.0", "", ""))
+}
+
+// shippedVersionSchemes returns the version scheme assignments of data/version_schemes.xml in lookup order.
+func shippedVersionSchemes(t *testing.T) []vulndbVersionScheme {
+	xmlSchemes, err := loadVersionSchemes("../data/version_schemes.xml")
+	require.NoError(t, err)
+	require.Equal(t, VersionSchemesVersion, xmlSchemes.Version)
+	var schemes []vulndbVersionScheme
+	for _, item := range xmlSchemes.Items {
+		require.True(t, HasVersionComparator(item.Scheme), item.Scheme)
+		schemes = append(schemes, vulndbVersionScheme{VendorName: item.VendorName, ProductNameGlob: item.ProductGlob, Scheme: item.Scheme})
+	}
+	return sortVersionSchemes(schemes)
+}
+
+func TestShippedVersionSchemes(t *testing.T) {
+	schemes := shippedVersionSchemes(t)
+	require.Equal(t, VersionSchemeCiscoIOS, lookupVersionScheme(schemes, "cisco", "ios"))
+	require.Equal(t, VersionSchemeCiscoASA, lookupVersionScheme(schemes, "cisco", "adaptive_security_appliance_software"))
+	require.Equal(t, VersionSchemeCisco, lookupVersionScheme(schemes, "cisco", "ios_xe"))
+	require.Equal(t, VersionSchemeJunos, lookupVersionScheme(schemes, "juniper", "junos"))
+	require.Equal(t, VersionSchemeAdobeYear, lookupVersionScheme(schemes, "adobe", "acrobat_dc"))
+	require.Equal(t, VersionSchemeSemVer, lookupVersionScheme(schemes, "nodejs", "node.js"))
+	require.Equal(t, VersionSchemeGeneric, lookupVersionScheme(schemes, "openssl", "openssl"))
+}
+
+func TestLoadVersionSchemes(t *testing.T) {
+	schemes, err := loadVersionSchemes("testdata/versionschemes/version-schemes.xml")
+	require.NoError(t, err)
+	require.Len(t, schemes.Items, 4)
+	require.Equal(t, xmlVersionSchemeItem{VendorName: "redhat", ProductGlob: "*", Scheme: VersionSchemeRPM}, schemes.Items[0])
+
+	sorted := sortVersionSchemes([]vulndbVersionScheme{
+		{VendorName: "redhat", ProductNameGlob: "*", Scheme: VersionSchemeRPM},
+		{VendorName: "redhat", ProductNameGlob: "openssl*", Scheme: VersionSchemeGeneric},
+	})
+	require.Equal(t, VersionSchemeGeneric, lookupVersionScheme(sorted, "redhat", "openssl-libs"))
+	require.Equal(t, VersionSchemeRPM, lookupVersionScheme(sorted, "redhat", "bind"))
+	require.Equal(t, VersionSchemeGeneric, lookupVersionScheme(sorted, "debian", "bind"))
+
+	// Exact names before globs, globs by literal characters rather than length.
+	sorted = sortVersionSchemes([]vulndbVersionScheme{
+		{VendorName: "acme", ProductNameGlob: "*[a-z]*[0-9]*", Scheme: VersionSchemeGeneric},
+		{VendorName: "acme", ProductNameGlob: "ag*", Scheme: VersionSchemeSemVerLoose},
+		{VendorName: "acme", ProductNameGlob: "agent*", Scheme: VersionSchemeRPM},
+		{VendorName: "acme", ProductNameGlob: "agent", Scheme: VersionSchemeSemVer},
+	})
+	require.Equal(t, []string{"agent", "agent*", "ag*", "*[a-z]*[0-9]*"}, []string{sorted[0].ProductNameGlob,
+		sorted[1].ProductNameGlob, sorted[2].ProductNameGlob, sorted[3].ProductNameGlob})
+	require.Equal(t, VersionSchemeSemVer, lookupVersionScheme(sorted, "acme", "agent"))
+	require.Equal(t, VersionSchemeRPM, lookupVersionScheme(sorted, "acme", "agent2"))
+	require.Equal(t, VersionSchemeSemVerLoose, lookupVersionScheme(sorted, "acme", "ag2"))
+	require.Equal(t, VersionSchemeGeneric, lookupVersionScheme(sorted, "acme", "tool2"))
+}
+
+func TestGlobMatch(t *testing.T) {
+	testcases := []struct {
+		Pattern  string
+		Value    string
+		Expected bool
+	}{
+		{"Java*", "Java 8 Update 201", true},
+		{"Java*", "java 8", false},
+		{"*Reader*", "Adobe Acrobat Reader DC", true},
+		{"Office 1?", "Office 16", true},
+		{"Office 1?", "Office 2016", false},
+		{"Firefox [0-9]*", "Firefox 68.0 (x64 en-US)", true},
+		{"Firefox [^0-9]*", "Firefox 68.0", false},
+		{"Firefox [^0-9]*", "Firefox ESR", true},
+		{"7-Zip/*", "7-Zip/19.00", true},
+		{"exact", "exact", true},
+		{"exact", "exactly", false},
+		{"[abc", "a", false},
+		{"*", "", true},
+	}
+	for _, tcase := range testcases {
+		require.Equal(t, tcase.Expected, globMatch(tcase.Pattern, tcase.Value), "%s GLOB %s", tcase.Value, tcase.Pattern)
+	}
+}
@@ -0,0 +1,397 @@
+package vulndb
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// VEX statement statuses (OpenVEX naming, CSAF product statuses are mapped onto these).
+const (
+	VEXStatusNotAffected        = "not_affected"
+	VEXStatusAffected           = "affected"
+	VEXStatusFixed              = "fixed"
+	VEXStatusUnderInvestigation = "under_investigation"
+)
+
+// VEXDocument represents the statements of an OpenVEX or CSAF VEX document.
+type VEXDocument struct {
+	ID         string
+	Author     string
+	Statements []VEXStatement
+}
+
+// VEXStatement represents a VEX statement on the exploitability of a CVE in a set of products.
+type VEXStatement struct {
+	CVEID           string
+	Status          string
+	Justification   string // e.g. vulnerable_code_not_present, required for not_affected.
+	ImpactStatement string
+	ActionStatement string
+	Products        []VEXProduct
+}
+
+// VEXProduct identifies a product a VEX statement applies to. At least one of CPE, PURL or Product is set.
+// An empty Version means the statement applies to all versions.
+type VEXProduct struct {
+	CPE     string
+	PURL    string
+	Vendor  string
+	Product string
+	Version string
+}
+
+// Suppresses returns true if the statement states that the CVE does not apply (not_affected or fixed).
+func (s VEXStatement) Suppresses() bool {
+	return s.Status == VEXStatusNotAffected || s.Status == VEXStatusFixed
+}
+
+// openVEXDocument represents an OpenVEX document. Supports both the v0.0.x format, where vulnerabilities and
+// products are plain strings, and the v0.2.x format, where those are objects.
+type openVEXDocument struct {
+	Context    string `json:"@context"`
+	ID         string `json:"@id"`
+	Author     string `json:"author"`
+	Statements []struct {
+		Vulnerability   json.RawMessage   `json:"vulnerability"`
+		Products        []json.RawMessage `json:"products"`
+		Status          string            `json:"status"`
+		Justification   string            `json:"justification"`
+		ImpactStatement string            `json:"impact_statement"`
+		ActionStatement string            `json:"action_statement"`
+	} `json:"statements"`
+}
+
+// openVEXProduct represents a product object in an OpenVEX v0.2.x statement.
+type openVEXProduct struct {
+	ID          string `json:"@id"`
+	Identifiers struct {
+		PURL  string `json:"purl"`
+		CPE22 string `json:"cpe22"`
+		CPE23 string `json:"cpe23"`
+	} `json:"identifiers"`
+}
+
+// newVEXProductFromID returns a VEXProduct from an identifier string which is either a purl, a CPE or a name.
+func newVEXProductFromID(id string) VEXProduct {
+	switch {
+	case strings.HasPrefix(id, "pkg:"):
+		return VEXProduct{PURL: id}
+	case strings.HasPrefix(id, "cpe:"):
+		return VEXProduct{CPE: id}
+	}
+	return VEXProduct{Product: id}
+}
+
+// ParseOpenVEX parses an OpenVEX document.
+func ParseOpenVEX(data []byte) (*VEXDocument, error) {
+	var doc openVEXDocument
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	vex := &VEXDocument{
+		ID:     doc.ID,
+		Author: doc.Author,
+	}
+	for _, st := range doc.Statements {
+		statement := VEXStatement{
+			Status:          st.Status,
+			Justification:   st.Justification,
+			ImpactStatement: st.ImpactStatement,
+			ActionStatement: st.ActionStatement,
+		}
+
+		var vulnName string
+		if err := json.Unmarshal(st.Vulnerability, &vulnName); err != nil {
+			var vuln struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(st.Vulnerability, &vuln); err != nil {
+				return nil, err
+			}
+			vulnName = vuln.Name
+		}
+		statement.CVEID = strings.ToUpper(vulnName)
+
+		for _, raw := range st.Products {
+			var id string
+			if err := json.Unmarshal(raw, &id); err == nil {
+				statement.Products = append(statement.Products, newVEXProductFromID(id))
+				continue
+			}
+			var prod openVEXProduct
+			if err := json.Unmarshal(raw, &prod); err != nil {
+				return nil, err
+			}
+			vprod := newVEXProductFromID(prod.ID)
+			if len(prod.Identifiers.PURL) > 0 {
+				vprod.PURL = prod.Identifiers.PURL
+			}
+			if len(prod.Identifiers.CPE23) > 0 {
+				vprod.CPE = prod.Identifiers.CPE23
+			} else if len(prod.Identifiers.CPE22) > 0 {
+				vprod.CPE = prod.Identifiers.CPE22
+			}
+			statement.Products = append(statement.Products, vprod)
+		}
+
+		vex.Statements = append(vex.Statements, statement)
+	}
+
+	return vex, nil
+}
+
+// csafVEXStatuses maps CSAF product statuses to VEX statuses.
+var csafVEXStatuses = map[string]string{
+	CSAFStatusKnownNotAffected:   VEXStatusNotAffected,
+	CSAFStatusKnownAffected:      VEXStatusAffected,
+	CSAFStatusFirstAffected:      VEXStatusAffected,
+	CSAFStatusLastAffected:       VEXStatusAffected,
+	CSAFStatusFixed:              VEXStatusFixed,
+	CSAFStatusFirstFixed:         VEXStatusFixed,
+	CSAFStatusUnderInvestigation: VEXStatusUnderInvestigation,
+}
+
+// ParseCSAFVEX parses a CSAF VEX document (category csaf_vex). The justification of not affected products
+// is taken from the flags and the impact statement from the impact threats.
+func ParseCSAFVEX(data []byte) (*VEXDocument, error) {
+	var doc csafDocument
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	vex := &VEXDocument{
+		ID:     doc.Document.Tracking.ID,
+		Author: doc.Document.Publisher.Name,
+	}
+	products := resolveCSAFProducts(&doc)
+	for _, vuln := range doc.Vulnerabilities {
+		if len(vuln.CVE) == 0 {
+			continue
+		}
+
+		justifications := map[string]string{}
+		for _, flag := range vuln.Flags {
+			for _, pid := range flag.ProductIDs {
+				justifications[pid] = flag.Label
+			}
+		}
+		impacts := map[string]string{}
+		for _, threat := range vuln.Threats {
+			if threat.Category != "impact" {
+				continue
+			}
+			for _, pid := range threat.ProductIDs {
+				impacts[pid] = threat.Details
+			}
+		}
+		actions := map[string]string{}
+		for _, rem := range vuln.Remediations {
+			for _, pid := range rem.ProductIDs {
+				actions[pid] = rem.Details
+			}
+		}
+
+		var csafStatuses []string
+		for csafStatus := range vuln.ProductStatus {
+			csafStatuses = append(csafStatuses, csafStatus)
+		}
+		sort.Strings(csafStatuses)
+		for _, csafStatus := range csafStatuses {
+			status, has := csafVEXStatuses[csafStatus]
+			if !has {
+				continue
+			}
+			for _, pid := range vuln.ProductStatus[csafStatus] {
+				prod, has := products[pid]
+				if !has {
+					continue
+				}
+				vprod := VEXProduct{
+					CPE:     prod.CPE,
+					PURL:    prod.PURL,
+					Vendor:  prod.Vendor,
+					Product: prod.Product,
+					Version: prod.Version,
+				}
+				vex.Statements = append(vex.Statements, VEXStatement{
+					CVEID:           vuln.CVE,
+					Status:          status,
+					Justification:   justifications[pid],
+					ImpactStatement: impacts[pid],
+					ActionStatement: actions[pid],
+					Products:        []VEXProduct{vprod},
+				})
+			}
+		}
+	}
+
+	return vex, nil
+}
+
+// LoadVEX loads an OpenVEX or CSAF VEX document from `inputPath`, detecting the format from the content.
+func LoadVEX(inputPath string) (*VEXDocument, error) {
+	data, err := ioutil.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		Context  string          `json:"@context"`
+		Document json.RawMessage `json:"document"`
+	}
+	err = json.Unmarshal(data, &probe)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.Contains(probe.Context, "openvex"):
+		return ParseOpenVEX(data)
+	case len(probe.Document) > 0:
+		return ParseCSAFVEX(data)
+	}
+	return nil, errors.New("unsupported VEX format")
+}
+
+// vexProductVendorName returns the vendor and product names of `p` for looking up products in vulndb.
+func vexProductVendorName(p VEXProduct) (vendor, product, version string) {
+	if len(p.CPE) > 0 {
+		cpeParts, err := ParseCPE(p.CPE)
+		if err == nil {
+			version = cpeParts.Version
+			if version == "*" || version == "-" {
+				version = ""
+			}
+			return cpeParts.Vendor, cpeParts.Product, version
+		}
+	}
+	if len(p.PURL) > 0 {
+		purl, err := ParsePURL(p.PURL)
+		if err == nil {
+			vendor = purl.Name
+			if len(purl.Namespace) > 0 {
+				vendor = purl.Namespace[strings.LastIndex(purl.Namespace, "/")+1:]
+			}
+			return vendor, purl.Name, purl.Version
+		}
+	}
+	return p.Vendor, p.Product, p.Version
+}
+
+// vexStatementKey identifies the statements on a CVE for a vulndb product.
+type vexStatementKey struct {
+	CVEID     string
+	ProductID int64
+}
+
+// addVEXStatement adds statement `st` for the vulndb product `productID` to `statements`, unless a not_affected
+// or fixed statement is already there for the CVE and product.
+func addVEXStatement(statements map[vexStatementKey]*VEXStatement, st *VEXStatement, productID int64) {
+	key := vexStatementKey{CVEID: strings.ToUpper(st.CVEID), ProductID: productID}
+	if prev, has := statements[key]; !has || (!prev.Suppresses() && st.Suppresses()) {
+		statements[key] = st
+	}
+}
+
+// vexStatementFor returns the statement of `statements` on `cveID` for any of the vulndb products `productIDs`,
+// preferring not_affected and fixed statements. Returns nil if none.
+func vexStatementFor(statements map[vexStatementKey]*VEXStatement, cveID string, productIDs []int64) *VEXStatement {
+	var ret *VEXStatement
+	for _, productID := range productIDs {
+		st, has := statements[vexStatementKey{CVEID: strings.ToUpper(cveID), ProductID: productID}]
+		if has && (ret == nil || (!ret.Suppresses() && st.Suppresses())) {
+			ret = st
+		}
+	}
+	return ret
+}
+
+// ApplyVEX checks the CVE `matches` of the software item (`publisher`, `title`, `version`) against the
+// statements in `vex`. Matches with an applicable statement are annotated with it (CVEMatch.VEX).
+// If `suppress` is true, matches with a not_affected or fixed statement are removed.
+// The products of the statements are resolved via vulndb the same way as MatchCVEs resolves products,
+// so they apply to the matches of the same vulndb product (see MatchEvidence) if the statement version (if any)
+// matches. If several statements apply, not_affected and fixed statements take precedence.
+func ApplyVEX(session *VulnDBSession, vex *VEXDocument, matches []CVEMatch, publisher, title, version string, suppress bool) ([]CVEMatch, error) {
+	if vex == nil || len(matches) == 0 {
+		return matches, nil
+	}
+
+	itemProducts, err := ListProductByTitles(session, publisher, title)
+	if err != nil {
+		return nil, err
+	}
+	if itemProducts == nil {
+		return matches, nil
+	}
+	itemProductIDs := map[int64]bool{}
+	var sortedItemProductIDs []int64
+	for _, p := range itemProducts.Products {
+		if !itemProductIDs[p.ProductId] {
+			sortedItemProductIDs = append(sortedItemProductIDs, p.ProductId)
+		}
+		itemProductIDs[p.ProductId] = true
+	}
+	sort.Slice(sortedItemProductIDs, func(i, j int) bool { return sortedItemProductIDs[i] < sortedItemProductIDs[j] })
+
+	// Applicable statements by CVE and product.
+	statements := map[vexStatementKey]*VEXStatement{}
+	for i, st := range vex.Statements {
+		for _, p := range st.Products {
+			vendorName, productName, stVersion := vexProductVendorName(p)
+			if len(stVersion) > 0 && VersionCompare(stVersion, version) != 0 {
+				continue
+			}
+			stProducts, err := ListProductByTitles(session, vendorName, productName)
+			if err != nil {
+				return nil, err
+			}
+			if stProducts == nil {
+				continue
+			}
+			for _, sp := range stProducts.Products {
+				if !itemProductIDs[sp.ProductId] {
+					continue
+				}
+				addVEXStatement(statements, &vex.Statements[i], sp.ProductId)
+			}
+		}
+	}
+
+	result := make([]CVEMatch, 0, len(matches))
+	for _, match := range matches {
+		// The products of the match, or all the products of the item if without evidence.
+		var productIDs []int64
+		for _, e := range match.Evidence {
+			productIDs = append(productIDs, e.ProductItem.ProductID)
+		}
+		if len(productIDs) == 0 {
+			productIDs = sortedItemProductIDs
+		}
+
+		if st := vexStatementFor(statements, match.Advisory.CVEID, productIDs); st != nil {
+			if suppress && st.Suppresses() {
+				continue
+			}
+			match.VEX = st
+		}
+		result = append(result, match)
+	}
+
+	return result, nil
+}
+
+// MatchCVEsVEX matches CVEs as MatchCVEs and applies the statements in `vex` to the results, see ApplyVEX.
+func MatchCVEsVEX(session *VulnDBSession, vex *VEXDocument, suppress bool, systype, publisher, title, version, patch, target_sw string) ([]CVEMatch, error) {
+	matches, err := MatchCVEs(session, systype, publisher, title, version, patch, target_sw)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyVEX(session, vex, matches, publisher, title, version, suppress)
+}
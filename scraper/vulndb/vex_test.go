@@ -0,0 +1,98 @@
+package vulndb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadVEX(t *testing.T) {
+	vex, err := LoadVEX("testdata/vex/openvex-v0.2.json")
+	require.NoError(t, err)
+	require.Equal(t, "Example Security Team", vex.Author)
+	require.Equal(t, []VEXStatement{
+		{
+			CVEID:           "CVE-2023-1234",
+			Status:          VEXStatusNotAffected,
+			Justification:   "vulnerable_code_not_in_execute_path",
+			ImpactStatement: "The vulnerable function is not called.",
+			Products: []VEXProduct{{
+				PURL: "pkg:apk/wolfi/git@2.39.0-r1?arch=x86_64",
+				CPE:  "cpe:2.3:a:git-scm:git:2.39.0:*:*:*:*:*:*:*",
+			}},
+		},
+		{
+			CVEID:           "CVE-2023-5678",
+			Status:          VEXStatusAffected,
+			ActionStatement: "Upgrade to 2.39.1.",
+			Products:        []VEXProduct{{PURL: "pkg:apk/wolfi/git@2.39.0-r1?arch=x86_64"}},
+		},
+	}, vex.Statements)
+	require.True(t, vex.Statements[0].Suppresses())
+	require.False(t, vex.Statements[1].Suppresses())
+
+	vex, err = LoadVEX("testdata/vex/openvex-v0.0.1.json")
+	require.NoError(t, err)
+	require.Equal(t, []VEXStatement{{
+		CVEID:    "CVE-2022-3602",
+		Status:   VEXStatusFixed,
+		Products: []VEXProduct{{CPE: "cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*"}},
+	}}, vex.Statements)
+
+	vex, err = LoadVEX("testdata/vex/csaf-vex.json")
+	require.NoError(t, err)
+	require.Equal(t, "EXAMPLE-VEX-2022-0001", vex.ID)
+	require.Len(t, vex.Statements, 1)
+	st := vex.Statements[0]
+	require.Equal(t, "CVE-2021-44228", st.CVEID)
+	require.Equal(t, VEXStatusNotAffected, st.Status)
+	require.Equal(t, "component_not_present", st.Justification)
+	require.Equal(t, "Log4j is not shipped with the kernel.", st.ImpactStatement)
+	require.Len(t, st.Products, 1)
+	require.Equal(t, "cpe:2.3:o:example:kernel:4.0:*:*:*:*:*:*:*", st.Products[0].CPE)
+	require.Equal(t, "4.0", st.Products[0].Version)
+}
+
+func TestVEXProductVendorName(t *testing.T) {
+	testcases := []struct {
+		Product VEXProduct
+		Vendor  string
+		Name    string
+		Version string
+	}{
+		{VEXProduct{CPE: "cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*"}, "openssl", "openssl", "3.0.7"},
+		{VEXProduct{CPE: "cpe:/a:openssl:openssl"}, "openssl", "openssl", ""},
+		{VEXProduct{PURL: "pkg:apk/wolfi/git@2.39.0-r1?arch=x86_64"}, "wolfi", "git", "2.39.0-r1"},
+		{VEXProduct{PURL: "pkg:npm/lodash@4.17.20"}, "lodash", "lodash", "4.17.20"},
+		{VEXProduct{Vendor: "Example", Product: "Kernel", Version: "4.0"}, "Example", "Kernel", "4.0"},
+	}
+
+	for _, tcase := range testcases {
+		vendor, name, version := vexProductVendorName(tcase.Product)
+		require.Equal(t, tcase.Vendor, vendor, "Tcase: %+v", tcase)
+		require.Equal(t, tcase.Name, name, "Tcase: %+v", tcase)
+		require.Equal(t, tcase.Version, version, "Tcase: %+v", tcase)
+	}
+}
+
+func TestVEXStatementFor(t *testing.T) {
+	affected := VEXStatement{CVEID: "CVE-2023-1234", Status: VEXStatusAffected}
+	notAffected := VEXStatement{CVEID: "cve-2023-1234", Status: VEXStatusNotAffected}
+	fixed := VEXStatement{CVEID: "CVE-2023-5678", Status: VEXStatusFixed}
+	investigating := VEXStatement{CVEID: "CVE-2023-5678", Status: VEXStatusUnderInvestigation}
+
+	statements := map[vexStatementKey]*VEXStatement{}
+	addVEXStatement(statements, &affected, 1)
+	addVEXStatement(statements, &notAffected, 1)
+	addVEXStatement(statements, &affected, 2)
+	addVEXStatement(statements, &fixed, 2)
+	addVEXStatement(statements, &investigating, 2)
+
+	require.Equal(t, &notAffected, vexStatementFor(statements, "CVE-2023-1234", []int64{1}))
+	require.Equal(t, &affected, vexStatementFor(statements, "CVE-2023-1234", []int64{2}))
+	require.Equal(t, &notAffected, vexStatementFor(statements, "CVE-2023-1234", []int64{2, 1}))
+	require.Equal(t, &fixed, vexStatementFor(statements, "cve-2023-5678", []int64{2}))
+	// Statements of other products do not apply.
+	require.Nil(t, vexStatementFor(statements, "CVE-2023-5678", []int64{1}))
+	require.Nil(t, vexStatementFor(statements, "CVE-2023-1234", []int64{3}))
+}